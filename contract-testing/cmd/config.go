@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the project configuration",
+	Long:  `Commands for working with the contract-testing.yaml project configuration.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the project configuration for errors",
+	Long:  `Loads contract-testing.yaml and checks that every provider's schema, mocks and URLs are usable.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectConfig == nil {
+			return fmt.Errorf("no configuration file found")
+		}
+		
+		problems := projectConfig.Validate()
+		if len(problems) > 0 {
+			fmt.Printf("❌ %s has %d problem(s):\n", projectConfig.Path(), len(problems))
+			for _, problem := range problems {
+				fmt.Printf("  - %v\n", problem)
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("invalid configuration")
+		}
+		
+		fmt.Printf("✅ %s is valid (%d provider(s), environment: %s)\n", projectConfig.Path(), len(projectConfig.Providers), projectConfig.Environment)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
\ No newline at end of file
@@ -2,24 +2,63 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
+	_ "github.com/Arpit529srivastava/internal/provider" // registers the sample provider's events
+	"github.com/Arpit529srivastava/internal/repository"
 	"github.com/Arpit529srivastava/internal/schema"
 	"github.com/spf13/cobra"
 )
 
 var (
-	providerName string
-	baseURL      string
-	outputPath   string
+	providerName    string
+	baseURL         string
+	outputPath      string
+	generateFormat  string
+	asyncAPIVersion string
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate OpenAPI schema from provider service",
-	Long:  `Analyzes the provider service API and generates an OpenAPI schema that represents the contract.`,
+	Long: `Analyzes the provider service API and generates an OpenAPI schema that represents the contract.
+
+With --format asyncapi, an AsyncAPI document is generated instead from the
+events the provider registers in Go (contract.RegisterEvent), with payload and
+header schemas reflected from their types. It is written to the provider's
+asyncapi.yaml, next to its OpenAPI schema.
+
+Only events compiled into this binary can be described, which are those of
+the bundled sample order-service. For your own services, call
+contract.GenerateAsyncAPI(provider, version, outputPath) from a test or main
+package in the provider's module, where its events are registered.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		switch generateFormat {
+		case "openapi":
+		case "asyncapi":
+			return generateAsyncAPI(cmd)
+		default:
+			return fmt.Errorf("unknown format %q (use openapi or asyncapi)", generateFormat)
+		}
+		
+		if projectConfig != nil && providerName != "" {
+			if provider, ok := projectConfig.Providers[providerName]; ok {
+				baseURL = stringOption(cmd, "url", projectConfig.URL(providerName, projectConfig.Environment))
+				outputPath = stringOption(cmd, "output", provider.Schema)
+			}
+		}
+		
+		err := requireOptions(map[string]string{
+			"provider": providerName,
+			"url":      baseURL,
+			"output":   outputPath,
+		})
+		if err != nil {
+			return err
+		}
+		
 		generator := schema.NewGenerator(providerName, baseURL)
-		err := generator.GenerateSchema(outputPath)
+		err = generator.GenerateSchema(outputPath)
 		if err != nil {
 			return err
 		}
@@ -30,10 +69,44 @@ var generateCmd = &cobra.Command{
 
 func init() {
 	generateCmd.Flags().StringVarP(&providerName, "provider", "p", "", "Name of the provider service (required)")
-	generateCmd.Flags().StringVarP(&baseURL, "url", "u", "", "Base URL of the provider service (required)")
-	generateCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output path for the generated schema (required)")
+	generateCmd.Flags().StringVarP(&baseURL, "url", "u", "", "Base URL of the provider service (default: from the config file)")
+	generateCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output path for the generated schema (default: the provider's configured schema)")
+	generateCmd.Flags().StringVar(&generateFormat, "format", "openapi", "Document to generate: openapi or asyncapi")
+	generateCmd.Flags().StringVar(&asyncAPIVersion, "asyncapi-version", schema.AsyncAPIVersion2, "AsyncAPI version to write: 2.6.0 or 3.0.0")
+}
+
+// generateAsyncAPI writes the AsyncAPI document of the provider's registered
+// events to --output, the configured asyncapi path or asyncapi.yaml next to
+// the provider's schema, in that order.
+func generateAsyncAPI(cmd *cobra.Command) error {
+	if err := requireOptions(map[string]string{"provider": providerName}); err != nil {
+		return err
+	}
+	
+	output := outputPath
+	if output == "" {
+		output = repository.NewContractRepository("contracts").ProviderAsyncAPIPath(providerName)
+		if projectConfig != nil {
+			if provider, ok := projectConfig.Providers[providerName]; ok {
+				switch {
+				case provider.AsyncAPI != "":
+					output = provider.AsyncAPI
+				case provider.Schema != "":
+					output = filepath.Join(filepath.Dir(provider.Schema), "asyncapi.yaml")
+				}
+			}
+		}
+	}
 	
-	generateCmd.MarkFlagRequired("provider")
-	generateCmd.MarkFlagRequired("url")
-	generateCmd.MarkFlagRequired("output")
+	events := schema.RegisteredEvents(providerName)
+	if len(events) == 0 {
+		return fmt.Errorf("no events are registered for %s in this binary; call contract.GenerateAsyncAPI from the provider's own module instead", providerName)
+	}
+	generator := schema.NewAsyncGenerator(providerName, events).
+		WithVersion(asyncAPIVersion)
+	if err := generator.GenerateSchema(output); err != nil {
+		return err
+	}
+	fmt.Printf("AsyncAPI document generated successfully at: %s\n", output)
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/Arpit529srivastava/contract"
+	"github.com/Arpit529srivastava/internal/repository"
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/stub"
+	"github.com/Arpit529srivastava/internal/verifier"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordTarget       string
+	recordConsumer     string
+	recordProvider     string
+	recordListen       string
+	recordOutput       string
+	recordSchema       string
+	recordContracts    string
+	recordRedact       []string
+	recordHeaders      []string
+	recordPlaceholders bool
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record real traffic to a provider as consumer mocks",
+	Long: `Runs a reverse proxy in front of a provider and writes every interaction that
+passes through it as a mock for the consumer.
+
+Point the consumer at --listen instead of the provider. Interactions that share
+a request shape (method, endpoint, parameter names, request body structure and
+status) with an already recorded mock are skipped, including mocks already in
+the output directory.
+
+--redact replaces field and header values with "[REDACTED]"; a rule is a field
+name (password) or a dotted path (body.card.number, response.headers.Set-Cookie).
+IDs, UUIDs and timestamps are replaced with stable placeholders unless
+--placeholders=false. With a provider schema, paths are recorded as the
+schema's path templates with their parameters.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectConfig != nil && recordProvider != "" {
+			if provider, ok := projectConfig.Providers[recordProvider]; ok {
+				recordTarget = stringOption(cmd, "target", projectConfig.URL(recordProvider, projectConfig.Environment))
+				recordSchema = stringOption(cmd, "schema", provider.Schema)
+			}
+		}
+		
+		err := requireOptions(map[string]string{
+			"target":   recordTarget,
+			"consumer": recordConsumer,
+			"provider": recordProvider,
+		})
+		if err != nil {
+			return err
+		}
+		
+		target, err := url.Parse(recordTarget)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return fmt.Errorf("invalid target URL %q", recordTarget)
+		}
+		
+		repo := repository.NewContractRepository(recordContracts)
+		if recordOutput == "" {
+			recordOutput = repo.ConsumerMocksPath(recordConsumer)
+		}
+		if recordSchema == "" {
+			if _, err := os.Stat(repo.ProviderSchemaPath(recordProvider)); err == nil {
+				recordSchema = repo.ProviderSchemaPath(recordProvider)
+			}
+		}
+		
+		options := contract.RecorderOptions{
+			Consumer:     recordConsumer,
+			Provider:     recordProvider,
+			Redact:       recordRedact,
+			Headers:      recordHeaders,
+			Placeholders: recordPlaceholders,
+		}
+		if recordSchema != "" {
+			if options.Spec, err = schema.LoadSpec(recordSchema); err != nil {
+				return err
+			}
+		}
+		recorder := contract.NewRecorder(options)
+		
+		if _, err := os.Stat(recordOutput); err == nil {
+			_, existing, err := stub.LoadMocks(recordOutput, recordProvider)
+			if err != nil {
+				return err
+			}
+			recorder.Known(existing...)
+		}
+		
+		proxy := contract.NewRecordingProxy(target, recorder, func(mock verifier.Mock, recorded bool) {
+			if !recorded {
+				return
+			}
+			path, err := contract.WriteMock(recordOutput, mock)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				return
+			}
+			fmt.Printf("Recorded %s -> %s\n", mock.Description, path)
+		})
+		
+		fmt.Printf("Recording %s traffic to %s for %s on %s\n", recordProvider, target, recordConsumer, recordListen)
+		return http.ListenAndServe(recordListen, stub.WithLogging(proxy, os.Stdout))
+	},
+}
+
+func init() {
+	recordCmd.Flags().StringVarP(&recordTarget, "target", "t", "", "Base URL of the provider to forward to (default: from the config file)")
+	recordCmd.Flags().StringVar(&recordConsumer, "consumer", "", "Consumer the recorded mocks belong to (required)")
+	recordCmd.Flags().StringVarP(&recordProvider, "provider", "p", "", "Provider being recorded (required)")
+	recordCmd.Flags().StringVarP(&recordListen, "listen", "l", ":9000", "Address the recording proxy listens on")
+	recordCmd.Flags().StringVarP(&recordOutput, "output", "o", "", "Directory mocks are written to (default: the consumer's mocks directory)")
+	recordCmd.Flags().StringVarP(&recordSchema, "schema", "s", "", "Provider schema used to record path templates (default: the provider's schema, if any)")
+	recordCmd.Flags().StringVar(&recordContracts, "contracts", "contracts", "Contracts directory")
+	recordCmd.Flags().StringSliceVar(&recordRedact, "redact", nil, "Fields or headers whose values are redacted (repeatable or comma-separated)")
+	recordCmd.Flags().StringSliceVar(&recordHeaders, "header", nil, "Headers to record besides Content-Type (repeatable or comma-separated)")
+	recordCmd.Flags().BoolVar(&recordPlaceholders, "placeholders", true, "Replace IDs, UUIDs and timestamps with stable placeholders")
+}
\ No newline at end of file
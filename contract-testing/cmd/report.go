@@ -11,6 +11,8 @@ var (
 	resultsPath string
 	reportFormat string
 	reportOutput string
+	reportSort string
+	reportTimestamp string
 )
 
 var reportCmd = &cobra.Command{
@@ -18,9 +20,28 @@ var reportCmd = &cobra.Command{
 	Short: "Generate detailed reports from verification results",
 	Long:  `Creates comprehensive reports based on the verification results, highlighting compatibility issues.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		validationResult := &verifier.ValidationResult{}
-		reporter := verifier.NewReporter(validationResult)
-		err := reporter.GenerateReport(resultsPath, reportFormat, reportOutput)
+		if projectConfig != nil {
+			resultsPath = stringOption(cmd, "results", projectConfig.Report.Results)
+			reportFormat = stringOption(cmd, "format", projectConfig.Report.Format)
+			reportOutput = stringOption(cmd, "output", projectConfig.Report.Output)
+		}
+		
+		if err := requireOptions(map[string]string{"results": resultsPath}); err != nil {
+			return err
+		}
+		
+		sortKeys, err := verifier.ParseSortKeys(reportSort)
+		if err != nil {
+			return err
+		}
+		
+		timestamp, err := parseTimestamp(reportTimestamp)
+		if err != nil {
+			return err
+		}
+		
+		reporter := verifier.NewReporter(nil).WithSort(sortKeys).WithTimestamp(timestamp)
+		err = reporter.GenerateReport(resultsPath, reportFormat, reportOutput)
 		if err != nil {
 			return err
 		}
@@ -34,6 +55,6 @@ func init() {
 	reportCmd.Flags().StringVarP(&resultsPath, "results", "r", "", "Path to verification results (required)")
 	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Report format (html, json, markdown)")
 	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "report.html", "Output path for the report")
-	
-	reportCmd.MarkFlagRequired("results")
+	reportCmd.Flags().StringVar(&reportSort, "sort", "", "Comma-separated sort keys: severity, consumer, endpoint (default: consumer,endpoint)")
+	reportCmd.Flags().StringVar(&reportTimestamp, "timestamp", "", "Fixed report timestamp (RFC 3339 or Unix seconds) for byte-identical reports")
 }
\ No newline at end of file
@@ -1,14 +1,46 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/config"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix prefixes the environment variables that override flags, e.g.
+// CT_SCHEMA for --schema.
+const envPrefix = "CT_"
+
+var (
+	configPath  string
+	environment string
+
+	// projectConfig is the loaded project configuration, or nil when the
+	// project has none.
+	projectConfig *config.Config
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "contract-testing",
 	Short: "A provider-driven contract testing tool",
 	Long: `A tool for provider-driven contract testing that allows 
-services to validate their API contracts against consumer expectations.`,
+services to validate their API contracts against consumer expectations.
+
+Settings are read from contract-testing.yaml in the current directory or its
+parents. CT_* environment variables (e.g. CT_SCHEMA) override the file, and
+flags override both.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyEnvOverrides(cmd.Flags()); err != nil {
+			return err
+		}
+		return loadProjectConfig()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -17,7 +49,107 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to the project configuration file (default: contract-testing.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&environment, "env", "e", "", "Environment whose provider URLs are used (default: the config's environment)")
+	
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(verifyCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(stubCmd)
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(webhooksCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// applyEnvOverrides sets every flag that wasn't given on the command line from
+// its CT_* environment variable, if one is set.
+func applyEnvOverrides(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(flag.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value for %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+func loadProjectConfig() error {
+	path := configPath
+	if path == "" {
+		found, ok := config.Find(".")
+		if !ok {
+			return nil
+		}
+		path = found
+	}
+	
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	
+	if environment != "" {
+		cfg.Environment = environment
+	}
+	
+	projectConfig = cfg
+	return nil
+}
+
+// stringOption returns the flag's value when it was set on the command line
+// or through the environment, and otherwise falls back to the config value
+// and then the flag's default.
+func stringOption(cmd *cobra.Command, name, configValue string) string {
+	flag := cmd.Flags().Lookup(name)
+	if flag.Changed || configValue == "" {
+		return flag.Value.String()
+	}
+	return configValue
+}
+
+// requireOptions reports the options that are still empty after flags,
+// environment and configuration have been applied.
+func requireOptions(options map[string]string) error {
+	var missing []string
+	for name, value := range options {
+		if value == "" {
+			missing = append(missing, fmt.Sprintf("%q", name))
+		}
+	}
+	
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parseTimestamp parses a --timestamp value given as RFC 3339 or as Unix
+// seconds. An empty value falls back to SOURCE_DATE_EPOCH, the convention for
+// reproducible builds, and otherwise returns the zero time.
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		value = os.Getenv("SOURCE_DATE_EPOCH")
+		if value == "" {
+			return time.Time{}, nil
+		}
+	}
+	
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: use RFC 3339 or Unix seconds", value)
+	}
+	return timestamp, nil
 }
\ No newline at end of file
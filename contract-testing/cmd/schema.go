@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/repository"
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	registryProvider  string
+	registryAsyncAPI  string
+	registryContracts string
+	registrySubject   string
+	registryFile      string
+	registryDryRun    bool
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage the event schema registry",
+	Long: `Commands for the schema registry kept in the contract repository, under
+<contracts>/registry. It stores every version of each event subject's payload
+schema and enforces the subject's compatibility mode when a new version is
+registered.`,
+}
+
+var schemaRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Register new versions of event payload schemas",
+	Long: `Registers the payload schemas of the messages a provider publishes, taken from
+its AsyncAPI document, as new versions of their subjects. A channel's subject is
+its name, or "<channel>-<message>" for channels carrying several messages. With
+--file, the JSON or YAML schema in the file is registered as --subject instead.
+
+Each new version is checked against the subject's compatibility mode:
+
+  BACKWARD    consumers using the new schema can read data written with the
+              latest version (the default)
+  FORWARD     consumers using the latest version can read data written with
+              the new schema
+  FULL        both
+  NONE        no check
+
+The _TRANSITIVE variants (e.g. BACKWARD_TRANSITIVE) check every registered
+version instead of only the latest. Incompatible versions are rejected with
+every field that breaks the mode. Schemas identical to a registered version are
+not registered again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectConfig != nil && registryProvider != "" {
+			if provider, ok := projectConfig.Providers[registryProvider]; ok {
+				registryAsyncAPI = stringOption(cmd, "asyncapi", provider.AsyncAPI)
+			}
+		}
+
+		repo := repository.NewContractRepository(registryContracts)
+		registry, err := repository.OpenSchemaRegistry(repo.SchemaRegistryPath())
+		if err != nil {
+			return err
+		}
+
+		subjects, err := registrySchemas(repo)
+		if err != nil {
+			return err
+		}
+
+		rejected := 0
+		for _, subject := range sortedSubjects(subjects) {
+			mode := registry.Compatibility(subject)
+			issues, existing, err := registry.Check(subject, subjects[subject])
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case existing != 0:
+				fmt.Printf("➖ %s: unchanged, version %d\n", subject, existing)
+			case len(issues) > 0:
+				rejected++
+				fmt.Printf("❌ %s: incompatible with %s\n", subject, mode)
+				for _, issue := range issues {
+					fmt.Printf("   • %s\n", issue)
+				}
+			case registryDryRun:
+				fmt.Printf("✅ %s: compatible with %s\n", subject, mode)
+			default:
+				version, _, err := registry.Register(subject, subjects[subject])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ %s: registered version %d (%s)\n", subject, version, mode)
+			}
+		}
+
+		if rejected > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d schemas rejected", rejected, len(subjects))
+		}
+		return nil
+	},
+}
+
+var schemaCompatibilityCmd = &cobra.Command{
+	Use:   "compatibility [MODE]",
+	Short: "Show or set a subject's compatibility mode",
+	Long: `Shows the compatibility mode of --subject, or the registry's default without
+--subject. With a MODE (BACKWARD, FORWARD, FULL, their _TRANSITIVE variants or
+NONE), sets it instead. Modes are saved in <contracts>/registry/registry.yaml.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo := repository.NewContractRepository(registryContracts)
+		registry, err := repository.OpenSchemaRegistry(repo.SchemaRegistryPath())
+		if err != nil {
+			return err
+		}
+
+		name := registrySubject
+		if name == "" {
+			name = "default"
+		}
+		if len(args) == 1 {
+			if err := registry.SetCompatibility(registrySubject, args[0]); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s: %s\n", name, registry.Compatibility(registrySubject))
+		return nil
+	},
+}
+
+// registrySchemas returns the schemas to register by subject: the one in
+// --file, or the message payloads of the provider's AsyncAPI document.
+// --subject keeps only that subject.
+func registrySchemas(repo *repository.ContractRepository) (map[string]interface{}, error) {
+	if registryFile != "" {
+		if registrySubject == "" {
+			return nil, fmt.Errorf("--file needs a --subject")
+		}
+		doc, err := schema.NewParser(registryFile).Parse()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{registrySubject: schema.NewSpec(doc).Inline(doc)}, nil
+	}
+
+	if registryAsyncAPI == "" && registryProvider != "" {
+		registryAsyncAPI = repo.ProviderAsyncAPIPath(registryProvider)
+	}
+	if err := requireOptions(map[string]string{"provider": registryProvider}); err != nil {
+		return nil, err
+	}
+	async, err := schema.LoadAsyncSpec(registryAsyncAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := map[string]interface{}{}
+	for _, channel := range async.Channels() {
+		messages, _ := async.Messages(channel)
+		for _, message := range messages {
+			if message.Payload == nil {
+				continue
+			}
+			subject := strings.ReplaceAll(strings.Trim(channel, "/"), "/", ".")
+			if len(messages) > 1 {
+				subject += "-" + message.Name
+			}
+			if registrySubject == "" || subject == registrySubject {
+				subjects[subject] = async.Spec().Inline(message.Payload)
+			}
+		}
+	}
+	if len(subjects) == 0 {
+		if registrySubject != "" {
+			return nil, fmt.Errorf("%s publishes no subject %s", registryProvider, registrySubject)
+		}
+		return nil, fmt.Errorf("%s publishes no message payloads", registryProvider)
+	}
+	return subjects, nil
+}
+
+func sortedSubjects(subjects map[string]interface{}) []string {
+	names := make([]string, 0, len(subjects))
+	for name := range subjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	schemaRegisterCmd.Flags().StringVarP(&registryProvider, "provider", "p", "", "Provider whose AsyncAPI message payloads are registered")
+	schemaRegisterCmd.Flags().StringVar(&registryAsyncAPI, "asyncapi", "", "Path to the provider's AsyncAPI document (default: the provider's asyncapi.yaml)")
+	schemaRegisterCmd.Flags().StringVarP(&registryFile, "file", "f", "", "JSON or YAML schema file to register as --subject")
+	schemaRegisterCmd.Flags().StringVar(&registrySubject, "subject", "", "Register only this subject")
+	schemaRegisterCmd.Flags().BoolVar(&registryDryRun, "dry-run", false, "Check compatibility without registering")
+	schemaCompatibilityCmd.Flags().StringVar(&registrySubject, "subject", "", "Subject whose mode is shown or set (default: the registry's default)")
+
+	for _, command := range []*cobra.Command{schemaRegisterCmd, schemaCompatibilityCmd} {
+		command.Flags().StringVar(&registryContracts, "contracts", "contracts", "Contracts directory holding the registry")
+		schemaCmd.AddCommand(command)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Arpit529srivastava/internal/repository"
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/stub"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stubProvider  string
+	stubSchema    string
+	stubContracts string
+	stubListen    string
+	stubMocks     string
+	stubFaults    string
+)
+
+var stubCmd = &cobra.Command{
+	Use:   "stub",
+	Short: "Run stub servers that stand in for providers",
+	Long:  `Commands for running stub servers that consumers can test against instead of hand-written fakes.`,
+}
+
+var stubServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a provider's contract as a stub server",
+	Long: `Loads the provider's OpenAPI schema and answers requests on its behalf.
+
+Requests are routed by the schema's path templates and validated against the
+declared parameters and request bodies; invalid requests get a 400 naming the
+contract rules they break. Responses come from the schema's example/examples,
+or are generated from the response schema. Send "Prefer: code=404" to ask for
+a specific declared response.
+
+With --mocks the stub replays consumer mocks instead: a request gets the
+response of the mock whose method, endpoint, parameters, headers and body it
+matches, and a 404 describing the closest mock otherwise. GET
+/__stub/mocks/unused lists the mocks no request has hit so far.
+
+--faults loads per-route fault profiles (latency, jitter, injected 5xx
+responses, connection resets, truncated bodies, slow-drip streaming) from a
+YAML file. GET, PUT, POST and DELETE on /__stub/faults inspect and change the
+profiles while the stub runs. Requests no profile matches are served normally.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var handler http.Handler
+		var source string
+		var err error
+		
+		if stubMocks != "" {
+			handler, source, err = newMockStub()
+		} else {
+			handler, source, err = newSchemaStub()
+		}
+		if err != nil {
+			return err
+		}
+		
+		faultsFile := stubFaults
+		if faultsFile == "" && projectConfig != nil {
+			faultsFile = projectConfig.Providers[stubProvider].Faults
+		}
+		
+		var profiles []stub.FaultProfile
+		if faultsFile != "" {
+			if profiles, err = stub.LoadFaultProfiles(faultsFile); err != nil {
+				return err
+			}
+			fmt.Printf("Injecting %d fault profiles from %s\n", len(profiles), faultsFile)
+		}
+		handler = stub.WithFaults(handler, profiles)
+		
+		fmt.Printf("Serving %s on %s\n", source, stubListen)
+		return http.ListenAndServe(stubListen, stub.WithLogging(handler, os.Stdout))
+	},
+}
+
+// newSchemaStub builds a stub that answers from the provider's OpenAPI schema.
+func newSchemaStub() (http.Handler, string, error) {
+	schemaFile := stubSchema
+	if schemaFile == "" && stubProvider != "" {
+		schemaFile = repository.NewContractRepository(stubContracts).ProviderSchemaPath(stubProvider)
+		if projectConfig != nil && projectConfig.Providers[stubProvider].Schema != "" {
+			schemaFile = projectConfig.Providers[stubProvider].Schema
+		}
+	}
+	
+	if schemaFile == "" {
+		return nil, "", requireOptions(map[string]string{"provider": stubProvider})
+	}
+	
+	spec, err := schema.LoadSpec(schemaFile)
+	if err != nil {
+		return nil, "", err
+	}
+	
+	name := stubProvider
+	if name == "" {
+		name = spec.Title()
+	}
+	
+	return stub.NewSchemaServer(name, spec), fmt.Sprintf("%s stub from %s", name, schemaFile), nil
+}
+
+// newMockStub builds a stub that replays the consumer mocks in --mocks.
+func newMockStub() (http.Handler, string, error) {
+	paths, mocks, err := stub.LoadMocks(stubMocks, stubProvider)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(mocks) == 0 {
+		return nil, "", fmt.Errorf("no mocks found in %s", stubMocks)
+	}
+	
+	return stub.NewMockServer(paths, mocks), fmt.Sprintf("%d mocks from %s", len(mocks), stubMocks), nil
+}
+
+func init() {
+	stubServeCmd.Flags().StringVarP(&stubProvider, "provider", "p", "", "Provider whose contract is served")
+	stubServeCmd.Flags().StringVarP(&stubSchema, "schema", "s", "", "Path to the provider schema (default: the provider's schema in the contracts directory)")
+	stubServeCmd.Flags().StringVar(&stubContracts, "contracts", "contracts", "Contracts directory")
+	stubServeCmd.Flags().StringVarP(&stubMocks, "mocks", "m", "", "Serve the consumer mocks in this directory instead of the schema")
+	stubServeCmd.Flags().StringVar(&stubFaults, "faults", "", "Fault profile file (default: the provider's faults in the config file)")
+	stubServeCmd.Flags().StringVarP(&stubListen, "listen", "l", ":9090", "Address the stub server listens on")
+	
+	stubCmd.AddCommand(stubServeCmd)
+}
\ No newline at end of file
@@ -2,42 +2,357 @@ package cmd
 
 import (
 	"fmt"
+	"runtime"
+	"time"
 
+	"github.com/Arpit529srivastava/internal/repository"
+	"github.com/Arpit529srivastava/internal/schema"
 	"github.com/Arpit529srivastava/internal/verifier"
 	"github.com/spf13/cobra"
 )
 
 var (
-	schemaPath    string
-	mocksDir      string
-	providerURL   string
+	schemaPath     string
+	asyncAPIPath   string
+	protosDir      string
+	grpcURL        string
+	graphQLPath    string
+	graphQLURL     string
+	mocksDir       string
+	providerURL    string
+	verifyProvider string
+	resultsOutput  string
+	baselinePath   string
+	writeBaseline  string
+	verifyAll      bool
+	contractsDir   string
+	concurrency    int
+	liveVerify     bool
+	rateLimit      float64
+	sortBy         string
+	timestampFlag  string
 )
 
+// verifyTarget is one provider to verify, after flags, environment and
+// configuration have been resolved.
+type verifyTarget struct {
+	provider  string
+	schema    string
+	asyncAPI  string
+	protos     string
+	graphQL    string
+	mocks      string
+	url        string
+	grpcURL    string
+	graphQLURL string
+	rateLimit  float64
+}
+
 var verifyCmd = &cobra.Command{
 	Use:   "verify",
 	Short: "Verify provider contracts against consumer mocks",
-	Long:  `Validates the provider's implementation against consumer expectations by using the mocks.`,
+	Long: `Validates the provider's implementation against consumer expectations by using the mocks.
+
+Without --schema, every provider declared in contract-testing.yaml is verified
+(or only the one named by --provider). With --all, every provider found under
+the contracts directory is verified against its consumers, and the combined
+results include a consumer × provider matrix.
+
+Message contracts (files with a channel instead of a request) are checked
+against the provider's AsyncAPI document: --asyncapi, or asyncapi.yaml next to
+the schema.
+
+gRPC contracts (files with a grpc section naming a method) are checked against
+the provider's .proto files: --protos, or the .proto files next to the schema.
+With --live, their methods are also called on --grpc-url.
+
+GraphQL contracts (files with a graphql section holding a query) are checked
+against the provider's SDL: --graphql, or schema.graphql next to the schema.
+With --live, their operations are also posted to --graphql-url, by default
+/graphql on the provider URL.
+
+Stream contracts (files with a stream section) are checked against the
+provider's streaming endpoints. With --live, each stream is opened on the
+provider over Server-Sent Events or WebSocket, and the messages it sends within
+the contract's timeout are matched against the expected ones.
+
+Mocks are verified by --concurrency workers. With --live, each mock's request is
+also sent to the provider and the response compared with the mock; every
+provider gets its own --rate-limit.
+
+Output is sorted deterministically; --sort puts severity, consumer or endpoint
+first, and --timestamp (or SOURCE_DATE_EPOCH) fixes the run timestamp.
+
+Use --write-baseline to snapshot the current issues, and --baseline on later runs
+so that only new issues fail verification. Waivers listed in the baseline file
+suppress matching issues until their expiry date.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		validator := verifier.NewValidator(schemaPath, mocksDir, providerURL)
-		results, err := validator.Validate()
+		targets, err := resolveVerifyTargets(cmd)
 		if err != nil {
 			return err
 		}
 		
-		reporter := verifier.NewReporter(results)
-		summary := reporter.GenerateSummary()
+		sortKeys, err := verifier.ParseSortKeys(sortBy)
+		if err != nil {
+			return err
+		}
 		
-		fmt.Println(summary)
+		timestamp, err := parseTimestamp(timestampFlag)
+		if err != nil {
+			return err
+		}
+		
+		baselineFile := baselinePath
+		failOnExpired := false
+		if projectConfig != nil {
+			baselineFile = stringOption(cmd, "baseline", projectConfig.Policy.Baseline)
+			failOnExpired = projectConfig.Policy.FailOnExpiredWaivers
+		}
+		
+		var baseline *verifier.Baseline
+		if baselineFile != "" && writeBaseline == "" {
+			baseline, err = verifier.LoadBaseline(baselineFile)
+			if err != nil {
+				return err
+			}
+		}
+		
+		var allResults []*verifier.ValidationResult
+		success := true
+		
+		for _, target := range targets {
+			validator := verifier.NewValidator(target.schema, target.mocks, target.url).
+				WithConcurrency(concurrency)
+			if target.provider != "" {
+				validator.ForProvider(target.provider)
+			}
+			if liveVerify {
+				validator.WithLive(target.rateLimit)
+			}
+			if target.asyncAPI != "" {
+				validator.WithAsyncAPI(target.asyncAPI)
+			}
+			if target.protos != "" {
+				validator.WithProtos(target.protos)
+			}
+			if target.grpcURL != "" {
+				validator.WithGRPC(target.grpcURL)
+			}
+			if target.graphQL != "" {
+				validator.WithGraphQL(target.graphQL)
+			}
+			if target.graphQLURL != "" {
+				validator.WithGraphQLURL(target.graphQLURL)
+			}
+			
+			results, err := validator.Validate()
+			if err != nil {
+				return err
+			}
+			
+			if !timestamp.IsZero() {
+				results.Timestamp = timestamp
+			}
+			
+			if baseline != nil {
+				baseline.Apply(results, time.Now())
+			}
+			
+			reporter := verifier.NewReporter(results).WithSort(sortKeys)
+			summary := reporter.GenerateSummary()
+			
+			fmt.Println(summary)
+			
+			if !results.OverallSuccess || (failOnExpired && len(results.ExpiredWaivers) > 0) {
+				success = false
+			}
+			allResults = append(allResults, results)
+		}
+		
+		var reporter *verifier.Reporter
+		if len(allResults) == 1 {
+			reporter = verifier.NewReporter(allResults[0])
+		} else {
+			reporter = verifier.NewSuiteReporter(verifier.NewSuiteResult(allResults))
+		}
+		reporter.WithSort(sortKeys).WithTimestamp(timestamp)
+		if len(allResults) > 1 {
+			fmt.Println("Compatibility Matrix:")
+			fmt.Println(reporter.GenerateMatrix())
+		}
+		
+		output := resultsOutput
+		if projectConfig != nil {
+			output = stringOption(cmd, "output", projectConfig.Report.Results)
+		}
+		if output != "" {
+			if err := reporter.GenerateReport("", "json", output); err != nil {
+				return err
+			}
+		}
+		
+		if writeBaseline != "" {
+			if err := verifier.NewBaseline(allResults...).Save(writeBaseline); err != nil {
+				return err
+			}
+			fmt.Printf("Baseline written to: %s\n", writeBaseline)
+		}
+		
+		if !success {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("contract verification failed")
+		}
 		return nil
 	},
 }
 
 func init() {
-	verifyCmd.Flags().StringVarP(&schemaPath, "schema", "s", "", "Path to the provider schema (required)")
-	verifyCmd.Flags().StringVarP(&mocksDir, "mocks", "m", "", "Directory containing consumer mocks (required)")
-	verifyCmd.Flags().StringVarP(&providerURL, "url", "u", "", "Base URL of the provider service (required)")
-	
-	verifyCmd.MarkFlagRequired("schema")
-	verifyCmd.MarkFlagRequired("mocks")
-	verifyCmd.MarkFlagRequired("url")
-}
\ No newline at end of file
+	verifyCmd.Flags().StringVarP(&schemaPath, "schema", "s", "", "Path to the provider schema (required without a config file)")
+	verifyCmd.Flags().StringVar(&asyncAPIPath, "asyncapi", "", "Path to the provider's AsyncAPI document (default: asyncapi.yaml next to the schema)")
+	verifyCmd.Flags().StringVar(&protosDir, "protos", "", "Directory of the provider's .proto files (default: the schema's directory)")
+	verifyCmd.Flags().StringVar(&graphQLPath, "graphql", "", "Path to the provider's GraphQL SDL (default: schema.graphql next to the schema)")
+	verifyCmd.Flags().StringVarP(&mocksDir, "mocks", "m", "", "Directory containing consumer mocks (required without a config file)")
+	verifyCmd.Flags().StringVarP(&providerURL, "url", "u", "", "Base URL of the provider service (required without a config file)")
+	verifyCmd.Flags().StringVar(&grpcURL, "grpc-url", "", "Address of the provider's gRPC server, host:port or URL, for live verification")
+	verifyCmd.Flags().StringVar(&graphQLURL, "graphql-url", "", "Provider's GraphQL endpoint for live verification (default: /graphql on --url)")
+	verifyCmd.Flags().StringVarP(&verifyProvider, "provider", "p", "", "Verify only this provider from the config file")
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "Verify every provider found in the contracts directory")
+	verifyCmd.Flags().StringVar(&contractsDir, "contracts", "contracts", "Contracts directory searched by --all")
+	verifyCmd.Flags().IntVar(&concurrency, "concurrency", runtime.NumCPU(), "Number of mocks verified in parallel")
+	verifyCmd.Flags().BoolVar(&liveVerify, "live", false, "Also replay each mock against the running provider")
+	verifyCmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum live requests per second to each provider (0 = unlimited)")
+	verifyCmd.Flags().StringVar(&sortBy, "sort", "", "Comma-separated sort keys: severity, consumer, endpoint (default: consumer,endpoint)")
+	verifyCmd.Flags().StringVar(&timestampFlag, "timestamp", "", "Fixed run timestamp (RFC 3339 or Unix seconds) for reproducible output")
+	verifyCmd.Flags().StringVarP(&resultsOutput, "output", "o", "", "Write verification results as JSON to this path")
+	verifyCmd.Flags().StringVar(&baselinePath, "baseline", "", "Baseline file of known issues and waivers; only new issues fail")
+	verifyCmd.Flags().StringVar(&writeBaseline, "write-baseline", "", "Snapshot the current issues into this baseline file")
+}
+
+// resolveVerifyTargets returns the single provider described by --schema, or
+// else the providers declared in the project configuration.
+func resolveVerifyTargets(cmd *cobra.Command) ([]verifyTarget, error) {
+	if verifyAll {
+		return discoverVerifyTargets(cmd)
+	}
+	
+	if projectConfig == nil || cmd.Flags().Changed("schema") {
+		err := requireOptions(map[string]string{
+			"schema": schemaPath,
+			"mocks":  mocksDir,
+			"url":    providerURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		
+		return []verifyTarget{{
+			provider:   verifyProvider,
+			schema:     schemaPath,
+			asyncAPI:   asyncAPIPath,
+			protos:     protosDir,
+			graphQL:    graphQLPath,
+			mocks:      mocksDir,
+			url:        providerURL,
+			grpcURL:    grpcURL,
+			graphQLURL: graphQLURL,
+			rateLimit:  rateLimit,
+		}}, nil
+	}
+	
+	names := projectConfig.ProviderNames()
+	if verifyProvider != "" {
+		if _, ok := projectConfig.Providers[verifyProvider]; !ok {
+			return nil, fmt.Errorf("provider %s is not declared in %s", verifyProvider, projectConfig.Path())
+		}
+		names = []string{verifyProvider}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no providers declared in %s", projectConfig.Path())
+	}
+	
+	var targets []verifyTarget
+	for _, name := range names {
+		provider := projectConfig.Providers[name]
+		target := verifyTarget{
+			provider:   name,
+			schema:     provider.Schema,
+			asyncAPI:   stringOption(cmd, "asyncapi", provider.AsyncAPI),
+			protos:     stringOption(cmd, "protos", provider.Protos),
+			graphQL:    stringOption(cmd, "graphql", provider.GraphQL),
+			mocks:      stringOption(cmd, "mocks", provider.Mocks),
+			url:        stringOption(cmd, "url", projectConfig.URL(name, projectConfig.Environment)),
+			grpcURL:    stringOption(cmd, "grpc-url", projectConfig.GRPCURL(name, projectConfig.Environment)),
+			graphQLURL: stringOption(cmd, "graphql-url", projectConfig.GraphQLURL(name, projectConfig.Environment)),
+			rateLimit:  rateLimit,
+		}
+		if !cmd.Flags().Changed("rate-limit") && provider.RateLimit > 0 {
+			target.rateLimit = provider.RateLimit
+		}
+		
+		if target.schema == "" || target.mocks == "" {
+			return nil, fmt.Errorf("provider %s: schema and mocks must be configured", name)
+		}
+		if target.url == "" {
+			return nil, fmt.Errorf("provider %s: no URL for environment %s", name, projectConfig.Environment)
+		}
+		
+		targets = append(targets, target)
+	}
+	
+	return targets, nil
+}
+
+// discoverVerifyTargets returns every provider with a schema in the contracts
+// directory. Provider URLs come from --url, the project configuration or the
+// first server declared in the schema, in that order.
+func discoverVerifyTargets(cmd *cobra.Command) ([]verifyTarget, error) {
+	repo := repository.NewContractRepository(contractsDir)
+	
+	names, err := repo.ListProviders()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no provider schemas found under %s", contractsDir)
+	}
+	
+	mocks := mocksDir
+	if mocks == "" {
+		mocks = repo.ConsumersPath()
+	}
+	
+	var targets []verifyTarget
+	for _, name := range names {
+		target := verifyTarget{
+			provider:   name,
+			schema:     repo.ProviderSchemaPath(name),
+			mocks:      mocks,
+			url:        providerURL,
+			grpcURL:    grpcURL,
+			graphQLURL: graphQLURL,
+			rateLimit:  rateLimit,
+		}
+		
+		if projectConfig != nil {
+			if target.url == "" {
+				target.url = projectConfig.URL(name, projectConfig.Environment)
+			}
+			if target.grpcURL == "" {
+				target.grpcURL = projectConfig.GRPCURL(name, projectConfig.Environment)
+			}
+			if target.graphQLURL == "" {
+				target.graphQLURL = projectConfig.GraphQLURL(name, projectConfig.Environment)
+			}
+			if configured := projectConfig.Providers[name].RateLimit; !cmd.Flags().Changed("rate-limit") && configured > 0 {
+				target.rateLimit = configured
+			}
+		}
+		if target.url == "" {
+			target.url, _ = schema.NewParser(target.schema).GetServerURL()
+		}
+		
+		targets = append(targets, target)
+	}
+	
+	return targets, nil
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/repository"
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/verifier"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookProvider  string
+	webhookSchema    string
+	webhookContracts string
+	webhookTarget    string
+	webhookConsumer  string
+	webhookName      string
+	webhookEndpoint  string
+	webhookRateLimit float64
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Work with the webhooks and callbacks providers send",
+	Long:  `Commands for the requests providers send their consumers: OpenAPI 3.1 webhooks and OpenAPI 3.0 callbacks.`,
+}
+
+var webhooksFireCmd = &cobra.Command{
+	Use:   "fire",
+	Short: "Send sample webhook deliveries to a consumer",
+	Long: `Sends a sample delivery of each of the provider's webhooks and callbacks to a
+consumer running locally at --target, and checks that the consumer answers with
+a status the provider declares for it.
+
+Deliveries are generated from the provider's schema: the declared example of
+the request body, or a value built from its schema, plus every required header.
+
+With --consumer, only the webhooks the consumer has contracts for are sent,
+each to the endpoint (request.endpoint) its contract names, and the consumer
+must answer with the status its contract promises. Otherwise every webhook is
+sent to --endpoint.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectConfig != nil && webhookProvider != "" {
+			if provider, ok := projectConfig.Providers[webhookProvider]; ok {
+				webhookSchema = stringOption(cmd, "schema", provider.Schema)
+			}
+		}
+		
+		repo := repository.NewContractRepository(webhookContracts)
+		if webhookSchema == "" && webhookProvider != "" {
+			webhookSchema = repo.ProviderSchemaPath(webhookProvider)
+		}
+		
+		err := requireOptions(map[string]string{
+			"provider": webhookProvider,
+			"target":   webhookTarget,
+		})
+		if err != nil {
+			return err
+		}
+		if target, err := url.Parse(webhookTarget); err != nil || target.Scheme == "" || target.Host == "" {
+			return fmt.Errorf("invalid target URL %q", webhookTarget)
+		}
+		
+		spec, err := schema.LoadSpec(webhookSchema)
+		if err != nil {
+			return err
+		}
+		
+		deliveries, err := planDeliveries(spec, repo)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return fmt.Errorf("no webhooks or callbacks to send for %s", webhookProvider)
+		}
+		
+		sender := verifier.NewWebhookSender(webhookTarget, webhookRateLimit)
+		failed := 0
+		for _, delivery := range deliveries {
+			status, issues := sender.Deliver(delivery.webhook, delivery.endpoint, delivery.status)
+			target := strings.TrimSuffix(webhookTarget, "/") + delivery.endpoint
+			if len(issues) == 0 {
+				fmt.Printf("✅ %s -> %s: %d\n", delivery.webhook, target, status)
+				continue
+			}
+			failed++
+			fmt.Printf("❌ %s -> %s\n", delivery.webhook, target)
+			for _, issue := range issues {
+				fmt.Printf("   • %s\n", issue.Description)
+			}
+		}
+		
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d webhook deliveries failed", failed, len(deliveries))
+		}
+		return nil
+	},
+}
+
+// webhookDelivery is one sample delivery to send: the webhook, the
+// consumer's endpoint and the status the consumer promises (zero if none).
+type webhookDelivery struct {
+	webhook  schema.Webhook
+	endpoint string
+	status   int
+}
+
+// planDeliveries picks the deliveries to send: one per webhook contract of
+// --consumer, or else one per webhook to --endpoint. --name keeps only the
+// webhook with that name.
+func planDeliveries(spec *schema.Spec, repo *repository.ContractRepository) ([]webhookDelivery, error) {
+	var deliveries []webhookDelivery
+	
+	if webhookConsumer == "" {
+		for _, webhook := range spec.Webhooks() {
+			if webhookName == "" || webhook.Name == webhookName {
+				deliveries = append(deliveries, webhookDelivery{webhook: webhook, endpoint: webhookEndpoint})
+			}
+		}
+		return deliveries, nil
+	}
+	
+	contracts, err := verifier.LoadContracts(repo.ConsumersPath(), webhookProvider)
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range contracts.Webhooks {
+		if contract.Consumer != webhookConsumer || webhookName != "" && contract.Webhook != webhookName {
+			continue
+		}
+		method := contract.Request.Method
+		if method == "" {
+			method = "post"
+		}
+		webhook, _ := spec.FindWebhook(contract.Webhook, method)
+		if webhook == nil {
+			return nil, fmt.Errorf("%s has no %s webhook %s", webhookProvider, strings.ToUpper(method), contract.Webhook)
+		}
+		endpoint := contract.Request.Endpoint
+		if endpoint == "" {
+			endpoint = webhookEndpoint
+		}
+		deliveries = append(deliveries, webhookDelivery{webhook: *webhook, endpoint: endpoint, status: contract.Response.StatusCode})
+	}
+	return deliveries, nil
+}
+
+func init() {
+	webhooksFireCmd.Flags().StringVarP(&webhookProvider, "provider", "p", "", "Provider whose webhooks are sent (required)")
+	webhooksFireCmd.Flags().StringVarP(&webhookSchema, "schema", "s", "", "Path to the provider schema (default: the provider's schema)")
+	webhooksFireCmd.Flags().StringVar(&webhookContracts, "contracts", "contracts", "Contracts directory")
+	webhooksFireCmd.Flags().StringVarP(&webhookTarget, "target", "t", "", "Base URL of the consumer receiving the deliveries (required)")
+	webhooksFireCmd.Flags().StringVar(&webhookConsumer, "consumer", "", "Send only the webhooks this consumer has contracts for, to their endpoints")
+	webhooksFireCmd.Flags().StringVar(&webhookName, "name", "", "Send only the webhook or callback with this name")
+	webhooksFireCmd.Flags().StringVar(&webhookEndpoint, "endpoint", "/", "Consumer path deliveries are sent to when no contract names one")
+	webhooksFireCmd.Flags().Float64Var(&webhookRateLimit, "rate-limit", 0, "Maximum deliveries per second (0 = unlimited)")
+	
+	webhooksCmd.AddCommand(webhooksFireCmd)
+}
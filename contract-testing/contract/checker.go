@@ -0,0 +1,327 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// ContractViolation describes a request or response that breaks the
+// provider's contract. It is also the error a strict CheckingTransport
+// returns.
+type ContractViolation struct {
+	Provider   string             `json:"provider,omitempty"`
+	Operation  string             `json:"operation,omitempty"`
+	Method     string             `json:"method"`
+	URL        string             `json:"url"`
+	Phase      string             `json:"phase"` // "request" or "response"
+	Violations []schema.Violation `json:"violations"`
+}
+
+func (v *ContractViolation) Error() string {
+	details := make([]string, len(v.Violations))
+	for i, violation := range v.Violations {
+		details[i] = violation.String()
+	}
+
+	subject := v.Method + " " + v.URL
+	if v.Operation != "" {
+		subject = v.Operation
+	}
+	contract := "the contract"
+	if v.Provider != "" {
+		contract = "the " + v.Provider + " contract"
+	}
+	return fmt.Sprintf("%s %s violates %s: %s", subject, v.Phase, contract, strings.Join(details, "; "))
+}
+
+// CheckingTransport is an http.RoundTripper that validates every request it
+// sends and every response it receives against a provider's OpenAPI schema,
+// using the same engine as verification.
+//
+// By default violations are handed to a report callback on a separate
+// goroutine and never affect the call: response bodies are passed through as
+// they arrive and checked once the caller has read them. In strict mode a
+// violating request is not sent and a violating response is discarded;
+// RoundTrip then returns the *ContractViolation as its error. Streaming
+// responses such as server-sent events only have their status and headers
+// checked, in either mode.
+type CheckingTransport struct {
+	next     http.RoundTripper
+	spec     *schema.Spec
+	provider string
+	strict   bool
+
+	reports chan ContractViolation
+	done    chan struct{}
+	dropped int64
+	mu      sync.RWMutex // guards closed against late reports
+	closed  bool
+}
+
+// reportBuffer is how many violations may wait for the report callback
+// before further ones are dropped.
+const reportBuffer = 1024
+
+// NewCheckingTransport wraps next, or http.DefaultTransport if next is nil,
+// and reports violations of spec to report, which may be nil.
+func NewCheckingTransport(next http.RoundTripper, provider string, spec *schema.Spec, report func(ContractViolation)) *CheckingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &CheckingTransport{
+		next:     next,
+		spec:     spec,
+		provider: provider,
+		reports:  make(chan ContractViolation, reportBuffer),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(t.done)
+		for violation := range t.reports {
+			if report != nil {
+				report(violation)
+			}
+		}
+	}()
+
+	return t
+}
+
+// NewCheckingTransportFromSchema is NewCheckingTransport for the OpenAPI
+// document at schemaPath.
+func NewCheckingTransportFromSchema(next http.RoundTripper, provider, schemaPath string, report func(ContractViolation)) (*CheckingTransport, error) {
+	document, err := schema.NewParser(schemaPath).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return NewCheckingTransport(next, provider, schema.NewSpec(document), report), nil
+}
+
+// LogViolations returns a report callback that writes each violation to logger.
+func LogViolations(logger *log.Logger) func(ContractViolation) {
+	return func(violation ContractViolation) {
+		logger.Printf("contract violation: %v", &violation)
+	}
+}
+
+// WithStrict makes violations fail the call instead of only being reported.
+func (t *CheckingTransport) WithStrict(strict bool) *CheckingTransport {
+	t.strict = strict
+	return t
+}
+
+// Dropped returns how many violations were not reported because the report
+// callback fell behind.
+func (t *CheckingTransport) Dropped() int64 {
+	return atomic.LoadInt64(&t.dropped)
+}
+
+// Close stops accepting reports and waits until every queued violation has
+// been passed to the report callback. The transport must not be used after;
+// violations in bodies read later are dropped.
+func (t *CheckingTransport) Close() {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.reports)
+	}
+	t.mu.Unlock()
+	<-t.done
+}
+
+func (t *CheckingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op, params := t.findOperation(req.Method, req.URL)
+	if op == nil {
+		violation := t.violation(req, nil, "request", []schema.Violation{{
+			Rule:    "operation",
+			Message: fmt.Sprintf("%s %s is not declared", req.Method, req.URL.Path),
+		}})
+		if t.strict {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, violation
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	violations := op.ValidateRequest(schema.RequestData{
+		PathParams: params,
+		Query:      req.URL.Query(),
+		Header:     req.Header,
+		Body:       reqBody,
+	})
+	if len(violations) > 0 {
+		violation := t.violation(req, op, "request", violations)
+		if t.strict {
+			return nil, violation
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	streaming := isStreaming(resp.StatusCode, resp.Header.Get("Content-Type"))
+
+	// In strict mode the whole body is checked before the caller sees it.
+	// Streams are never buffered; only their status and headers are checked.
+	if t.strict && !streaming {
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		violations = op.ValidateResponse(schema.ResponseData{
+			Status: resp.StatusCode,
+			Header: resp.Header,
+			Body:   respBody,
+		})
+		if len(violations) > 0 {
+			return nil, t.violation(req, op, "response", violations)
+		}
+		return resp, nil
+	}
+
+	violations = op.ValidateResponse(schema.ResponseData{
+		Status: resp.StatusCode,
+		Header: resp.Header,
+	})
+	if len(violations) > 0 {
+		violation := t.violation(req, op, "response", violations)
+		if t.strict {
+			resp.Body.Close()
+			return nil, violation
+		}
+	}
+
+	// Otherwise the body is passed through untouched and checked once the
+	// caller has read it.
+	if _, declared := op.Response(resp.StatusCode); declared && !streaming && resp.Body != nil {
+		status, header := resp.StatusCode, resp.Header
+		resp.Body = &checkedBody{
+			ReadCloser: resp.Body,
+			length:     resp.ContentLength,
+			check: func(body []byte) {
+				if violations := op.ValidateResponseBody(schema.ResponseData{Status: status, Header: header, Body: body}); len(violations) > 0 {
+					t.violation(req, op, "response", violations)
+				}
+			},
+		}
+	}
+
+	return resp, nil
+}
+
+// checkedBody passes a response body through to the caller and hands it to
+// check once the caller has read all of it, so that checking neither
+// buffers the call nor delays it.
+type checkedBody struct {
+	io.ReadCloser
+	buf    bytes.Buffer
+	length int64
+	check  func(body []byte)
+	once   sync.Once
+}
+
+func (b *checkedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.once.Do(func() { b.check(b.buf.Bytes()) })
+	}
+	return n, err
+}
+
+// Close checks the body if the caller read all of it without reading on to
+// EOF. A body closed part way through is incomplete and is not checked.
+func (b *checkedBody) Close() error {
+	if b.length >= 0 && int64(b.buf.Len()) == b.length {
+		b.once.Do(func() { b.check(b.buf.Bytes()) })
+	}
+	b.once.Do(func() {})
+	return b.ReadCloser.Close()
+}
+
+// isStreaming reports whether a response is a stream, such as server-sent
+// events or a protocol upgrade, whose body never ends on its own.
+func isStreaming(status int, contentType string) bool {
+	if status == http.StatusSwitchingProtocols {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])) {
+	case "text/event-stream", "application/x-ndjson", "application/stream+json", "multipart/x-mixed-replace":
+		return true
+	}
+	return false
+}
+
+// findOperation matches a request URL against the schema, first as is and
+// then without the base path of the schema's servers.
+func (t *CheckingTransport) findOperation(method string, u *url.URL) (*schema.Operation, map[string]string) {
+	if op, params, _ := t.spec.FindOperation(method, u.Path); op != nil {
+		return op, params
+	}
+
+	for _, basePath := range t.spec.BasePaths() {
+		if path := strings.TrimPrefix(u.Path, basePath); path != u.Path && strings.HasPrefix(path, "/") {
+			if op, params, _ := t.spec.FindOperation(method, path); op != nil {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+// violation queues a violation for the report callback and returns it.
+func (t *CheckingTransport) violation(req *http.Request, op *schema.Operation, phase string, violations []schema.Violation) *ContractViolation {
+	violation := &ContractViolation{
+		Provider:   t.provider,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Phase:      phase,
+		Violations: violations,
+	}
+	if op != nil {
+		violation.Operation = op.String()
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		atomic.AddInt64(&t.dropped, 1)
+		return violation
+	}
+	select {
+	case t.reports <- *violation:
+	default:
+		atomic.AddInt64(&t.dropped, 1)
+	}
+	return violation
+}
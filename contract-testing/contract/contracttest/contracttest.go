@@ -0,0 +1,91 @@
+// Package contracttest holds the helpers of package contract that run inside
+// go test. They live apart from it so that programs importing contract, such
+// as the ct binary, don't link the testing package.
+package contracttest
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Arpit529srivastava/contract"
+	"github.com/Arpit529srivastava/internal/stub"
+)
+
+// SaveOnSuccess saves the mocks transport recorded into dir when the test
+// finishes, unless it failed.
+func SaveOnSuccess(tb testing.TB, transport *contract.RecordingTransport, dir string) {
+	tb.Helper()
+	tb.Cleanup(func() {
+		if tb.Failed() {
+			return
+		}
+		if _, err := transport.Save(dir); err != nil {
+			tb.Errorf("failed to save recorded mocks: %v", err)
+		}
+	})
+}
+
+// Verify starts a mock server that answers the interactions declared on pact
+// and calls test with its base URL. The test fails if an interaction is
+// invalid, if a request matched no interaction or if an interaction was
+// never called. The mocks are written to pact's output directory only if
+// the whole test passed.
+func Verify(tb testing.TB, pact *contract.Builder, test func(baseURL string)) {
+	tb.Helper()
+
+	if errs := pact.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			tb.Errorf("%v", err)
+		}
+		return
+	}
+
+	mocks := pact.Mocks()
+	labels := make([]string, len(mocks))
+	for i, mock := range mocks {
+		labels[i] = fmt.Sprintf("interaction %q", mock.Description)
+	}
+
+	server := stub.NewMockServer(labels, mocks)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	test(httpServer.URL)
+
+	for _, unmatched := range server.Unmatched() {
+		message := unmatched.Error
+		if closest := unmatched.Closest; closest != nil {
+			message += fmt.Sprintf("; closest is %s: %s", closest.Path, strings.Join(closest.Mismatches, ", "))
+		}
+		tb.Errorf("%s", message)
+	}
+	for _, unused := range server.Unused() {
+		tb.Errorf("%s (%s %s) was never called", unused.Path, unused.Method, unused.Endpoint)
+	}
+
+	tb.Cleanup(func() {
+		if tb.Failed() {
+			return
+		}
+		if _, err := pact.Save(); err != nil {
+			tb.Errorf("failed to write mocks: %v", err)
+		}
+	})
+}
+
+// VerifyMessages checks the provider's message producers registered on v
+// against the message contracts under dir, and fails the test for every
+// violation and every contract without a producer.
+func VerifyMessages(tb testing.TB, v *contract.MessageVerifier, dir string) {
+	tb.Helper()
+
+	problems, err := v.Check(dir)
+	if err != nil {
+		tb.Fatalf("%v", err)
+	}
+	for _, problem := range problems {
+		tb.Errorf("%s", problem)
+	}
+}
@@ -0,0 +1,215 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// Builder declares the interactions a consumer expects from a provider, in
+// Go instead of hand-written mock files:
+//
+//	pact := contract.New("user-service", "order-service")
+//	pact.Given("user user_123 exists").
+//		UponReceiving("Create a new order").
+//		WithRequest("POST", "/orders").
+//		WithJSONBody(map[string]interface{}{"userId": "user_123", "items": items}).
+//		WillRespondWith(201, map[string]interface{}{"orderId": "ord_12345", "status": "pending"})
+//
+//	contracttest.Verify(t, pact, func(baseURL string) {
+//		_, err := provider.NewOrderService(baseURL).CreateOrder("user_123", items)
+//		...
+//	})
+//
+// contracttest.Verify runs the consumer's code against a mock server playing
+// the provider, fails the test if an interaction wasn't called or a request
+// matched none, and writes the mock files only if the test passed.
+type Builder struct {
+	consumer     string
+	provider     string
+	outputDir    string
+	interactions []*Interaction
+}
+
+// Interaction is one expected request and the response it gets.
+type Interaction struct {
+	mock   verifier.Mock
+	errors []string
+}
+
+// New starts declaring the interactions of consumer with provider. Mocks are
+// written to contracts/consumers/<consumer>/mocks unless WithOutputDir says
+// otherwise; relative paths are resolved against the test's package directory.
+func New(consumer, provider string) *Builder {
+	return &Builder{
+		consumer:  consumer,
+		provider:  provider,
+		outputDir: filepath.Join("contracts", "consumers", consumer, "mocks"),
+	}
+}
+
+// WithOutputDir sets the directory the mocks are written to.
+func (b *Builder) WithOutputDir(dir string) *Builder {
+	b.outputDir = dir
+	return b
+}
+
+// Given starts a new interaction that expects the provider to be in state.
+func (b *Builder) Given(state string) *Interaction {
+	return b.newInteraction().Given(state)
+}
+
+// UponReceiving starts a new interaction with the given description.
+func (b *Builder) UponReceiving(description string) *Interaction {
+	return b.newInteraction().UponReceiving(description)
+}
+
+func (b *Builder) newInteraction() *Interaction {
+	interaction := &Interaction{
+		mock: verifier.Mock{
+			Provider: b.provider,
+			Consumer: b.consumer,
+			Request: verifier.MockRequest{
+				Headers: map[string]string{},
+			},
+			Response: verifier.MockResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{},
+			},
+			Dependencies: []string{},
+		},
+	}
+	b.interactions = append(b.interactions, interaction)
+	return interaction
+}
+
+// Given sets the provider state the interaction expects.
+func (i *Interaction) Given(state string) *Interaction {
+	i.mock.ProviderState = state
+	return i
+}
+
+// UponReceiving sets the interaction's description.
+func (i *Interaction) UponReceiving(description string) *Interaction {
+	i.mock.Description = description
+	return i
+}
+
+// WithRequest sets the request method and endpoint. The endpoint may be a
+// path template such as /orders/{orderId}, filled in with WithParameter.
+func (i *Interaction) WithRequest(method, endpoint string) *Interaction {
+	i.mock.Request.Method = strings.ToUpper(method)
+	i.mock.Request.Endpoint = endpoint
+	return i
+}
+
+// WithParameter sets a path parameter, if the endpoint has one of that name,
+// or else a query parameter.
+func (i *Interaction) WithParameter(name, value string) *Interaction {
+	if i.mock.Request.Parameters == nil {
+		i.mock.Request.Parameters = map[string]string{}
+	}
+	i.mock.Request.Parameters[name] = value
+	return i
+}
+
+// WithHeader sets a request header the consumer sends.
+func (i *Interaction) WithHeader(name, value string) *Interaction {
+	i.mock.Request.Headers[name] = value
+	return i
+}
+
+// WithJSONBody sets the request body. body must encode to a JSON object.
+func (i *Interaction) WithJSONBody(body interface{}) *Interaction {
+	object, err := jsonObject(body)
+	if err != nil {
+		i.errors = append(i.errors, fmt.Sprintf("request body: %v", err))
+		return i
+	}
+	i.mock.Request.Body = object
+	if _, ok := i.mock.Request.Headers["Content-Type"]; !ok {
+		i.mock.Request.Headers["Content-Type"] = "application/json"
+	}
+	return i
+}
+
+// WillRespondWith sets the response status and, optionally, its JSON body,
+// which must encode to a JSON object.
+func (i *Interaction) WillRespondWith(status int, body ...interface{}) *Interaction {
+	i.mock.Response.StatusCode = status
+	if len(body) > 1 {
+		i.errors = append(i.errors, "response: more than one body given")
+	}
+	if len(body) > 0 {
+		object, err := jsonObject(body[0])
+		if err != nil {
+			i.errors = append(i.errors, fmt.Sprintf("response body: %v", err))
+			return i
+		}
+		i.mock.Response.Body = object
+		if _, ok := i.mock.Response.Headers["Content-Type"]; !ok {
+			i.mock.Response.Headers["Content-Type"] = "application/json"
+		}
+	}
+	return i
+}
+
+// WithResponseHeader sets a header of the response.
+func (i *Interaction) WithResponseHeader(name, value string) *Interaction {
+	i.mock.Response.Headers[name] = value
+	return i
+}
+
+// Mocks returns the declared interactions as mocks.
+func (b *Builder) Mocks() []verifier.Mock {
+	mocks := make([]verifier.Mock, len(b.interactions))
+	for i, interaction := range b.interactions {
+		mocks[i] = interaction.mock
+	}
+	return mocks
+}
+
+// Save writes the declared interactions as mocks into the output directory
+// and returns the files written.
+func (b *Builder) Save() ([]string, error) {
+	return SaveMocks(b.outputDir, b.Mocks())
+}
+
+// Validate reports incomplete or invalid interactions, one error each.
+func (b *Builder) Validate() []error {
+	var errs []error
+	for n, interaction := range b.interactions {
+		name := interaction.mock.Description
+		if name == "" {
+			name = fmt.Sprintf("#%d", n+1)
+			errs = append(errs, fmt.Errorf("interaction %s: UponReceiving is required", name))
+		}
+		if interaction.mock.Request.Method == "" || interaction.mock.Request.Endpoint == "" {
+			errs = append(errs, fmt.Errorf("interaction %s: WithRequest is required", name))
+		}
+		for _, err := range interaction.errors {
+			errs = append(errs, fmt.Errorf("interaction %s: %s", name, err))
+		}
+	}
+	if len(b.interactions) == 0 {
+		errs = append(errs, fmt.Errorf("no interactions declared between %s and %s", b.consumer, b.provider))
+	}
+	return errs
+}
+
+// jsonObject converts a value into the map form mocks store bodies in.
+func jsonObject(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("must be a JSON object")
+	}
+	return object, nil
+}
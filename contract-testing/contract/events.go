@@ -0,0 +1,31 @@
+package contract
+
+import (
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Event declares a message a provider publishes: its channel, payload type
+// and optionally a headers type. See RegisterEvent.
+type Event = schema.Event
+
+// RegisterEvent records that provider publishes event, so that
+// GenerateAsyncAPI and `generate --format asyncapi` can describe it:
+//
+//	func init() {
+//		contract.RegisterEvent("order-service", contract.Event{
+//			Channel: "order.status.changed",
+//			Payload: OrderStatusChanged{},
+//			Headers: EventHeader{},
+//		})
+//	}
+func RegisterEvent(provider string, event Event) {
+	schema.RegisterEvent(provider, event)
+}
+
+// GenerateAsyncAPI writes an AsyncAPI document of version (e.g. "2.6.0" or
+// "3.0.0") describing provider's registered events to outputPath.
+func GenerateAsyncAPI(provider, version, outputPath string) error {
+	return schema.NewAsyncGenerator(provider, schema.RegisteredEvents(provider)).
+		WithVersion(version).
+		GenerateSchema(outputPath)
+}
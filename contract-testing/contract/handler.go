@@ -0,0 +1,259 @@
+package contract
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/stub"
+)
+
+// HandlerMode selects what ValidateHandler checks.
+type HandlerMode int
+
+const (
+	// ModeProduction validates requests only.
+	ModeProduction HandlerMode = iota
+	// ModeDevelopment also validates responses and reports drift.
+	ModeDevelopment
+	// ModeTest also validates responses and replaces drifting ones with a 500.
+	ModeTest
+)
+
+// ParseHandlerMode parses "production", "development" or "test", e.g. from
+// an APP_ENV variable. An empty string is ModeProduction.
+func ParseHandlerMode(value string) (HandlerMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "production", "prod":
+		return ModeProduction, nil
+	case "development", "dev":
+		return ModeDevelopment, nil
+	case "test":
+		return ModeTest, nil
+	default:
+		return ModeProduction, fmt.Errorf("unknown handler mode %q (use production, development or test)", value)
+	}
+}
+
+// HandlerOption configures ValidateHandler.
+type HandlerOption func(*validatingHandler)
+
+// WithMode sets the handler mode; the default is ModeProduction.
+func WithMode(mode HandlerMode) HandlerOption {
+	return func(h *validatingHandler) {
+		h.mode = mode
+	}
+}
+
+// WithViolationReporter sets the callback that receives request and
+// response violations. By default they are written to the standard logger.
+func WithViolationReporter(report func(ContractViolation)) HandlerOption {
+	return func(h *validatingHandler) {
+		h.report = report
+	}
+}
+
+type validatingHandler struct {
+	spec   *schema.Spec
+	next   http.Handler
+	mode   HandlerMode
+	report func(ContractViolation)
+}
+
+// ValidateHandler wraps a provider's handler so that requests violating the
+// request side of spec are rejected with a 400 listing the violated rules,
+// before next sees them. Outside ModeProduction, responses are validated
+// too, so drift between a handler and its OpenAPI document shows up as soon
+// as it happens. Requests for operations spec doesn't declare are passed to
+// next unchecked.
+func ValidateHandler(spec *schema.Spec, next http.Handler, options ...HandlerOption) http.Handler {
+	h := &validatingHandler{
+		spec:   spec,
+		next:   next,
+		report: LogViolations(log.Default()),
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// ValidateHandlerFromSchema is ValidateHandler for the OpenAPI document at
+// schemaPath.
+func ValidateHandlerFromSchema(schemaPath string, next http.Handler, options ...HandlerOption) (http.Handler, error) {
+	document, err := schema.NewParser(schemaPath).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return ValidateHandler(schema.NewSpec(document), next, options...), nil
+}
+
+func (h *validatingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op, params, _ := h.spec.FindOperation(r.Method, r.URL.Path)
+	if op == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		writeContractError(w, http.StatusBadRequest, stub.ContractError{Error: "failed to read request body"})
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	violations := op.ValidateRequest(schema.RequestData{
+		PathParams: params,
+		Query:      r.URL.Query(),
+		Header:     r.Header,
+		Body:       body,
+	})
+	if len(violations) > 0 {
+		h.reportViolation(r, op, "request", violations)
+		writeContractError(w, http.StatusBadRequest, stub.ContractError{
+			Error:      fmt.Sprintf("request violates the contract for %s", op),
+			Operation:  op.String(),
+			Violations: violations,
+		})
+		return
+	}
+
+	if h.mode == ModeProduction {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	recorded := &responseBuffer{
+		w:      w,
+		header: make(http.Header),
+		status: http.StatusOK,
+		onStream: func(status int, header http.Header) {
+			if violations := op.ValidateResponse(schema.ResponseData{Status: status, Header: header}); len(violations) > 0 {
+				h.reportViolation(r, op, "response", violations)
+			}
+		},
+	}
+	h.next.ServeHTTP(recorded, r)
+	if recorded.streaming || recorded.hijacked {
+		return // Already sent
+	}
+
+	violations = op.ValidateResponse(schema.ResponseData{
+		Status: recorded.status,
+		Header: recorded.header,
+		Body:   recorded.body.Bytes(),
+	})
+	if len(violations) > 0 {
+		h.reportViolation(r, op, "response", violations)
+		if h.mode == ModeTest {
+			writeContractError(w, http.StatusInternalServerError, stub.ContractError{
+				Error:      fmt.Sprintf("response violates the contract for %s", op),
+				Operation:  op.String(),
+				Violations: violations,
+			})
+			return
+		}
+	}
+
+	for name, values := range recorded.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(recorded.status)
+	w.Write(recorded.body.Bytes())
+}
+
+func (h *validatingHandler) reportViolation(r *http.Request, op *schema.Operation, phase string, violations []schema.Violation) {
+	if h.report == nil {
+		return
+	}
+	h.report(ContractViolation{
+		Operation:  op.String(),
+		Method:     r.Method,
+		URL:        r.URL.RequestURI(),
+		Phase:      phase,
+		Violations: violations,
+	})
+}
+
+// responseBuffer holds a handler's response until it has been validated.
+// Streams, such as server-sent events, never end, so they are passed
+// straight through to w once their headers are written and only their
+// status and headers are checked, by onStream. Hijacked connections, such
+// as WebSockets, are not checked.
+type responseBuffer struct {
+	w           http.ResponseWriter
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	streaming   bool
+	hijacked    bool
+	onStream    func(status int, header http.Header)
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+
+	if isStreaming(status, b.header.Get("Content-Type")) {
+		b.streaming = true
+		b.onStream(status, b.header)
+		for name, values := range b.header {
+			b.w.Header()[name] = values
+		}
+		b.w.WriteHeader(status)
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	if b.streaming {
+		return b.w.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+// Flush sends what a stream has written so far. Other responses are sent
+// once they have been validated.
+func (b *responseBuffer) Flush() {
+	if !b.wroteHeader && isStreaming(http.StatusOK, b.header.Get("Content-Type")) {
+		b.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := b.w.(http.Flusher); ok && b.streaming {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection over to the handler, e.g. for a WebSocket.
+func (b *responseBuffer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := b.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		b.hijacked = true
+	}
+	return conn, rw, err
+}
+
+func writeContractError(w http.ResponseWriter, status int, body stub.ContractError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,127 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// Message is a message emitted by a provider's code. Payload is encoded as
+// JSON before it is checked.
+type Message struct {
+	Channel string
+	Headers map[string]string
+	Payload interface{}
+}
+
+// MessageProducer builds the message the provider emits for one consumer
+// expectation, using the same code the provider publishes with.
+type MessageProducer func() (Message, error)
+
+// MessageVerifier checks a provider's message producers against the message
+// contracts its consumers publish:
+//
+//	v, err := contract.NewMessageVerifier("order-service", "contracts/providers/order-service/asyncapi.yaml")
+//	...
+//	v.Register("Order status changed", func() (contract.Message, error) {
+//		event := provider.NewOrderStatusChanged("ord_1", "user_123", "pending", "shipped")
+//		return contract.Message{Channel: provider.OrderStatusChangedChannel, Headers: provider.EventHeaders("order.status.changed"), Payload: event}, nil
+//	})
+//	contracttest.VerifyMessages(t, v, "contracts/consumers")
+type MessageVerifier struct {
+	provider  string
+	spec      *schema.AsyncSpec
+	producers map[string]MessageProducer
+}
+
+// NewMessageVerifier creates a verifier for provider's messages, described
+// by the AsyncAPI document at asyncAPIPath.
+func NewMessageVerifier(provider, asyncAPIPath string) (*MessageVerifier, error) {
+	spec, err := schema.LoadAsyncSpec(asyncAPIPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AsyncAPI document: %w", err)
+	}
+	return &MessageVerifier{
+		provider:  provider,
+		spec:      spec,
+		producers: make(map[string]MessageProducer),
+	}, nil
+}
+
+// Register sets the producer for the message contracts with the given
+// description.
+func (v *MessageVerifier) Register(description string, producer MessageProducer) *MessageVerifier {
+	v.producers[description] = producer
+	return v
+}
+
+// Check loads every message contract for the provider under dir and, for
+// each, checks the expectation against the AsyncAPI document, calls the
+// registered producer and checks the message it builds against both. It
+// describes every violation and every contract without a producer.
+func (v *MessageVerifier) Check(dir string) ([]string, error) {
+	contracts, err := verifier.LoadContracts(dir, v.provider)
+	if err != nil {
+		return nil, err
+	}
+	paths, messages := contracts.MessagePaths, contracts.Messages
+	if len(messages) == 0 {
+		return []string{fmt.Sprintf("no message contracts for %s under %s", v.provider, dir)}, nil
+	}
+
+	var problems []string
+	for i, expected := range messages {
+		name := fmt.Sprintf("%s (%s, %s)", expected.Description, expected.Consumer, paths[i])
+
+		issues := verifier.MatchMessage(v.spec, expected, paths[i]).Issues
+
+		producer, ok := v.producers[expected.Description]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: no producer registered", name))
+		} else {
+			actual, err := produce(producer)
+			if err != nil {
+				issues = append(issues, verifier.Issue{
+					Code:        verifier.CodeProducerFailed,
+					Path:        "channel " + expected.Channel,
+					Description: err.Error(),
+					Severity:    "error",
+				})
+			} else {
+				issues = append(issues, verifier.VerifyProducedMessage(v.spec, expected, actual)...)
+			}
+		}
+
+		for _, issue := range issues {
+			if issue.Severity == "error" {
+				problems = append(problems, fmt.Sprintf("%s: %s: %s", name, issue.Path, issue.Description))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// produce calls a producer and decodes its payload the way a consumer would.
+func produce(producer MessageProducer) (verifier.ProducedMessage, error) {
+	message, err := producer()
+	if err != nil {
+		return verifier.ProducedMessage{}, fmt.Errorf("producer failed: %w", err)
+	}
+
+	data, err := json.Marshal(message.Payload)
+	if err != nil {
+		return verifier.ProducedMessage{}, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return verifier.ProducedMessage{}, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	return verifier.ProducedMessage{
+		Channel: message.Channel,
+		Headers: message.Headers,
+		Payload: payload,
+	}, nil
+}
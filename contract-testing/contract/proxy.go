@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// NewRecordingProxy returns a reverse proxy that forwards requests to target
+// and records every interaction with recorder. onRecord is called for each
+// interaction with whether it was new; it may be nil.
+func NewRecordingProxy(target *url.URL, recorder *Recorder, onRecord func(mock verifier.Mock, recorded bool)) http.Handler {
+	transport := NewRecordingTransport(nil, recorder)
+	transport.onRecord = onRecord
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = transport
+	return proxy
+}
@@ -0,0 +1,477 @@
+// Package contract captures and checks the HTTP interactions between a
+// consumer and its providers, producing mocks in the format verified by the
+// contract-testing CLI.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// Redacted replaces the values of redacted fields and headers.
+const Redacted = "[REDACTED]"
+
+// PlaceholderTimestamp replaces recorded date-time values.
+const PlaceholderTimestamp = "2025-01-01T00:00:00Z"
+
+// RecorderOptions configures how interactions become mocks.
+type RecorderOptions struct {
+	Consumer string
+	Provider string
+
+	// Spec, if set, maps request paths to the provider's path templates so
+	// that path segments are recorded as parameters.
+	Spec *schema.Spec
+
+	// Redact lists fields whose values are replaced with Redacted. A rule
+	// matches a field or header by name ("password") or by its dotted path,
+	// optionally qualified by side ("body.card.number", "response.headers.Set-Cookie").
+	Redact []string
+
+	// Headers lists headers recorded besides Content-Type.
+	Headers []string
+
+	// Placeholders replaces IDs, UUIDs and timestamps with stable values so
+	// that recordings don't change from run to run. The same recorded value
+	// always gets the same placeholder, so an ID returned by one interaction
+	// and sent in the next stays linked.
+	Placeholders bool
+}
+
+// Recorder turns HTTP interactions into consumer mocks. Interactions that
+// share a request shape with an earlier one are recorded only once: same
+// method, endpoint, parameter names, request body structure and status.
+type Recorder struct {
+	options      RecorderOptions
+	mu           sync.Mutex
+	mocks        []verifier.Mock
+	shapes       map[string]bool
+	placeholders map[string]string
+	counters     map[string]int
+}
+
+var (
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	idPattern    = regexp.MustCompile(`^(?:([A-Za-z]+)[_-])?([0-9A-Za-z]{4,})$`)
+	digitPattern = regexp.MustCompile(`[0-9]`)
+	slugPattern  = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// NewRecorder creates a recorder for one consumer/provider pair.
+func NewRecorder(options RecorderOptions) *Recorder {
+	return &Recorder{
+		options:      options,
+		shapes:       make(map[string]bool),
+		placeholders: make(map[string]string),
+		counters:     make(map[string]int),
+	}
+}
+
+// Known marks mocks that were recorded before, so interactions with the
+// same shape are not recorded again.
+func (r *Recorder) Known(mocks ...verifier.Mock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, mock := range mocks {
+		r.shapes[shapeKey(mock)] = true
+	}
+}
+
+// Record converts an interaction into a mock. The second result is false if
+// an interaction of the same shape was already recorded, in which case the
+// mock is not kept.
+func (r *Recorder) Record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) (verifier.Mock, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mock := verifier.Mock{
+		Provider: r.options.Provider,
+		Consumer: r.options.Consumer,
+		Request: verifier.MockRequest{
+			Method:  strings.ToUpper(req.Method),
+			Headers: r.recordHeaders("request", req.Header),
+			Body:    r.recordBody("request", req.Header.Get("Content-Type"), reqBody),
+		},
+		Response: verifier.MockResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    r.recordHeaders("response", resp.Header),
+			Body:       r.recordBody("response", resp.Header.Get("Content-Type"), respBody),
+		},
+		Dependencies: []string{},
+	}
+
+	endpoint, parameters := r.recordPath(req.URL.Path)
+	for name, values := range req.URL.Query() {
+		if len(values) > 0 {
+			parameters[name] = r.recordValue("request.parameters."+name, name, values[0])
+		}
+	}
+	mock.Request.Endpoint = endpoint
+	if len(parameters) > 0 {
+		mock.Request.Parameters = parameters
+	}
+	mock.Description = fmt.Sprintf("%s %s returning %d", mock.Request.Method, endpoint, resp.StatusCode)
+
+	key := shapeKey(mock)
+	if r.shapes[key] {
+		return mock, false
+	}
+	r.shapes[key] = true
+	r.mocks = append(r.mocks, mock)
+	return mock, true
+}
+
+// Mocks returns the mocks recorded so far, in recording order.
+func (r *Recorder) Mocks() []verifier.Mock {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]verifier.Mock{}, r.mocks...)
+}
+
+// recordPath returns the endpoint template for a path and the values of its
+// parameters. Without a spec, segments that look like IDs become {id}, {id2}, ...
+func (r *Recorder) recordPath(path string) (string, map[string]string) {
+	parameters := make(map[string]string)
+
+	if r.options.Spec != nil {
+		for _, method := range []string{"get", "post", "put", "patch", "delete", "head", "options"} {
+			if op, values, _ := r.options.Spec.FindOperation(method, path); op != nil {
+				for name, value := range values {
+					parameters[name] = r.recordValue("request.parameters."+name, name, value)
+				}
+				return op.Path, parameters
+			}
+		}
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !looksLikeID(segment) {
+			continue
+		}
+		name := "id"
+		if len(parameters) > 0 {
+			name = fmt.Sprintf("id%d", len(parameters)+1)
+		}
+		parameters[name] = r.recordValue("request.parameters."+name, name, segment)
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+func (r *Recorder) recordHeaders(side string, header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for _, name := range append([]string{"Content-Type"}, r.options.Headers...) {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		name = http.CanonicalHeaderKey(name)
+		if r.redacted(side+".headers."+name, name) {
+			value = Redacted
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// recordBody decodes a body as its Content-Type, JSON by default, and
+// records the value. Form, multipart and XML values are recorded as strings
+// and text bodies as a string. Empty bodies and media types that carry no
+// structured data, such as images, record no body.
+func (r *Recorder) recordBody(side, contentType string, data []byte) interface{} {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	value, violations, ok := schema.NewSpec(nil).DecodeBody(contentType, nil, data)
+	if !ok || value == nil && len(violations) > 0 {
+		return nil
+	}
+	return r.recordField(side+".body", "", value)
+}
+
+func (r *Recorder) recordObject(path string, object map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		result[key] = r.recordField(path+"."+key, key, value)
+	}
+	return result
+}
+
+func (r *Recorder) recordField(path, name string, value interface{}) interface{} {
+	if r.redacted(path, name) {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.recordObject(path, v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = r.recordField(path, name, item)
+		}
+		return items
+	case string:
+		return r.recordValue(path, name, v)
+	default:
+		return v
+	}
+}
+
+// recordValue redacts a string value or replaces it with a placeholder.
+func (r *Recorder) recordValue(path, name, value string) string {
+	if r.redacted(path, name) {
+		return Redacted
+	}
+	if !r.options.Placeholders {
+		return value
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return PlaceholderTimestamp
+	}
+	if placeholder, ok := r.placeholders[value]; ok {
+		return placeholder
+	}
+
+	var placeholder string
+	switch {
+	case uuidPattern.MatchString(value):
+		r.counters["uuid"]++
+		placeholder = fmt.Sprintf("00000000-0000-4000-8000-%012d", r.counters["uuid"])
+	case isIDField(path, name) && looksLikeID(value):
+		prefix := idPattern.FindStringSubmatch(value)[1]
+		r.counters[prefix]++
+		placeholder = fmt.Sprintf("%d", r.counters[prefix])
+		if prefix != "" {
+			placeholder = prefix + "_" + placeholder
+		}
+	default:
+		return value
+	}
+
+	r.placeholders[value] = placeholder
+	return placeholder
+}
+
+// redacted reports whether a redaction rule matches a field by name or by
+// a suffix of its dotted path.
+func (r *Recorder) redacted(path, name string) bool {
+	path = strings.ToLower(path)
+	for _, rule := range r.options.Redact {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		if rule == "" {
+			continue
+		}
+		if rule == strings.ToLower(name) || path == rule || strings.HasSuffix(path, "."+rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIDField reports whether a value names something: a path parameter or a
+// field called id, user_id, orderId and so on. Names such as paid or valid
+// don't count, so the Id of a camel-case name must start a new word.
+func isIDField(path, name string) bool {
+	if strings.HasPrefix(path, "request.parameters.") {
+		return true
+	}
+	if lower := strings.ToLower(name); lower == "id" || strings.HasSuffix(lower, "_id") {
+		return true
+	}
+	for _, suffix := range []string{"Id", "ID"} {
+		if rest := strings.TrimSuffix(name, suffix); rest != name && rest != "" {
+			last := rest[len(rest)-1]
+			if 'a' <= last && last <= 'z' || '0' <= last && last <= '9' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// looksLikeID reports whether a value looks generated rather than chosen:
+// a UUID, a number, or a token containing digits such as ord_8f3a9c.
+func looksLikeID(value string) bool {
+	if uuidPattern.MatchString(value) {
+		return true
+	}
+	return idPattern.MatchString(value) && digitPattern.MatchString(value)
+}
+
+// shapeKey identifies the request shape of a mock for de-duplication.
+func shapeKey(mock verifier.Mock) string {
+	var names []string
+	for name := range mock.Request.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join([]string{
+		strings.ToUpper(mock.Request.Method),
+		mock.Request.Endpoint,
+		strings.Join(names, ","),
+		bodyShape(mock.Request.Body),
+		fmt.Sprintf("%d", mock.Response.StatusCode),
+	}, " ")
+}
+
+// mergeMock refreshes an existing mock with a recorded one. The recorded
+// request and response replace the existing ones, except for {{name}}
+// placeholders filled in by a workflow's captures, the status range and the
+// matching rules, which stay unless the recording brings its own rules.
+func mergeMock(existing, recorded verifier.Mock) verifier.Mock {
+	merged := existing
+	merged.Provider = recorded.Provider
+	merged.Consumer = recorded.Consumer
+	merged.Request = recorded.Request
+	merged.Response = recorded.Response
+	merged.Response.StatusRange = existing.Response.StatusRange
+
+	merged.Request.Parameters = keepPlaceholderStrings(existing.Request.Parameters, recorded.Request.Parameters)
+	merged.Request.Headers = keepPlaceholderStrings(existing.Request.Headers, recorded.Request.Headers)
+	merged.Request.Body = keepPlaceholders(existing.Request.Body, recorded.Request.Body)
+	merged.Response.Headers = keepPlaceholderStrings(existing.Response.Headers, recorded.Response.Headers)
+	merged.Response.Body = keepPlaceholders(existing.Response.Body, recorded.Response.Body)
+
+	if len(recorded.Request.MatchingRules) == 0 {
+		merged.Request.MatchingRules = existing.Request.MatchingRules
+	}
+	if len(recorded.Response.MatchingRules) == 0 {
+		merged.Response.MatchingRules = existing.Response.MatchingRules
+	}
+	if merged.Description == "" {
+		merged.Description = recorded.Description
+	}
+	if merged.ProviderState == "" {
+		merged.ProviderState = recorded.ProviderState
+	}
+	return merged
+}
+
+// keepPlaceholders returns the recorded value with every string the
+// existing value holds a {{name}} placeholder for put back.
+func keepPlaceholders(existing, recorded interface{}) interface{} {
+	if text, ok := existing.(string); ok && strings.Contains(text, "{{") {
+		return text
+	}
+	switch v := recorded.(type) {
+	case map[string]interface{}:
+		old, _ := existing.(map[string]interface{})
+		merged := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			merged[key] = keepPlaceholders(old[key], value)
+		}
+		return merged
+	case []interface{}:
+		old, _ := existing.([]interface{})
+		merged := make([]interface{}, len(v))
+		for i, value := range v {
+			var previous interface{}
+			if i < len(old) {
+				previous = old[i]
+			}
+			merged[i] = keepPlaceholders(previous, value)
+		}
+		return merged
+	}
+	return recorded
+}
+
+func keepPlaceholderStrings(existing, recorded map[string]string) map[string]string {
+	if recorded == nil {
+		return nil
+	}
+	merged := make(map[string]string, len(recorded))
+	for key, value := range recorded {
+		merged[key] = value
+		if strings.Contains(existing[key], "{{") {
+			merged[key] = existing[key]
+		}
+	}
+	return merged
+}
+
+// bodyShape describes the structure of a body without its values.
+func bodyShape(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return "null"
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ":" + bodyShape(v[key])
+		}
+		return "{" + strings.Join(fields, ",") + "}"
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		return "[" + bodyShape(v[0]) + "]"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// WriteMock writes a mock into dir as indented JSON and returns the file
+// path. Files are named after the mock's method, endpoint and status, with a
+// numeric suffix if that name is taken.
+func WriteMock(dir string, mock verifier.Mock) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create mocks directory: %w", err)
+	}
+
+	base := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(fmt.Sprintf("%s %s %d", mock.Request.Method, mock.Request.Endpoint, mock.Response.StatusCode)), "_"), "_")
+	path := filepath.Join(dir, base+".json")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s_%d.json", base, n))
+	}
+
+	return path, writeMockFile(path, mock)
+}
+
+func writeMockFile(path string, mock verifier.Mock) error {
+	data, err := json.MarshalIndent(mock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mock: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write mock file: %w", err)
+	}
+	return nil
+}
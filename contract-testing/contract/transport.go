@@ -0,0 +1,135 @@
+package contract
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/Arpit529srivastava/internal/stub"
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// RecordingTransport is an http.RoundTripper that records every request it
+// forwards, and the response it gets back, with a Recorder. Plug it into a
+// consumer's provider client during go test to capture the consumer's real
+// interactions as mocks:
+//
+//	transport := contract.NewRecordingTransport(nil, contract.NewRecorder(contract.RecorderOptions{
+//		Consumer: "user-service",
+//		Provider: "order-service",
+//	}))
+//	contracttest.SaveOnSuccess(t, transport, "contracts/consumers/user-service/mocks")
+//	orders := provider.NewOrderService(server.URL).WithTransport(transport)
+type RecordingTransport struct {
+	next     http.RoundTripper
+	recorder *Recorder
+	onRecord func(mock verifier.Mock, recorded bool)
+}
+
+// NewRecordingTransport wraps next, or http.DefaultTransport if next is nil.
+func NewRecordingTransport(next http.RoundTripper, recorder *Recorder) *RecordingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RecordingTransport{
+		next:     next,
+		recorder: recorder,
+	}
+}
+
+// Recorder returns the recorder the transport records with.
+func (t *RecordingTransport) Recorder() *Recorder {
+	return t.recorder
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+
+		// RoundTrippers must not modify the caller's request.
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	recordedBody := respBody
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		if reader, err := gzip.NewReader(bytes.NewReader(respBody)); err == nil {
+			if data, err := io.ReadAll(reader); err == nil {
+				recordedBody = data
+			}
+		}
+	}
+
+	mock, recorded := t.recorder.Record(req, reqBody, resp, recordedBody)
+	if t.onRecord != nil {
+		t.onRecord(mock, recorded)
+	}
+
+	return resp, nil
+}
+
+// Save writes the recorded mocks into dir and returns the files written.
+func (t *RecordingTransport) Save(dir string) ([]string, error) {
+	return SaveMocks(dir, t.recorder.Mocks())
+}
+
+// SaveMocks writes mocks into dir. A mock with the same request shape as a
+// mock already in dir refreshes that file instead of adding a copy: its
+// request and response are replaced, but the hand-written parts of the file
+// (description, provider state, dependencies, captures, status range and
+// matching rules) are kept, so workflows that depend on it keep working.
+func SaveMocks(dir string, mocks []verifier.Mock) ([]string, error) {
+	existing := make(map[string]int)
+	var paths []string
+	var known []verifier.Mock
+	if _, err := os.Stat(dir); err == nil {
+		if paths, known, err = stub.LoadMocks(dir, ""); err != nil {
+			return nil, err
+		}
+		for i, mock := range known {
+			existing[shapeKey(mock)] = i
+		}
+	}
+
+	var written []string
+	for _, mock := range mocks {
+		if i, ok := existing[shapeKey(mock)]; ok {
+			known[i] = mergeMock(known[i], mock)
+			if err := writeMockFile(paths[i], known[i]); err != nil {
+				return written, err
+			}
+			written = append(written, paths[i])
+			continue
+		}
+
+		path, err := WriteMock(dir, mock)
+		if err != nil {
+			return written, err
+		}
+		existing[shapeKey(mock)] = len(known)
+		paths = append(paths, path)
+		known = append(known, mock)
+		written = append(written, path)
+	}
+	return written, nil
+}
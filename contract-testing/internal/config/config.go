@@ -0,0 +1,278 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFile is the name of the project configuration file.
+const DefaultFile = "contract-testing.yaml"
+
+// DefaultEnvironment is the environment used when none is selected.
+const DefaultEnvironment = "local"
+
+// Config describes a contract-testing project: its providers, where their
+// contracts live and how verification results are reported.
+type Config struct {
+	Environment string              `yaml:"environment"`
+	Providers   map[string]Provider `yaml:"providers"`
+	Report      Report              `yaml:"report"`
+	Policy      Policy              `yaml:"policy"`
+
+	path string
+}
+
+// Provider declares a provider's schema, the AsyncAPI document of the
+// messages it emits, the directory of its .proto files, its GraphQL SDL,
+// the mocks its consumers publish, its base URL, gRPC address and GraphQL
+// endpoint in each environment, how many requests per second live
+// verification may send it and the fault profiles its stub server injects.
+type Provider struct {
+	Schema      string            `yaml:"schema"`
+	AsyncAPI    string            `yaml:"asyncapi"`
+	Protos      string            `yaml:"protos"`
+	GraphQL     string            `yaml:"graphql"`
+	Mocks       string            `yaml:"mocks"`
+	URLs        map[string]string `yaml:"urls"`
+	GRPCURLs    map[string]string `yaml:"grpcUrls"`
+	GraphQLURLs map[string]string `yaml:"graphqlUrls"`
+	RateLimit   float64           `yaml:"rateLimit"`
+	Faults      string            `yaml:"faults"`
+}
+
+// Report configures where verification results and reports are written.
+type Report struct {
+	Results string `yaml:"results"`
+	Format  string `yaml:"format"`
+	Output  string `yaml:"output"`
+}
+
+// Policy configures how strictly verification results are judged.
+type Policy struct {
+	Baseline             string `yaml:"baseline"`
+	FailOnExpiredWaivers bool   `yaml:"failOnExpiredWaivers"`
+}
+
+// Find looks for DefaultFile in start and its parents and returns the first
+// match, relative to start when possible.
+func Find(start string) (string, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", false
+	}
+	origin := dir
+
+	for {
+		path := filepath.Join(dir, DefaultFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			if rel, err := filepath.Rel(origin, path); err == nil {
+				return filepath.Join(start, rel), true
+			}
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Load reads a configuration file. Relative paths in the file are resolved
+// against the directory that contains it.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	cfg.path = path
+	if cfg.Environment == "" {
+		cfg.Environment = DefaultEnvironment
+	}
+
+	dir := filepath.Dir(path)
+	for name, provider := range cfg.Providers {
+		provider.Schema = resolve(dir, provider.Schema)
+		provider.AsyncAPI = resolve(dir, provider.AsyncAPI)
+		provider.Protos = resolve(dir, provider.Protos)
+		provider.GraphQL = resolve(dir, provider.GraphQL)
+		provider.Mocks = resolve(dir, provider.Mocks)
+		provider.Faults = resolve(dir, provider.Faults)
+		cfg.Providers[name] = provider
+	}
+	cfg.Report.Results = resolve(dir, cfg.Report.Results)
+	cfg.Report.Output = resolve(dir, cfg.Report.Output)
+	cfg.Policy.Baseline = resolve(dir, cfg.Policy.Baseline)
+
+	return &cfg, nil
+}
+
+// Path returns the file the configuration was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// ProviderNames returns the declared providers in alphabetical order.
+func (c *Config) ProviderNames() []string {
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// URL returns the base URL of a provider in the given environment.
+func (c *Config) URL(providerName, env string) string {
+	return c.Providers[providerName].URLs[env]
+}
+
+// GRPCURL returns the address of a provider's gRPC server in the given
+// environment.
+func (c *Config) GRPCURL(providerName, env string) string {
+	return c.Providers[providerName].GRPCURLs[env]
+}
+
+// GraphQLURL returns the GraphQL endpoint of a provider in the given
+// environment.
+func (c *Config) GraphQLURL(providerName, env string) string {
+	return c.Providers[providerName].GraphQLURLs[env]
+}
+
+// Validate checks the configuration for missing or inconsistent settings and
+// returns every problem it finds.
+func (c *Config) Validate() []error {
+	var problems []error
+
+	if len(c.Providers) == 0 {
+		problems = append(problems, fmt.Errorf("no providers declared"))
+	}
+
+	for _, name := range c.ProviderNames() {
+		provider := c.Providers[name]
+
+		if provider.Schema == "" {
+			problems = append(problems, fmt.Errorf("provider %s: schema is required", name))
+		} else if _, err := os.Stat(provider.Schema); err != nil {
+			problems = append(problems, fmt.Errorf("provider %s: schema %s not found", name, provider.Schema))
+		}
+
+		if provider.AsyncAPI != "" {
+			if _, err := os.Stat(provider.AsyncAPI); err != nil {
+				problems = append(problems, fmt.Errorf("provider %s: AsyncAPI document %s not found", name, provider.AsyncAPI))
+			}
+		}
+
+		if provider.Protos != "" {
+			if info, err := os.Stat(provider.Protos); err != nil || !info.IsDir() {
+				problems = append(problems, fmt.Errorf("provider %s: protos directory %s not found", name, provider.Protos))
+			}
+		}
+
+		if provider.GraphQL != "" {
+			if _, err := os.Stat(provider.GraphQL); err != nil {
+				problems = append(problems, fmt.Errorf("provider %s: GraphQL schema %s not found", name, provider.GraphQL))
+			}
+		}
+
+		if provider.Mocks == "" {
+			problems = append(problems, fmt.Errorf("provider %s: mocks is required", name))
+		} else if info, err := os.Stat(provider.Mocks); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Errorf("provider %s: mocks directory %s not found", name, provider.Mocks))
+		}
+
+		if _, ok := provider.URLs[c.Environment]; !ok {
+			problems = append(problems, fmt.Errorf("provider %s: no URL for environment %s", name, c.Environment))
+		}
+
+		if provider.RateLimit < 0 {
+			problems = append(problems, fmt.Errorf("provider %s: rateLimit must not be negative", name))
+		}
+
+		if provider.Faults != "" {
+			if _, err := os.Stat(provider.Faults); err != nil {
+				problems = append(problems, fmt.Errorf("provider %s: fault profiles %s not found", name, provider.Faults))
+			}
+		}
+
+		envs := make([]string, 0, len(provider.URLs))
+		for env := range provider.URLs {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+		for _, env := range envs {
+			parsed, err := url.Parse(provider.URLs[env])
+			if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+				problems = append(problems, fmt.Errorf("provider %s: invalid %s URL %q", name, env, provider.URLs[env]))
+			}
+		}
+
+		envs = envs[:0]
+		for env := range provider.GRPCURLs {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+		for _, env := range envs {
+			if !validGRPCAddress(provider.GRPCURLs[env]) {
+				problems = append(problems, fmt.Errorf("provider %s: invalid %s gRPC address %q", name, env, provider.GRPCURLs[env]))
+			}
+		}
+
+		envs = envs[:0]
+		for env := range provider.GraphQLURLs {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+		for _, env := range envs {
+			parsed, err := url.Parse(provider.GraphQLURLs[env])
+			if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+				problems = append(problems, fmt.Errorf("provider %s: invalid %s GraphQL URL %q", name, env, provider.GraphQLURLs[env]))
+			}
+		}
+	}
+
+	switch c.Report.Format {
+	case "", "html", "json", "markdown":
+	default:
+		problems = append(problems, fmt.Errorf("report: unsupported format %s", c.Report.Format))
+	}
+
+	if c.Policy.Baseline != "" {
+		if _, err := os.Stat(c.Policy.Baseline); err != nil {
+			problems = append(problems, fmt.Errorf("policy: baseline %s not found", c.Policy.Baseline))
+		}
+	}
+
+	return problems
+}
+
+// validGRPCAddress accepts host:port and http:// or https:// URLs.
+func validGRPCAddress(address string) bool {
+	if strings.Contains(address, "://") {
+		parsed, err := url.Parse(address)
+		return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+	}
+	_, port, err := net.SplitHostPort(address)
+	return err == nil && port != ""
+}
+
+func resolve(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
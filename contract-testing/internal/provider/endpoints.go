@@ -2,7 +2,9 @@ package provider
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -27,6 +29,16 @@ func GetOrderServiceEndpoints() []Endpoint {
 			Method:  "GET",
 			Handler: getOrderHandler,
 		},
+		{
+			Path:    "/orders/{orderId}/events",
+			Method:  "GET",
+			Handler: orderEventsHandler,
+		},
+		{
+			Path:    "/graphql",
+			Method:  "POST",
+			Handler: graphQLHandler,
+		},
 	}
 }
 
@@ -38,32 +50,32 @@ func createOrderHandler(w http.ResponseWriter, r *http.Request) {
 	
 	var request map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "Invalid request format",
-		})
+		writeProblem(w, http.StatusBadRequest, "Invalid request format", err.Error())
 		return
 	}
 	
 	// Validate required fields
 	userID, ok := request["userId"].(string)
 	if !ok || userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "userId is required and must be a string",
-		})
+		writeProblem(w, http.StatusBadRequest, "Invalid request", "userId is required and must be a string")
 		return
 	}
 	
 	items, ok := request["items"].([]interface{})
 	if !ok || len(items) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "items is required and must be a non-empty array",
-		})
+		writeProblem(w, http.StatusBadRequest, "Invalid request", "items is required and must be a non-empty array")
 		return
 	}
 	
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		productID, _ := fields["productId"].(string)
+		if !catalog[productID] {
+			writeProblem(w, http.StatusBadRequest, "Unknown product", fmt.Sprintf("product %q is not in the catalog", productID))
+			return
+		}
+	}
+	
 	// Create a new order (in a real implementation, this would interact with a database)
 	orderID := "ord_" + generateRandomID()
 	
@@ -74,10 +86,30 @@ func createOrderHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/orders/"+orderID)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
+// catalog holds the products orders can be placed for.
+var catalog = map[string]bool{
+	"prod_1": true,
+	"prod_2": true,
+	"prod_3": true,
+}
+
+// writeProblem writes an RFC 7807 problem details response.
+func writeProblem(w http.ResponseWriter, status int, title, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":   "about:blank",
+		"title":  title,
+		"status": status,
+		"detail": detail,
+	})
+}
+
 func getOrderHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -110,6 +142,21 @@ func getOrderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	createdAt := time.Now().Format(time.RFC3339)
+	if acceptsXML(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, xml.Header)
+		xml.NewEncoder(w).Encode(xmlOrder{
+			OrderID:   orderID,
+			UserID:    "user_123",
+			Status:    "pending",
+			Items:     []xmlOrderItem{{ProductID: "prod_1", Quantity: 2}},
+			CreatedAt: createdAt,
+		})
+		return
+	}
+	
 	response := map[string]interface{}{
 		"orderId": orderID,
 		"userId": "user_123",
@@ -120,7 +167,7 @@ func getOrderHandler(w http.ResponseWriter, r *http.Request) {
 				"quantity":  2,
 			},
 		},
-		"createdAt": time.Now().Format(time.RFC3339),
+		"createdAt": createdAt,
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -128,6 +175,76 @@ func getOrderHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// xmlOrder is the application/xml form of an order.
+type xmlOrder struct {
+	XMLName   xml.Name       `xml:"order"`
+	OrderID   string         `xml:"id,attr"`
+	UserID    string         `xml:"userId"`
+	Status    string         `xml:"status"`
+	Items     []xmlOrderItem `xml:"items>item"`
+	CreatedAt string         `xml:"createdAt"`
+}
+
+type xmlOrderItem struct {
+	ProductID string `xml:"productId"`
+	Quantity  int    `xml:"quantity"`
+}
+
+// acceptsXML reports whether an Accept header asks for XML before JSON.
+func acceptsXML(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case "application/xml", "text/xml":
+			return true
+		case "application/json", "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// orderEventsHandler streams an order's status updates as server-sent
+// "status" events, one step of its lifecycle at a time.
+func orderEventsHandler(w http.ResponseWriter, r *http.Request) {
+	regex := regexp.MustCompile(`/orders/([^/]+)/events`)
+	matches := regex.FindStringSubmatch(r.URL.Path)
+	if len(matches) < 2 || strings.HasPrefix(matches[1], "notfound_") {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Order not found",
+		})
+		return
+	}
+	
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	
+	for _, status := range []string{"processing", "shipped", "delivered"} {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+		
+		data, _ := json.Marshal(map[string]interface{}{
+			"orderId":   matches[1],
+			"status":    status,
+			"updatedAt": time.Now().Format(time.RFC3339),
+		})
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+}
+
 func generateRandomID() string {
 	// In a real implementation, this would generate a unique ID
 	return fmt.Sprintf("%d", time.Now().UnixNano())
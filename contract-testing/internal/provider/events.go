@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// OrderStatusChangedChannel is the channel order status events are published on.
+const OrderStatusChangedChannel = "order.status.changed"
+
+// OrderStatusChanged is the event published when an order moves to a new status.
+type OrderStatusChanged struct {
+	OrderID        string    `json:"orderId"`
+	UserID         string    `json:"userId,omitempty"`
+	PreviousStatus string    `json:"previousStatus,omitempty" enum:"pending,processing,shipped,delivered,cancelled"`
+	Status         string    `json:"status" enum:"pending,processing,shipped,delivered,cancelled"`
+	ChangedAt      time.Time `json:"changedAt"`
+}
+
+// EventHeader is the set of headers published with every event.
+type EventHeader struct {
+	EventType    string `json:"eventType"`
+	EventVersion int    `json:"eventVersion,omitempty"`
+}
+
+func init() {
+	schema.RegisterEvent("order-service", schema.Event{
+		Channel: OrderStatusChangedChannel,
+		Summary: "An order moved to a new status",
+		Payload: OrderStatusChanged{},
+		Headers: EventHeader{},
+	})
+}
+
+// EventHeaders returns the headers published with an event of eventType.
+func EventHeaders(eventType string) map[string]string {
+	return map[string]string{
+		"eventType":    eventType,
+		"eventVersion": "1",
+	}
+}
+
+// NewOrderStatusChanged builds the event for an order moving from previous
+// to status.
+func NewOrderStatusChanged(orderID, userID, previous, status string) OrderStatusChanged {
+	return OrderStatusChanged{
+		OrderID:        orderID,
+		UserID:         userID,
+		PreviousStatus: previous,
+		Status:         status,
+		ChangedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// GraphQLSchemaPath is the order service's published SDL, read the first
+// time graphQLHandler runs. The default is relative to the contract-testing
+// directory, where the sample provider is run from.
+var GraphQLSchemaPath = "contracts/providers/order-service/schema.graphql"
+
+var (
+	graphQLOnce   sync.Once
+	graphQLSchema *schema.GraphQLSchema
+	graphQLErr    error
+)
+
+// loadGraphQLSchema parses GraphQLSchemaPath once.
+func loadGraphQLSchema() (*schema.GraphQLSchema, error) {
+	graphQLOnce.Do(func() {
+		graphQLSchema, graphQLErr = schema.LoadGraphQLSchema(GraphQLSchemaPath)
+	})
+	return graphQLSchema, graphQLErr
+}
+
+// graphQLHandler serves queries against the order service's SDL over HTTP
+// POST. Root fields are resolved from the operation's variables named after
+// their arguments, so order takes $id and orders takes $userId. Mutations
+// are not implemented.
+func graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	sdl, err := loadGraphQLSchema()
+	if err != nil {
+		writeGraphQLErrors(w, http.StatusInternalServerError, fmt.Sprintf("GraphQL schema unavailable: %v", err))
+		return
+	}
+
+	var request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	query, err := sdl.ParseQuery(request.Query, request.OperationName)
+	if err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var problems []string
+	for _, violation := range query.Validate(request.Variables) {
+		if violation.Rule != "deprecated" {
+			problems = append(problems, strings.TrimSpace(violation.Path+": "+violation.Message))
+		}
+	}
+	if len(problems) > 0 {
+		writeGraphQLErrors(w, http.StatusBadRequest, problems...)
+		return
+	}
+	if query.Operation() != "query" {
+		writeGraphQLErrors(w, http.StatusOK, fmt.Sprintf("%s operations are not implemented", query.Operation()))
+		return
+	}
+
+	id, _ := request.Variables["id"].(string)
+	userID, _ := request.Variables["userId"].(string)
+	var order interface{}
+	if !strings.HasPrefix(id, "notfound_") {
+		order = graphQLOrder(id, "user_123")
+	}
+	root := map[string]interface{}{
+		"order":  order,
+		"orders": []interface{}{graphQLOrder("ord_"+generateRandomID(), userID)},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": selectGraphQLFields(query.ResponseSchema(), root),
+	})
+}
+
+// graphQLOrder is the GraphQL form of the order getOrderHandler returns.
+func graphQLOrder(id, userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     id,
+		"userId": userID,
+		"status": "PENDING",
+		"items": []interface{}{
+			map[string]interface{}{"productId": "prod_1", "quantity": 2},
+		},
+		"createdAt": time.Now().Format(time.RFC3339),
+	}
+}
+
+// selectGraphQLFields cuts value down to the fields the response schema of
+// a query lists.
+func selectGraphQLFields(node, value interface{}) interface{} {
+	def, _ := node.(map[interface{}]interface{})
+	switch value := value.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = selectGraphQLFields(def["items"], item)
+		}
+		return items
+	case map[string]interface{}:
+		properties, _ := def["properties"].(map[interface{}]interface{})
+		selected := make(map[string]interface{}, len(properties))
+		for key, property := range properties {
+			name, _ := key.(string)
+			selected[name] = selectGraphQLFields(property, value[name])
+		}
+		return selected
+	default:
+		return value
+	}
+}
+
+func writeGraphQLErrors(w http.ResponseWriter, status int, messages ...string) {
+	errors := make([]map[string]string, len(messages))
+	for i, message := range messages {
+		errors[i] = map[string]string{"message": message}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": errors})
+}
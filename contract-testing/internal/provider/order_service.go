@@ -22,6 +22,23 @@ func NewOrderService(baseURL string) *OrderService {
 	}
 }
 
+// NewOrderServiceWithClient creates an order service client that sends its
+// requests through client, e.g. one with a recording or checking transport.
+func NewOrderServiceWithClient(baseURL string, client *http.Client) *OrderService {
+	return &OrderService{
+		baseURL: baseURL,
+		client:  client,
+	}
+}
+
+// WithTransport sends the client's requests through transport.
+func (s *OrderService) WithTransport(transport http.RoundTripper) *OrderService {
+	client := *s.client
+	client.Transport = transport
+	s.client = &client
+	return s
+}
+
 func (s *OrderService) CreateOrder(userID string, items []map[string]interface{}) (map[string]interface{}, error) {
 	url := fmt.Sprintf("%s/orders", s.baseURL)
 	
@@ -33,8 +33,7 @@ func (r *ContractRepository) SaveProviderSchema(providerName, schemaContent stri
 }
 
 func (r *ContractRepository) GetProviderSchema(providerName string) (string, error) {
-	filePath := filepath.Join(r.basePath, "providers", providerName, "openapi.yaml")
-	data, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(r.ProviderSchemaPath(providerName))
 	if err != nil {
 		return "", fmt.Errorf("failed to read schema: %w", err)
 	}
@@ -43,7 +42,7 @@ func (r *ContractRepository) GetProviderSchema(providerName string) (string, err
 }
 
 func (r *ContractRepository) GetConsumerMocks(providerName string) (map[string][]string, error) {
-	consumersPath := filepath.Join(r.basePath, "consumers")
+	consumersPath := r.ConsumersPath()
 	
 	consumersDir, err := os.ReadDir(consumersPath)
 	if err != nil {
@@ -92,4 +91,49 @@ func (r *ContractRepository) GetConsumerMocks(providerName string) (map[string][
 	}
 	
 	return result, nil
-}
\ No newline at end of file
+}
+
+// ListProviders returns the names of all providers that have a schema in the
+// repository, in alphabetical order.
+func (r *ContractRepository) ListProviders() ([]string, error) {
+	providersPath := filepath.Join(r.basePath, "providers")
+	
+	providerDirs, err := os.ReadDir(providersPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read providers directory: %w", err)
+	}
+	
+	var providers []string
+	for _, providerDir := range providerDirs {
+		if !providerDir.IsDir() {
+			continue
+		}
+		
+		if _, err := os.Stat(r.ProviderSchemaPath(providerDir.Name())); err != nil {
+			continue // Skip providers without an OpenAPI schema
+		}
+		
+		providers = append(providers, providerDir.Name())
+	}
+	
+	return providers, nil
+}
+
+// ProviderSchemaPath returns where the provider's OpenAPI schema is stored.
+func (r *ContractRepository) ProviderSchemaPath(providerName string) string {
+	return filepath.Join(r.basePath, "providers", providerName, "openapi.yaml")
+}
+
+// ProviderAsyncAPIPath returns where the provider's AsyncAPI document is stored.
+func (r *ContractRepository) ProviderAsyncAPIPath(providerName string) string {
+	return filepath.Join(r.basePath, "providers", providerName, "asyncapi.yaml")
+}
+
+// ConsumersPath returns the directory that holds every consumer's mocks.
+func (r *ContractRepository) ConsumersPath() string {
+	return filepath.Join(r.basePath, "consumers")
+}
+// ConsumerMocksPath returns the directory that holds a consumer's mocks.
+func (r *ContractRepository) ConsumerMocksPath(consumerName string) string {
+	return filepath.Join(r.ConsumersPath(), consumerName, "mocks")
+}
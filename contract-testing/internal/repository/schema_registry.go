@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// registryConfigFile holds a registry's compatibility modes.
+const registryConfigFile = "registry.yaml"
+
+// SchemaRegistry stores the versions of event payload schemas in the
+// contract repository, like a Confluent Schema Registry kept in git. Each
+// subject, usually a channel, has a directory of versions 1.json, 2.json
+// and so on. registry.yaml sets the default compatibility mode and
+// per-subject overrides:
+//
+//	compatibility: BACKWARD
+//	subjects:
+//	  order.status.changed: FULL_TRANSITIVE
+type SchemaRegistry struct {
+	path   string
+	config registryConfig
+}
+
+type registryConfig struct {
+	Compatibility string            `yaml:"compatibility,omitempty"`
+	Subjects      map[string]string `yaml:"subjects,omitempty"`
+}
+
+// SchemaVersion is one registered version of a subject's schema.
+type SchemaVersion struct {
+	Subject string
+	Version int
+	Schema  interface{}
+}
+
+// CompatibilityIssue is one way a new schema breaks a subject's
+// compatibility mode. Backward issues are data written with Version that a
+// consumer using the new schema could not read; forward issues are data
+// written with the new schema that a consumer still using Version could
+// not read.
+type CompatibilityIssue struct {
+	Version   int    // the registered version the new schema was checked against
+	Direction string // "backward" or "forward"
+	Violation schema.Violation
+}
+
+func (i CompatibilityIssue) String() string {
+	if i.Direction == "backward" {
+		return fmt.Sprintf("%s (new schema cannot read version %d data): %s", i.Violation.Path, i.Version, i.Violation.Message)
+	}
+	return fmt.Sprintf("%s (version %d cannot read new schema data): %s", i.Violation.Path, i.Version, i.Violation.Message)
+}
+
+// SchemaRegistryPath returns where the repository's schema registry is stored.
+func (r *ContractRepository) SchemaRegistryPath() string {
+	return filepath.Join(r.basePath, "registry")
+}
+
+// OpenSchemaRegistry opens the schema registry stored at path. The directory
+// need not exist yet.
+func OpenSchemaRegistry(path string) (*SchemaRegistry, error) {
+	registry := &SchemaRegistry{path: path}
+
+	data, err := os.ReadFile(filepath.Join(path, registryConfigFile))
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &registry.config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry config: %w", err)
+	}
+
+	if registry.config.Compatibility != "" {
+		if registry.config.Compatibility, err = schema.ParseCompatibility(registry.config.Compatibility); err != nil {
+			return nil, fmt.Errorf("registry config: %w", err)
+		}
+	}
+	for subject, mode := range registry.config.Subjects {
+		if registry.config.Subjects[subject], err = schema.ParseCompatibility(mode); err != nil {
+			return nil, fmt.Errorf("registry config: subject %s: %w", subject, err)
+		}
+	}
+	return registry, nil
+}
+
+// Compatibility returns the subject's compatibility mode: its override, the
+// registry's default, or BACKWARD. An empty subject returns the default.
+func (r *SchemaRegistry) Compatibility(subject string) string {
+	if mode, ok := r.config.Subjects[subject]; ok && subject != "" {
+		return mode
+	}
+	if r.config.Compatibility != "" {
+		return r.config.Compatibility
+	}
+	return schema.CompatibilityBackward
+}
+
+// SetCompatibility sets the subject's compatibility mode, or the registry's
+// default when subject is empty, and saves registry.yaml.
+func (r *SchemaRegistry) SetCompatibility(subject, mode string) error {
+	mode, err := schema.ParseCompatibility(mode)
+	if err != nil {
+		return err
+	}
+	if subject == "" {
+		r.config.Compatibility = mode
+	} else {
+		if err := checkSubject(subject); err != nil {
+			return err
+		}
+		if r.config.Subjects == nil {
+			r.config.Subjects = map[string]string{}
+		}
+		r.config.Subjects[subject] = mode
+	}
+
+	data, err := yaml.Marshal(r.config)
+	if err != nil {
+		return fmt.Errorf("failed to encode registry config: %w", err)
+	}
+	if err := os.MkdirAll(r.path, 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.path, registryConfigFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write registry config: %w", err)
+	}
+	return nil
+}
+
+// Subjects returns the names of the registered subjects in sorted order.
+func (r *SchemaRegistry) Subjects() ([]string, error) {
+	entries, err := os.ReadDir(r.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	var subjects []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subjects = append(subjects, entry.Name())
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+// Versions returns the subject's registered versions, oldest first.
+func (r *SchemaRegistry) Versions(subject string) ([]SchemaVersion, error) {
+	if err := checkSubject(subject); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(r.path, subject))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subject %s: %w", subject, err)
+	}
+
+	var versions []SchemaVersion
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || err != nil || version < 1 {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(r.path, subject, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s version %d: %w", subject, version, err)
+		}
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse %s version %d: %w", subject, version, err)
+		}
+		versions = append(versions, SchemaVersion{Subject: subject, Version: version, Schema: document})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// Check tests a new schema for the subject against its compatibility mode:
+// against the latest version, or every version for the TRANSITIVE modes.
+// It also returns the version already holding an identical schema, if any.
+func (r *SchemaRegistry) Check(subject string, newSchema interface{}) (issues []CompatibilityIssue, existing int, err error) {
+	versions, err := r.Versions(subject)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, version := range versions {
+		if reflect.DeepEqual(version.Schema, newSchema) {
+			return nil, version.Version, nil
+		}
+	}
+
+	mode := r.Compatibility(subject)
+	if !strings.HasSuffix(mode, "_TRANSITIVE") && len(versions) > 0 {
+		versions = versions[len(versions)-1:]
+	}
+	backward := strings.HasPrefix(mode, "BACKWARD") || strings.HasPrefix(mode, "FULL")
+	forward := strings.HasPrefix(mode, "FORWARD") || strings.HasPrefix(mode, "FULL")
+
+	for _, version := range versions {
+		if backward {
+			for _, violation := range schema.CheckReadable(newSchema, version.Schema, "payload") {
+				issues = append(issues, CompatibilityIssue{Version: version.Version, Direction: "backward", Violation: violation})
+			}
+		}
+		if forward {
+			for _, violation := range schema.CheckReadable(version.Schema, newSchema, "payload") {
+				issues = append(issues, CompatibilityIssue{Version: version.Version, Direction: "forward", Violation: violation})
+			}
+		}
+	}
+	return issues, 0, nil
+}
+
+// Register adds a new schema version to the subject if it satisfies the
+// subject's compatibility mode. A schema identical to a registered version
+// is not added again; its version is returned. Incompatible schemas are
+// rejected with the issues found and version 0.
+func (r *SchemaRegistry) Register(subject string, newSchema interface{}) (version int, issues []CompatibilityIssue, err error) {
+	issues, existing, err := r.Check(subject, newSchema)
+	if err != nil || existing != 0 {
+		return existing, nil, err
+	}
+	if len(issues) > 0 {
+		return 0, issues, nil
+	}
+
+	versions, err := r.Versions(subject)
+	if err != nil {
+		return 0, nil, err
+	}
+	version = 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].Version + 1
+	}
+
+	data, err := json.MarshalIndent(newSchema, "", "  ")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	dir := filepath.Join(r.path, subject)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, nil, fmt.Errorf("failed to create subject directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", version)), append(data, '\n'), 0644); err != nil {
+		return 0, nil, fmt.Errorf("failed to write schema: %w", err)
+	}
+	return version, nil, nil
+}
+
+// checkSubject rejects subject names that aren't a single directory name.
+func checkSubject(subject string) error {
+	if subject == "" || subject == "." || subject == ".." || strings.ContainsAny(subject, `/\`) {
+		return fmt.Errorf("invalid subject %q", subject)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AsyncSpec is a parsed AsyncAPI document describing the messages a provider
+// emits on its channels. Both 2.x documents, with messages under a channel's
+// publish or subscribe operation, and 3.x documents, with messages and an
+// address on the channel, are understood.
+type AsyncSpec struct {
+	spec *Spec
+}
+
+// AsyncMessage is one message a channel carries.
+type AsyncMessage struct {
+	Name    string
+	Headers interface{} // schema of the headers, or nil
+	Payload interface{} // schema of the payload, or nil
+}
+
+// LoadAsyncSpec parses the AsyncAPI document at path.
+func LoadAsyncSpec(path string) (*AsyncSpec, error) {
+	doc, err := NewParser(path).Parse()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := doc["asyncapi"]; !ok {
+		return nil, fmt.Errorf("%s is not an AsyncAPI document", path)
+	}
+	return NewAsyncSpec(doc), nil
+}
+
+// NewAsyncSpec wraps an already parsed AsyncAPI document.
+func NewAsyncSpec(doc map[string]interface{}) *AsyncSpec {
+	return &AsyncSpec{spec: NewSpec(doc)}
+}
+
+// Title returns the document's info.title.
+func (a *AsyncSpec) Title() string {
+	return a.spec.Title()
+}
+
+// Version returns the document's asyncapi version, e.g. "2.6.0" or "3.0.0".
+func (a *AsyncSpec) Version() string {
+	return fmt.Sprintf("%v", a.spec.doc["asyncapi"])
+}
+
+// Spec returns the document as a Spec, for resolving references and
+// validating values against its schemas.
+func (a *AsyncSpec) Spec() *Spec {
+	return a.spec
+}
+
+// Channels returns the names of the declared channels in sorted order. For
+// 3.x documents that give a channel an address, the address is its name.
+func (a *AsyncSpec) Channels() []string {
+	var names []string
+	for key, channel := range stringKeys(asMap(a.spec.doc["channels"])) {
+		names = append(names, channelName(key, asMap(a.spec.Resolve(channel))))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Messages returns the messages declared on a channel; ok is false if the
+// channel isn't declared.
+func (a *AsyncSpec) Messages(channel string) (messages []AsyncMessage, ok bool) {
+	for key, node := range stringKeys(asMap(a.spec.doc["channels"])) {
+		item := asMap(a.spec.Resolve(node))
+		if channelName(key, item) != channel {
+			continue
+		}
+
+		var refs []interface{}
+		for _, operation := range []string{"subscribe", "publish"} {
+			if message, declared := asMap(item[operation])["message"]; declared {
+				refs = append(refs, message)
+			}
+		}
+		for _, name := range sortedKeys(stringKeys(asMap(item["messages"]))) {
+			refs = append(refs, asMap(item["messages"])[name])
+		}
+
+		for _, ref := range refs {
+			messages = append(messages, a.messages(ref)...)
+		}
+		return messages, true
+	}
+	return nil, false
+}
+
+// AsyncOperation is one thing the application does on a channel. In 2.x
+// documents Action is "publish" or "subscribe"; in 3.x it is "send" or
+// "receive".
+type AsyncOperation struct {
+	ID       string
+	Action   string
+	Channel  string
+	Summary  string
+	Messages []AsyncMessage
+}
+
+// Operations returns the document's operations ordered by channel and ID.
+// 2.x operations without an operationId get "<action> <channel>" as ID.
+func (a *AsyncSpec) Operations() []AsyncOperation {
+	var operations []AsyncOperation
+
+	channels := stringKeys(asMap(a.spec.doc["channels"]))
+	for _, key := range sortedKeys(channels) {
+		item := asMap(a.spec.Resolve(channels[key]))
+		for _, action := range []string{"publish", "subscribe"} {
+			node := asMap(a.spec.Resolve(item[action]))
+			if node == nil {
+				continue
+			}
+			channel := channelName(key, item)
+			id := asString(node["operationId"])
+			if id == "" {
+				id = action + " " + channel
+			}
+			operations = append(operations, AsyncOperation{
+				ID:       id,
+				Action:   action,
+				Channel:  channel,
+				Summary:  asString(node["summary"]),
+				Messages: a.messages(node["message"]),
+			})
+		}
+	}
+
+	declared := stringKeys(asMap(a.spec.doc["operations"]))
+	for _, id := range sortedKeys(declared) {
+		node := asMap(a.spec.Resolve(declared[id]))
+		channelRef := asString(asMap(node["channel"])["$ref"])
+		channelKey := channelRef[strings.LastIndex(channelRef, "/")+1:]
+		channelKey = strings.ReplaceAll(strings.ReplaceAll(channelKey, "~1", "/"), "~0", "~")
+
+		operation := AsyncOperation{
+			ID:      id,
+			Action:  asString(node["action"]),
+			Channel: channelName(channelKey, asMap(a.spec.Resolve(node["channel"]))),
+			Summary: asString(node["summary"]),
+		}
+		if refs := asSlice(node["messages"]); refs != nil {
+			for _, ref := range refs {
+				operation.Messages = append(operation.Messages, a.messages(ref)...)
+			}
+		} else {
+			operation.Messages, _ = a.Messages(operation.Channel)
+		}
+		operations = append(operations, operation)
+	}
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		if operations[i].Channel != operations[j].Channel {
+			return operations[i].Channel < operations[j].Channel
+		}
+		return operations[i].ID < operations[j].ID
+	})
+	return operations
+}
+
+// Message returns a message declared under components.messages.
+func (a *AsyncSpec) Message(name string) (AsyncMessage, bool) {
+	node, ok := stringKeys(asMap(asMap(a.spec.doc["components"])["messages"]))[name]
+	if !ok {
+		return AsyncMessage{}, false
+	}
+	messages := a.messages(node)
+	if len(messages) != 1 {
+		return AsyncMessage{}, false
+	}
+	if messages[0].Name == "" {
+		messages[0].Name = name
+	}
+	return messages[0], true
+}
+
+// Schema returns a schema declared under components.schemas.
+func (a *AsyncSpec) Schema(name string) (interface{}, bool) {
+	schema, ok := stringKeys(asMap(asMap(a.spec.doc["components"])["schemas"]))[name]
+	return schema, ok
+}
+
+// messages expands a message node, which may be a oneOf of several.
+func (a *AsyncSpec) messages(node interface{}) []AsyncMessage {
+	message := asMap(a.spec.Resolve(node))
+	if options := asSlice(message["oneOf"]); options != nil {
+		var messages []AsyncMessage
+		for _, option := range options {
+			messages = append(messages, a.messages(option)...)
+		}
+		return messages
+	}
+
+	name := asString(message["name"])
+	if name == "" {
+		if ref := asString(asMap(node)["$ref"]); ref != "" {
+			name = ref[strings.LastIndex(ref, "/")+1:]
+		}
+	}
+
+	payload := message["payload"]
+	// 3.x allows a multi-format schema object around the payload schema.
+	if wrapped := asMap(a.spec.Resolve(payload)); wrapped != nil && wrapped["schemaFormat"] != nil {
+		payload = wrapped["schema"]
+	}
+
+	return []AsyncMessage{{Name: name, Headers: message["headers"], Payload: payload}}
+}
+
+// ValidateMessage checks a message's headers and JSON payload against the
+// messages declared on channel. A message is valid if it matches any of
+// them; otherwise the violations of the closest one are returned. Violation
+// paths are rooted at "headers" or "payload". The error reports an
+// undeclared channel.
+func (a *AsyncSpec) ValidateMessage(channel string, headers map[string]string, payload interface{}) ([]Violation, error) {
+	_, violations, err := a.ClosestMessage(channel, headers, payload)
+	return violations, err
+}
+
+// ClosestMessage returns the message declared on channel that a message
+// with these headers and payload matches or, if it matches none, the one it
+// breaks least, with its violations. The message is zero if the channel
+// declares none. The error reports an undeclared channel.
+func (a *AsyncSpec) ClosestMessage(channel string, headers map[string]string, payload interface{}) (AsyncMessage, []Violation, error) {
+	messages, ok := a.Messages(channel)
+	if !ok {
+		return AsyncMessage{}, nil, fmt.Errorf("channel %s is not declared, expected one of %s", channel, strings.Join(a.Channels(), ", "))
+	}
+	if len(messages) == 0 {
+		return AsyncMessage{}, nil, nil
+	}
+
+	var closest AsyncMessage
+	var closestViolations []Violation
+	for i, message := range messages {
+		var violations []Violation
+		if message.Headers != nil {
+			violations = append(violations, a.spec.Validate(message.Headers, a.headerValues(message.Headers, headers), "headers")...)
+		}
+		if message.Payload != nil {
+			violations = append(violations, a.spec.Validate(message.Payload, payload, "payload")...)
+		}
+		if len(violations) == 0 {
+			return message, nil, nil
+		}
+		if i == 0 || len(violations) < len(closestViolations) {
+			closest, closestViolations = message, violations
+		}
+	}
+	return closest, closestViolations, nil
+}
+
+// headerValues turns header strings into the JSON values the headers schema
+// declares, so that a "2" header satisfies an integer property.
+func (a *AsyncSpec) headerValues(schema interface{}, headers map[string]string) map[string]interface{} {
+	properties := asMap(asMap(a.spec.Resolve(schema))["properties"])
+	values := make(map[string]interface{}, len(headers))
+	for name, value := range headers {
+		values[name] = value
+		property := asMap(a.spec.Resolve(properties[name]))
+		if property == nil || typeAllows(property, "string") {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			values[name] = decoded
+		}
+	}
+	return values
+}
+
+func channelName(key string, channel map[interface{}]interface{}) string {
+	if address := asString(channel["address"]); address != "" {
+		return address
+	}
+	return key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,345 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AsyncAPI versions the AsyncGenerator can write.
+const (
+	AsyncAPIVersion2 = "2.6.0"
+	AsyncAPIVersion3 = "3.0.0"
+)
+
+// Event declares a message a provider publishes on a channel. Payload and
+// Headers are values of the Go types that are serialized; their JSON schemas
+// are reflected from the types' json tags. An `enum:"a,b"` tag restricts a
+// string field and a `format:"uuid"` tag sets its format.
+type Event struct {
+	Channel string
+	Name    string      // message name; defaults to the payload type's name
+	Summary string
+	Payload interface{}
+	Headers interface{} // optional struct describing the message headers
+}
+
+var (
+	eventsMu sync.Mutex
+	events   = map[string][]Event{}
+)
+
+// RegisterEvent records that provider publishes event. Providers register
+// their events, typically from an init function, so that AsyncAPI documents
+// can be generated from the Go types.
+func RegisterEvent(provider string, event Event) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	events[provider] = append(events[provider], event)
+}
+
+// RegisteredEvents returns the events registered for provider, ordered by
+// channel and name.
+func RegisteredEvents(provider string) []Event {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	registered := append([]Event{}, events[provider]...)
+	sort.SliceStable(registered, func(i, j int) bool {
+		if registered[i].Channel != registered[j].Channel {
+			return registered[i].Channel < registered[j].Channel
+		}
+		return eventName(registered[i]) < eventName(registered[j])
+	})
+	return registered
+}
+
+// AsyncGenerator builds an AsyncAPI document from a provider's events.
+type AsyncGenerator struct {
+	providerName string
+	version      string
+	events       []Event
+}
+
+// NewAsyncGenerator creates a generator for providerName's events, writing
+// AsyncAPI 2.6 documents unless WithVersion says otherwise.
+func NewAsyncGenerator(providerName string, events []Event) *AsyncGenerator {
+	return &AsyncGenerator{
+		providerName: providerName,
+		version:      AsyncAPIVersion2,
+		events:       events,
+	}
+}
+
+// WithVersion selects the AsyncAPI version written: AsyncAPIVersion2 or
+// AsyncAPIVersion3.
+func (g *AsyncGenerator) WithVersion(version string) *AsyncGenerator {
+	g.version = version
+	return g
+}
+
+// Document builds the AsyncAPI document. Payload and header types become
+// components.schemas entries named after the Go types, and every event
+// becomes a components.messages entry used by its channel.
+func (g *AsyncGenerator) Document() (map[string]interface{}, error) {
+	if len(g.events) == 0 {
+		return nil, fmt.Errorf("no events registered for %s", g.providerName)
+	}
+	if g.version != AsyncAPIVersion2 && g.version != AsyncAPIVersion3 {
+		return nil, fmt.Errorf("unsupported AsyncAPI version %q (use %s or %s)", g.version, AsyncAPIVersion2, AsyncAPIVersion3)
+	}
+
+	reflector := &schemaReflector{schemas: map[string]interface{}{}}
+	messages := map[string]interface{}{}
+	channels := map[string]interface{}{}
+	operations := map[string]interface{}{}
+
+	for _, event := range g.events {
+		if event.Channel == "" {
+			return nil, fmt.Errorf("event %s has no channel", eventName(event))
+		}
+		if event.Payload == nil {
+			return nil, fmt.Errorf("event on %s has no payload type", event.Channel)
+		}
+
+		name := eventName(event)
+		if _, taken := messages[name]; taken {
+			return nil, fmt.Errorf("more than one event is named %s", name)
+		}
+
+		message := map[string]interface{}{
+			"name":        name,
+			"contentType": "application/json",
+			"payload":     reflector.schema(reflect.TypeOf(event.Payload)),
+		}
+		if event.Summary != "" {
+			message["summary"] = event.Summary
+		}
+		if event.Headers != nil {
+			message["headers"] = reflector.schema(reflect.TypeOf(event.Headers))
+		}
+		messages[name] = message
+		messageRef := map[string]interface{}{"$ref": "#/components/messages/" + name}
+
+		if g.version == AsyncAPIVersion2 {
+			channel, _ := channels[event.Channel].(map[string]interface{})
+			if channel == nil {
+				channel = map[string]interface{}{"subscribe": map[string]interface{}{}}
+				channels[event.Channel] = channel
+			}
+			subscribe := channel["subscribe"].(map[string]interface{})
+			if existing, ok := subscribe["message"].(map[string]interface{}); ok {
+				options, _ := existing["oneOf"].([]interface{})
+				if options == nil {
+					options = []interface{}{existing}
+				}
+				subscribe["message"] = map[string]interface{}{"oneOf": append(options, messageRef)}
+			} else {
+				subscribe["message"] = messageRef
+			}
+			if event.Summary != "" && subscribe["summary"] == nil {
+				subscribe["summary"] = event.Summary
+			}
+			continue
+		}
+
+		channelRef := "#/channels/" + escapePointer(event.Channel)
+		channel, _ := channels[event.Channel].(map[string]interface{})
+		if channel == nil {
+			channel = map[string]interface{}{
+				"address":  event.Channel,
+				"messages": map[string]interface{}{},
+			}
+			channels[event.Channel] = channel
+		}
+		channel["messages"].(map[string]interface{})[name] = messageRef
+
+		operation := map[string]interface{}{
+			"action":   "send",
+			"channel":  map[string]interface{}{"$ref": channelRef},
+			"messages": []interface{}{map[string]interface{}{"$ref": channelRef + "/messages/" + name}},
+		}
+		if event.Summary != "" {
+			operation["summary"] = event.Summary
+		}
+		operations["publish"+name] = operation
+	}
+
+	doc := map[string]interface{}{
+		"asyncapi": g.version,
+		"info": map[string]interface{}{
+			"title":       fmt.Sprintf("%s events", g.providerName),
+			"description": fmt.Sprintf("Events published by %s", g.providerName),
+			"version":     "1.0.0",
+		},
+		"channels": channels,
+		"components": map[string]interface{}{
+			"messages": messages,
+			"schemas":  reflector.schemas,
+		},
+	}
+	if g.version == AsyncAPIVersion3 {
+		doc["operations"] = operations
+	}
+	return doc, nil
+}
+
+// GenerateSchema writes the AsyncAPI document to outputPath as YAML.
+func (g *AsyncGenerator) GenerateSchema(outputPath string) error {
+	doc, err := g.Document()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	yamlData, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal AsyncAPI document: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, yamlData, 0644); err != nil {
+		return fmt.Errorf("failed to write AsyncAPI document to file: %w", err)
+	}
+
+	return nil
+}
+
+func eventName(event Event) string {
+	if event.Name != "" {
+		return event.Name
+	}
+	t := reflect.TypeOf(event.Payload)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// schemaReflector derives JSON schemas from Go types the way encoding/json
+// serializes them. Named struct types are added to schemas once and
+// referenced from everywhere they are used.
+type schemaReflector struct {
+	schemas map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (r *schemaReflector) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, done := r.schemas[t.Name()]; !done {
+			r.schemas[t.Name()] = map[string]interface{}{} // placeholder for recursive types
+			r.schemas[t.Name()] = r.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return r.object(t)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": r.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.schema(t.Elem())}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object reflects a struct's exported fields. Fields marked omitempty or of
+// pointer type are optional; embedded structs contribute their fields.
+func (r *schemaReflector) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.object(embedded)
+				for key, value := range inner["properties"].(map[string]interface{}) {
+					properties[key] = value
+				}
+				if names, ok := inner["required"].([]string); ok {
+					required = append(required, names...)
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := r.schema(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		if format := field.Tag.Get("format"); format != "" {
+			property["format"] = format
+		}
+		properties[name] = property
+
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	object := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		object["required"] = required
+	}
+	return object
+}
@@ -0,0 +1,353 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compatibility modes of a schema registry subject. They follow Confluent
+// Schema Registry: BACKWARD means consumers using the new schema can read
+// data written with the previous one, FORWARD means consumers still using
+// the previous schema can read data written with the new one, and FULL
+// means both. The TRANSITIVE variants check every earlier version instead
+// of only the latest, and NONE checks nothing.
+const (
+	CompatibilityNone               = "NONE"
+	CompatibilityBackward           = "BACKWARD"
+	CompatibilityBackwardTransitive = "BACKWARD_TRANSITIVE"
+	CompatibilityForward            = "FORWARD"
+	CompatibilityForwardTransitive  = "FORWARD_TRANSITIVE"
+	CompatibilityFull               = "FULL"
+	CompatibilityFullTransitive     = "FULL_TRANSITIVE"
+)
+
+// CompatibilityModes lists the valid compatibility modes.
+var CompatibilityModes = []string{
+	CompatibilityBackward,
+	CompatibilityBackwardTransitive,
+	CompatibilityForward,
+	CompatibilityForwardTransitive,
+	CompatibilityFull,
+	CompatibilityFullTransitive,
+	CompatibilityNone,
+}
+
+// ParseCompatibility returns the compatibility mode named by mode, which is
+// case-insensitive.
+func ParseCompatibility(mode string) (string, error) {
+	upper := strings.ToUpper(strings.TrimSpace(mode))
+	for _, known := range CompatibilityModes {
+		if upper == known {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unknown compatibility mode %q (use %s)", mode, strings.Join(CompatibilityModes, ", "))
+}
+
+// CheckReadable reports every way data written with the writer schema could
+// be rejected by a reader using the reader schema: types the reader doesn't
+// accept, fields it requires that the writer may leave out, enum values it
+// doesn't know, undeclared fields it forbids and constraints it tightens.
+// Both schemas are standalone JSON schemas; $refs are resolved within each.
+// Paths are rooted at path, with "[]" for array items.
+func CheckReadable(reader, writer interface{}, path string) []Violation {
+	c := &compatChecker{
+		reader: NewSpec(documentOf(reader)),
+		writer: NewSpec(documentOf(writer)),
+	}
+	return c.check(reader, writer, path, 0)
+}
+
+// documentOf returns a schema as a document its $refs resolve against.
+func documentOf(schema interface{}) map[string]interface{} {
+	return stringKeys(asMap(schema))
+}
+
+type compatChecker struct {
+	reader, writer *Spec
+}
+
+func (c *compatChecker) check(reader, writer interface{}, path string, depth int) []Violation {
+	if depth > 64 {
+		return nil
+	}
+	r := mergeAllOf(c.reader, asMap(c.reader.Resolve(reader)), depth)
+	w := mergeAllOf(c.writer, asMap(c.writer.Resolve(writer)), depth)
+	if len(r) == 0 {
+		return nil // The reader accepts anything
+	}
+
+	// Every branch the writer may pick must be readable by some branch of
+	// the reader.
+	readerBranches, writerBranches := branches(r), branches(w)
+	if len(readerBranches) > 1 || len(writerBranches) > 1 {
+		var violations []Violation
+		for _, wb := range writerBranches {
+			var best []Violation
+			for i, rb := range readerBranches {
+				found := c.check(rb, wb, path, depth+1)
+				if i == 0 || len(found) < len(best) {
+					best = found
+				}
+				if len(best) == 0 {
+					break
+				}
+			}
+			violations = append(violations, best...)
+		}
+		return violations
+	}
+
+	var violations []Violation
+	add := func(rule, fieldPath, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: fieldPath, Message: fmt.Sprintf(format, args...)})
+	}
+
+	readerTypes, writerTypes := schemaTypes(r), schemaTypes(w)
+	if readerTypes != nil {
+		if writerTypes == nil {
+			add("type", path, "writer's type is unconstrained, reader only accepts %s", describeTypes(r))
+			return violations
+		}
+		for _, name := range writerTypes {
+			if !containsString(readerTypes, name) && !(name == "integer" && containsString(readerTypes, "number")) {
+				add("type", path, "writer may send %s, reader only accepts %s", name, describeTypes(r))
+			}
+		}
+		if len(violations) > 0 {
+			return violations
+		}
+	}
+
+	if readerEnum, ok := enumValues(r); ok {
+		writerEnum, ok := enumValues(w)
+		if !ok {
+			add("enum", path, "writer's values are unconstrained, reader only accepts %s", formatValues(readerEnum))
+		}
+		for _, value := range writerEnum {
+			if !containsValue(readerEnum, value) {
+				add("enum", path, "writer may send %s, reader does not accept it", formatValue(value))
+			}
+		}
+	}
+
+	for _, keyword := range []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"} {
+		if min, ok := asFloat(r[keyword]); ok {
+			if writerMin, ok := asFloat(w[keyword]); !ok || writerMin < min {
+				add(keyword, path, "reader requires %s %v, writer %s", keyword, min, describeBound(keyword, w[keyword]))
+			}
+		}
+	}
+	for _, keyword := range []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"} {
+		if max, ok := asFloat(r[keyword]); ok {
+			if writerMax, ok := asFloat(w[keyword]); !ok || writerMax > max {
+				add(keyword, path, "reader requires %s %v, writer %s", keyword, max, describeBound(keyword, w[keyword]))
+			}
+		}
+	}
+	for _, keyword := range []string{"pattern", "format"} {
+		if value := asString(r[keyword]); value != "" && asString(w[keyword]) != value {
+			add(keyword, path, "reader requires %s %s, writer does not", keyword, value)
+		}
+	}
+
+	if _, ok := r["items"]; ok {
+		violations = append(violations, c.check(r["items"], w["items"], path+"[]", depth+1)...)
+	}
+	if _, ok := r["properties"]; ok || r["additionalProperties"] != nil || r["required"] != nil {
+		violations = append(violations, c.checkObject(r, w, path, depth)...)
+	}
+
+	return violations
+}
+
+func (c *compatChecker) checkObject(r, w map[interface{}]interface{}, path string, depth int) []Violation {
+	var violations []Violation
+	add := func(rule, fieldPath, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: fieldPath, Message: fmt.Sprintf(format, args...)})
+	}
+
+	readerProperties := stringKeys(asMap(r["properties"]))
+	writerProperties := stringKeys(asMap(w["properties"]))
+	writerRequired := map[string]bool{}
+	for _, name := range asSlice(w["required"]) {
+		writerRequired[asString(name)] = true
+	}
+
+	for _, required := range asSlice(r["required"]) {
+		name := asString(required)
+		switch {
+		case writerRequired[name]:
+		case writerProperties[name] != nil:
+			add("required", joinPath(path, name), "reader requires the field, writer may leave it out")
+		default:
+			add("required", joinPath(path, name), "reader requires the field, writer does not declare it")
+		}
+	}
+
+	for _, name := range sortedKeys(readerProperties) {
+		if property, ok := writerProperties[name]; ok {
+			violations = append(violations, c.check(readerProperties[name], property, joinPath(path, name), depth+1)...)
+		}
+	}
+
+	additional, hasAdditional := r["additionalProperties"]
+	closed := hasAdditional && additional == false
+	for _, name := range sortedKeys(writerProperties) {
+		if _, ok := readerProperties[name]; ok || !hasAdditional {
+			continue
+		}
+		if closed {
+			add("additionalProperties", joinPath(path, name), "writer may send the field, reader does not allow undeclared fields")
+			continue
+		}
+		if _, ok := additional.(bool); !ok {
+			violations = append(violations, c.check(additional, writerProperties[name], joinPath(path, name), depth+1)...)
+		}
+	}
+	if closed && w["additionalProperties"] != false {
+		add("additionalProperties", path, "writer allows undeclared fields, reader does not")
+	}
+
+	return violations
+}
+
+// mergeAllOf folds a schema's allOf parts into one schema: properties and
+// required fields are combined, and other keywords are taken from the first
+// part that declares them.
+func mergeAllOf(s *Spec, node map[interface{}]interface{}, depth int) map[interface{}]interface{} {
+	parts := asSlice(node["allOf"])
+	if len(parts) == 0 || depth > 64 {
+		return node
+	}
+
+	merged := map[interface{}]interface{}{}
+	properties := map[interface{}]interface{}{}
+	var required []interface{}
+	for i, part := range append([]interface{}{node}, parts...) {
+		partNode := node
+		if i > 0 {
+			partNode = mergeAllOf(s, asMap(s.Resolve(part)), depth+1)
+		}
+		for key, value := range partNode {
+			switch key {
+			case "allOf":
+			case "properties":
+				for name, property := range asMap(value) {
+					if _, ok := properties[name]; !ok {
+						properties[name] = property
+					}
+				}
+			case "required":
+				for _, name := range asSlice(value) {
+					if !containsValue(required, name) {
+						required = append(required, name)
+					}
+				}
+			default:
+				if _, ok := merged[key]; !ok {
+					merged[key] = value
+				}
+			}
+		}
+	}
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+	return merged
+}
+
+// branches returns the alternatives of a oneOf or anyOf schema, or the
+// schema itself.
+func branches(node map[interface{}]interface{}) []interface{} {
+	if options := asSlice(node["oneOf"]); len(options) > 0 {
+		return options
+	}
+	if options := asSlice(node["anyOf"]); len(options) > 0 {
+		return options
+	}
+	return []interface{}{node}
+}
+
+// schemaTypes returns the types a schema allows, including "null" for
+// nullable schemas, or nil if it doesn't restrict the type.
+func schemaTypes(node map[interface{}]interface{}) []string {
+	var types []string
+	switch t := node["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			types = append(types, asString(item))
+		}
+	default:
+		return nil
+	}
+	if asBool(node["nullable"]) && !containsString(types, "null") {
+		types = append(types, "null")
+	}
+	return types
+}
+
+// enumValues returns the values an enum or const schema allows.
+func enumValues(node map[interface{}]interface{}) ([]interface{}, bool) {
+	if enum := asSlice(node["enum"]); enum != nil {
+		return enum, true
+	}
+	if constant, ok := node["const"]; ok {
+		return []interface{}{constant}, true
+	}
+	return nil, false
+}
+
+func describeBound(keyword string, value interface{}) string {
+	if value == nil {
+		return "has no " + keyword
+	}
+	return fmt.Sprintf("allows %s %v", keyword, value)
+}
+
+// Inline returns a copy of schema with every local $ref replaced by its
+// target, as a standalone JSON schema. References that recurse are replaced
+// by an empty schema.
+func (s *Spec) Inline(schema interface{}) interface{} {
+	return s.inline(schema, map[string]bool{}, 0)
+}
+
+func (s *Spec) inline(node interface{}, resolving map[string]bool, depth int) interface{} {
+	if depth > 64 {
+		return map[string]interface{}{}
+	}
+	switch v := node.(type) {
+	case map[interface{}]interface{}, map[string]interface{}:
+		m := stringKeys(asMap(v))
+		if ref, ok := m["$ref"].(string); ok {
+			target, found := s.lookupRef(ref)
+			if !found {
+				return toJSON(m)
+			}
+			if resolving[ref] {
+				return map[string]interface{}{}
+			}
+			resolving[ref] = true
+			defer delete(resolving, ref)
+			return s.inline(target, resolving, depth+1)
+		}
+		result := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			result[key] = s.inline(value, resolving, depth+1)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = s.inline(item, resolving, depth+1)
+		}
+		return result
+	default:
+		return toJSON(v)
+	}
+}
@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Example synthesizes a value that satisfies a schema node. Declared example,
+// default, const and enum values are preferred; otherwise a value is built
+// from the schema's type, format and bounds. The result only uses JSON types.
+func (s *Spec) Example(schema interface{}) interface{} {
+	return s.example(schema, 0)
+}
+
+// MediaExample returns the example declared on a media type object, taking
+// the named entry of examples if name is set and the first one otherwise.
+// The second result is false when the media type declares no example.
+func (s *Spec) MediaExample(media interface{}, name string) (interface{}, bool) {
+	node := asMap(s.Resolve(media))
+
+	if examples := asMap(node["examples"]); len(examples) > 0 {
+		if name != "" {
+			if example, ok := examples[name]; ok {
+				return toJSON(asMap(s.Resolve(example))["value"]), true
+			}
+		}
+
+		keys := make([]string, 0, len(examples))
+		for key := range examples {
+			keys = append(keys, fmt.Sprintf("%v", key))
+		}
+		sort.Strings(keys)
+		return toJSON(asMap(s.Resolve(examples[keys[0]]))["value"]), true
+	}
+
+	if example, ok := node["example"]; ok {
+		return toJSON(example), true
+	}
+
+	return nil, false
+}
+
+func (s *Spec) example(schema interface{}, depth int) interface{} {
+	node := asMap(s.Resolve(schema))
+	if node == nil || depth > 16 {
+		return nil
+	}
+
+	for _, key := range []string{"example", "default", "const"} {
+		if value, ok := node[key]; ok {
+			return toJSON(value)
+		}
+	}
+	if examples := asSlice(node["examples"]); len(examples) > 0 {
+		return toJSON(examples[0])
+	}
+	if enum := asSlice(node["enum"]); len(enum) > 0 {
+		return toJSON(enum[0])
+	}
+
+	if allOf := asSlice(node["allOf"]); len(allOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, sub := range allOf {
+			if object, ok := s.example(sub, depth+1).(map[string]interface{}); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		if object, ok := s.exampleObject(node, depth).(map[string]interface{}); ok {
+			for key, value := range object {
+				merged[key] = value
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options := asSlice(node[key]); len(options) > 0 {
+			return s.example(options[0], depth+1)
+		}
+	}
+
+	switch primaryType(node) {
+	case "string":
+		return exampleString(node)
+	case "integer":
+		return exampleNumber(node, true)
+	case "number":
+		return exampleNumber(node, false)
+	case "boolean":
+		return true
+	case "array":
+		count := 1
+		if min, ok := asFloat(node["minItems"]); ok && int(min) > count {
+			count = int(min)
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = s.example(node["items"], depth+1)
+		}
+		return items
+	case "null":
+		return nil
+	default:
+		return s.exampleObject(node, depth)
+	}
+}
+
+func (s *Spec) exampleObject(node map[interface{}]interface{}, depth int) interface{} {
+	object := map[string]interface{}{}
+	for name, property := range asMap(node["properties"]) {
+		object[fmt.Sprintf("%v", name)] = s.example(property, depth+1)
+	}
+	return object
+}
+
+func exampleString(node map[interface{}]interface{}) interface{} {
+	var value string
+	switch asString(node["format"]) {
+	case "date-time":
+		value = "2025-01-01T00:00:00Z"
+	case "date":
+		value = "2025-01-01"
+	case "uuid":
+		value = "00000000-0000-4000-8000-000000000000"
+	case "email":
+		value = "user@example.com"
+	case "uri", "url":
+		value = "https://example.com"
+	default:
+		value = "string"
+	}
+
+	if min, ok := asFloat(node["minLength"]); ok && len(value) < int(min) {
+		value += strings.Repeat("x", int(min)-len(value))
+	}
+	if max, ok := asFloat(node["maxLength"]); ok && len(value) > int(max) {
+		value = value[:int(max)]
+	}
+	return value
+}
+
+func exampleNumber(node map[interface{}]interface{}, integer bool) interface{} {
+	value := 1.0
+	if min, ok := asFloat(node["minimum"]); ok {
+		value = min
+		if asBool(node["exclusiveMinimum"]) {
+			value++
+		}
+	} else if min, ok := asFloat(node["exclusiveMinimum"]); ok {
+		value = min + 1
+	} else if max, ok := asFloat(node["maximum"]); ok && max < value {
+		value = max
+	}
+
+	if integer {
+		return float64(int64(value))
+	}
+	return value
+}
+
+// toJSON converts YAML-decoded values into their encoding/json equivalents.
+func toJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = toJSON(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = toJSON(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = toJSON(item)
+		}
+		return result
+	default:
+		if n, ok := asFloat(v); ok {
+			return n
+		}
+		return v
+	}
+}
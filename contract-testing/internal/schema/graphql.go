@@ -0,0 +1,908 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of GraphQL named types.
+const (
+	GraphQLScalar      = "SCALAR"
+	GraphQLObject      = "OBJECT"
+	GraphQLInterface   = "INTERFACE"
+	GraphQLUnion       = "UNION"
+	GraphQLEnum        = "ENUM"
+	GraphQLInputObject = "INPUT_OBJECT"
+)
+
+// GraphQLSchema is a provider's GraphQL schema, parsed from SDL. Types may be
+// spread over several files and extended with "extend"; the built-in scalars
+// and the @skip, @include, @deprecated and @specifiedBy directives are always
+// declared.
+type GraphQLSchema struct {
+	types        map[string]*GraphQLTypeDef
+	directives   map[string]*GraphQLDirectiveDef
+	query        string
+	mutation     string
+	subscription string
+}
+
+// GraphQLTypeDef is a named type. Fields holds the fields of objects and
+// interfaces and the input fields of input objects.
+type GraphQLTypeDef struct {
+	Name          string
+	Kind          string
+	Fields        []*GraphQLField
+	Interfaces    []string
+	PossibleTypes []string // members of a union
+	EnumValues    []*GraphQLEnumValue
+}
+
+// GraphQLField is a field of an object or interface, or an input field.
+type GraphQLField struct {
+	Name              string
+	Type              *GraphQLTypeRef
+	Args              []*GraphQLField // arguments; their Default is set if they have one
+	Default           *gqlValue
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// GraphQLEnumValue is one value of an enum.
+type GraphQLEnumValue struct {
+	Name              string
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// GraphQLDirectiveDef is a directive declaration.
+type GraphQLDirectiveDef struct {
+	Name string
+	Args []*GraphQLField
+}
+
+// GraphQLTypeRef is a reference to a type: a named type, or a list of
+// OfType, either of which may be non-null.
+type GraphQLTypeRef struct {
+	Name    string
+	OfType  *GraphQLTypeRef
+	NonNull bool
+}
+
+func (t *GraphQLTypeRef) String() string {
+	s := t.Name
+	if t.OfType != nil {
+		s = "[" + t.OfType.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// NamedType returns the name of the type at the bottom of any lists.
+func (t *GraphQLTypeRef) NamedType() string {
+	for t.OfType != nil {
+		t = t.OfType
+	}
+	return t.Name
+}
+
+// Field returns a field of the type by name.
+func (t *GraphQLTypeDef) Field(name string) (*GraphQLField, bool) {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// EnumValue returns a value of an enum by name.
+func (t *GraphQLTypeDef) EnumValue(name string) (*GraphQLEnumValue, bool) {
+	for _, value := range t.EnumValues {
+		if value.Name == name {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Arg returns an argument of the field by name.
+func (f *GraphQLField) Arg(name string) (*GraphQLField, bool) {
+	for _, arg := range f.Args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return nil, false
+}
+
+// LoadGraphQLSchema parses the SDL files as one schema.
+func LoadGraphQLSchema(paths ...string) (*GraphQLSchema, error) {
+	var sources []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GraphQL schema: %w", err)
+		}
+		sources = append(sources, string(data))
+	}
+
+	schema, err := ParseGraphQLSchema(sources...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL schema %s: %w", strings.Join(paths, ", "), err)
+	}
+	return schema, nil
+}
+
+// ParseGraphQLSchema parses SDL sources as one schema. Without a schema
+// definition the root types are named Query, Mutation and Subscription.
+func ParseGraphQLSchema(sources ...string) (*GraphQLSchema, error) {
+	s := &GraphQLSchema{
+		types:      map[string]*GraphQLTypeDef{},
+		directives: map[string]*GraphQLDirectiveDef{},
+	}
+	for _, name := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+		s.types[name] = &GraphQLTypeDef{Name: name, Kind: GraphQLScalar}
+	}
+	ifArg := []*GraphQLField{{Name: "if", Type: &GraphQLTypeRef{Name: "Boolean", NonNull: true}}}
+	s.directives["skip"] = &GraphQLDirectiveDef{Name: "skip", Args: ifArg}
+	s.directives["include"] = &GraphQLDirectiveDef{Name: "include", Args: ifArg}
+	s.directives["deprecated"] = &GraphQLDirectiveDef{Name: "deprecated", Args: []*GraphQLField{{Name: "reason", Type: &GraphQLTypeRef{Name: "String"}}}}
+	s.directives["specifiedBy"] = &GraphQLDirectiveDef{Name: "specifiedBy", Args: []*GraphQLField{{Name: "url", Type: &GraphQLTypeRef{Name: "String", NonNull: true}}}}
+
+	var extensions []*GraphQLTypeDef
+	for _, source := range sources {
+		tokens, err := lexGraphQL(source)
+		if err != nil {
+			return nil, err
+		}
+		p := &gqlParser{tokens: tokens}
+		if err := p.parseSchema(s, &extensions); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, extension := range extensions {
+		base, ok := s.types[extension.Name]
+		if !ok {
+			return nil, fmt.Errorf("extend type %s: type is not declared", extension.Name)
+		}
+		base.Fields = append(base.Fields, extension.Fields...)
+		base.Interfaces = append(base.Interfaces, extension.Interfaces...)
+		base.PossibleTypes = append(base.PossibleTypes, extension.PossibleTypes...)
+		base.EnumValues = append(base.EnumValues, extension.EnumValues...)
+	}
+
+	for name, root := range map[string]*string{"Query": &s.query, "Mutation": &s.mutation, "Subscription": &s.subscription} {
+		if *root == "" {
+			if _, ok := s.types[name]; ok {
+				*root = name
+			}
+		}
+	}
+	if s.query == "" {
+		return nil, fmt.Errorf("schema has no query type")
+	}
+
+	return s, s.check()
+}
+
+// check verifies that every type a declaration refers to exists and has a
+// kind that is allowed where it is used.
+func (s *GraphQLSchema) check() error {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := s.types[name]
+		for _, field := range t.Fields {
+			ref, ok := s.types[field.Type.NamedType()]
+			if !ok {
+				return fmt.Errorf("%s.%s: unknown type %s", name, field.Name, field.Type.NamedType())
+			}
+			input := ref.Kind == GraphQLScalar || ref.Kind == GraphQLEnum || ref.Kind == GraphQLInputObject
+			if t.Kind == GraphQLInputObject && !input {
+				return fmt.Errorf("%s.%s: %s is not an input type", name, field.Name, ref.Name)
+			}
+			if t.Kind != GraphQLInputObject && ref.Kind == GraphQLInputObject {
+				return fmt.Errorf("%s.%s: %s is an input type", name, field.Name, ref.Name)
+			}
+			for _, arg := range field.Args {
+				argType, ok := s.types[arg.Type.NamedType()]
+				if !ok {
+					return fmt.Errorf("%s.%s(%s): unknown type %s", name, field.Name, arg.Name, arg.Type.NamedType())
+				}
+				if argType.Kind != GraphQLScalar && argType.Kind != GraphQLEnum && argType.Kind != GraphQLInputObject {
+					return fmt.Errorf("%s.%s(%s): %s is not an input type", name, field.Name, arg.Name, argType.Name)
+				}
+			}
+		}
+		for _, iface := range t.Interfaces {
+			if ref, ok := s.types[iface]; !ok || ref.Kind != GraphQLInterface {
+				return fmt.Errorf("%s implements %s, which is not an interface", name, iface)
+			}
+		}
+		for _, member := range t.PossibleTypes {
+			if ref, ok := s.types[member]; !ok || ref.Kind != GraphQLObject {
+				return fmt.Errorf("union %s includes %s, which is not an object type", name, member)
+			}
+		}
+	}
+
+	for _, root := range []string{s.query, s.mutation, s.subscription} {
+		if root == "" {
+			continue
+		}
+		if t, ok := s.types[root]; !ok || t.Kind != GraphQLObject {
+			return fmt.Errorf("root type %s is not an object type", root)
+		}
+	}
+	return nil
+}
+
+// Type returns a named type.
+func (s *GraphQLSchema) Type(name string) (*GraphQLTypeDef, bool) {
+	t, ok := s.types[name]
+	return t, ok
+}
+
+// RootType returns the root type of an operation kind: "query", "mutation"
+// or "subscription".
+func (s *GraphQLSchema) RootType(operation string) (*GraphQLTypeDef, bool) {
+	name := map[string]string{"query": s.query, "mutation": s.mutation, "subscription": s.subscription}[operation]
+	if name == "" {
+		return nil, false
+	}
+	return s.Type(name)
+}
+
+// possibleTypes returns the object types a composite type can be at run
+// time.
+func (s *GraphQLSchema) possibleTypes(name string) []string {
+	t, ok := s.types[name]
+	if !ok {
+		return nil
+	}
+	switch t.Kind {
+	case GraphQLObject:
+		return []string{name}
+	case GraphQLUnion:
+		return t.PossibleTypes
+	case GraphQLInterface:
+		var types []string
+		for candidate, def := range s.types {
+			if def.Kind != GraphQLObject {
+				continue
+			}
+			for _, iface := range def.Interfaces {
+				if iface == name {
+					types = append(types, candidate)
+				}
+			}
+		}
+		sort.Strings(types)
+		return types
+	default:
+		return nil
+	}
+}
+
+// gqlToken is a lexical token of a GraphQL document. Kind is "name",
+// "int", "float", "string", "punct" or "eof".
+type gqlToken struct {
+	kind  string
+	value string
+	line  int
+	col   int
+}
+
+func (t gqlToken) String() string {
+	if t.kind == "eof" {
+		return "end of document"
+	}
+	if t.kind == "string" {
+		return strconv.Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+// lexGraphQL splits a GraphQL document into tokens, skipping whitespace,
+// commas and comments and decoding string literals.
+func lexGraphQL(source string) ([]gqlToken, error) {
+	var tokens []gqlToken
+	line, lineStart := 1, 0
+	source = strings.TrimPrefix(source, "\ufeff")
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		col := i - lineStart + 1
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+			lineStart = i
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, gqlToken{"punct", "...", line, col})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, gqlToken{"punct", string(c), line, col})
+			i++
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(source) && (source[j] == '_' || source[j] >= 'a' && source[j] <= 'z' || source[j] >= 'A' && source[j] <= 'Z' || source[j] >= '0' && source[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, gqlToken{"name", source[i:j], line, col})
+			i = j
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			kind := "int"
+			for j < len(source) && (source[j] >= '0' && source[j] <= '9' || strings.IndexByte(".eE+-", source[j]) >= 0) {
+				if strings.IndexByte(".eE", source[j]) >= 0 {
+					kind = "float"
+				}
+				j++
+			}
+			number := source[i:j]
+			if _, err := strconv.ParseFloat(number, 64); err != nil {
+				return nil, fmt.Errorf("%d:%d: invalid number %s", line, col, number)
+			}
+			tokens = append(tokens, gqlToken{kind, number, line, col})
+			i = j
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(source[i+3:], `"""`)
+			for end >= 0 && source[i+3+end-1] == '\\' {
+				next := strings.Index(source[i+3+end+3:], `"""`)
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += 3 + next
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%d:%d: unterminated block string", line, col)
+			}
+			raw := source[i+3 : i+3+end]
+			tokens = append(tokens, gqlToken{"string", blockStringValue(raw), line, col})
+			line += strings.Count(raw, "\n")
+			if k := strings.LastIndex(raw, "\n"); k >= 0 {
+				lineStart = i + 3 + k + 1
+			}
+			i += 3 + end + 3
+		case c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != '"' && source[j] != '\n' {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(source) || source[j] != '"' {
+				return nil, fmt.Errorf("%d:%d: unterminated string", line, col)
+			}
+			value, err := strconv.Unquote(source[i : j+1])
+			if err != nil {
+				value = source[i+1 : j]
+			}
+			tokens = append(tokens, gqlToken{"string", value, line, col})
+			i = j + 1
+		default:
+			return nil, fmt.Errorf("%d:%d: unexpected character %q", line, col, c)
+		}
+	}
+
+	tokens = append(tokens, gqlToken{kind: "eof", line: line, col: len(source) - lineStart + 1})
+	return tokens, nil
+}
+
+// blockStringValue removes the common indentation and the blank first and
+// last lines of a block string.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(l) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gqlValue is a literal value in a document. Kind is "variable", "int",
+// "float", "string", "boolean", "null", "enum", "list" or "object".
+type gqlValue struct {
+	kind   string
+	raw    string
+	list   []*gqlValue
+	fields []gqlObjectField
+	line   int
+	col    int
+}
+
+type gqlObjectField struct {
+	name  string
+	value *gqlValue
+}
+
+// gqlParser is a recursive-descent parser for SDL and executable documents.
+type gqlParser struct {
+	tokens []gqlToken
+	pos    int
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *gqlParser) next() gqlToken {
+	token := p.tokens[p.pos]
+	if token.kind != "eof" {
+		p.pos++
+	}
+	return token
+}
+
+func (p *gqlParser) is(value string) bool {
+	token := p.peek()
+	return (token.kind == "punct" || token.kind == "name") && token.value == value
+}
+
+func (p *gqlParser) accept(value string) bool {
+	if p.is(value) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *gqlParser) errorf(token gqlToken, format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", token.line, token.col, fmt.Sprintf(format, args...))
+}
+
+func (p *gqlParser) expect(value string) error {
+	if token := p.next(); !((token.kind == "punct" || token.kind == "name") && token.value == value) {
+		return p.errorf(token, "expected %q, got %s", value, token)
+	}
+	return nil
+}
+
+func (p *gqlParser) name() (string, error) {
+	token := p.next()
+	if token.kind != "name" {
+		return "", p.errorf(token, "expected a name, got %s", token)
+	}
+	return token.value, nil
+}
+
+func (p *gqlParser) parseSchema(s *GraphQLSchema, extensions *[]*GraphQLTypeDef) error {
+	for p.peek().kind != "eof" {
+		if p.peek().kind == "string" {
+			p.next() // Description
+		}
+
+		extend := p.accept("extend")
+		token := p.next()
+		if token.kind != "name" {
+			return p.errorf(token, "expected a definition, got %s", token)
+		}
+
+		switch token.value {
+		case "schema":
+			if _, err := p.parseDirectives(); err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			for !p.accept("}") {
+				operation, err := p.name()
+				if err != nil {
+					return err
+				}
+				if err := p.expect(":"); err != nil {
+					return err
+				}
+				typeName, err := p.name()
+				if err != nil {
+					return err
+				}
+				switch operation {
+				case "query":
+					s.query = typeName
+				case "mutation":
+					s.mutation = typeName
+				case "subscription":
+					s.subscription = typeName
+				default:
+					return p.errorf(token, "unknown root operation %s", operation)
+				}
+			}
+		case "directive":
+			def, err := p.parseDirectiveDefinition()
+			if err != nil {
+				return err
+			}
+			s.directives[def.Name] = def
+		case "scalar", "type", "interface", "union", "enum", "input":
+			def, err := p.parseTypeDefinition(token.value)
+			if err != nil {
+				return err
+			}
+			if extend {
+				*extensions = append(*extensions, def)
+				continue
+			}
+			if _, exists := s.types[def.Name]; exists {
+				return p.errorf(token, "type %s is declared more than once", def.Name)
+			}
+			s.types[def.Name] = def
+		default:
+			return p.errorf(token, "unexpected %s", token)
+		}
+	}
+	return nil
+}
+
+func (p *gqlParser) parseDirectiveDefinition() (*GraphQLDirectiveDef, error) {
+	if err := p.expect("@"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	def := &GraphQLDirectiveDef{Name: name}
+	if p.is("(") {
+		if def.Args, err = p.parseInputFields("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	p.accept("repeatable")
+	if err := p.expect("on"); err != nil {
+		return nil, err
+	}
+	p.accept("|")
+	for {
+		if _, err := p.name(); err != nil {
+			return nil, err
+		}
+		if !p.accept("|") {
+			return def, nil
+		}
+	}
+}
+
+func (p *gqlParser) parseTypeDefinition(keyword string) (*GraphQLTypeDef, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	kind := map[string]string{
+		"scalar": GraphQLScalar, "type": GraphQLObject, "interface": GraphQLInterface,
+		"union": GraphQLUnion, "enum": GraphQLEnum, "input": GraphQLInputObject,
+	}[keyword]
+	def := &GraphQLTypeDef{Name: name, Kind: kind}
+
+	if p.accept("implements") {
+		p.accept("&")
+		for {
+			iface, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			def.Interfaces = append(def.Interfaces, iface)
+			if !p.accept("&") {
+				break
+			}
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case GraphQLObject, GraphQLInterface:
+		if p.is("{") {
+			def.Fields, err = p.parseFieldDefinitions()
+		}
+	case GraphQLInputObject:
+		if p.is("{") {
+			def.Fields, err = p.parseInputFields("{", "}")
+		}
+	case GraphQLUnion:
+		if p.accept("=") {
+			p.accept("|")
+			for {
+				member, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				def.PossibleTypes = append(def.PossibleTypes, member)
+				if !p.accept("|") {
+					break
+				}
+			}
+		}
+	case GraphQLEnum:
+		if p.accept("{") {
+			for !p.accept("}") {
+				if p.peek().kind == "string" {
+					p.next()
+				}
+				valueName, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				directives, err := p.parseDirectives()
+				if err != nil {
+					return nil, err
+				}
+				value := &GraphQLEnumValue{Name: valueName}
+				value.Deprecated, value.DeprecationReason = deprecation(directives)
+				def.EnumValues = append(def.EnumValues, value)
+			}
+		}
+	}
+	return def, err
+}
+
+func (p *gqlParser) parseFieldDefinitions() ([]*GraphQLField, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []*GraphQLField
+	for !p.accept("}") {
+		if p.peek().kind == "eof" {
+			return nil, p.errorf(p.peek(), "expected \"}\", got end of document")
+		}
+		if p.peek().kind == "string" {
+			p.next()
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		field := &GraphQLField{Name: name}
+		if p.is("(") {
+			if field.Args, err = p.parseInputFields("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if field.Type, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		field.Deprecated, field.DeprecationReason = deprecation(directives)
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseInputFields parses argument or input field definitions between the
+// given delimiters.
+func (p *gqlParser) parseInputFields(open, close string) ([]*GraphQLField, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	var fields []*GraphQLField
+	for !p.accept(close) {
+		if p.peek().kind == "eof" {
+			return nil, p.errorf(p.peek(), "expected %q, got end of document", close)
+		}
+		if p.peek().kind == "string" {
+			p.next()
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		field := &GraphQLField{Name: name}
+		if field.Type, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if p.accept("=") {
+			if field.Default, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		field.Deprecated, field.DeprecationReason = deprecation(directives)
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func (p *gqlParser) parseType() (*GraphQLTypeRef, error) {
+	var t *GraphQLTypeRef
+	if p.accept("[") {
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &GraphQLTypeRef{OfType: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t = &GraphQLTypeRef{Name: name}
+	}
+	t.NonNull = p.accept("!")
+	return t, nil
+}
+
+// gqlDirective is a directive applied in a document.
+type gqlDirective struct {
+	name string
+	args []gqlArgument
+	line int
+	col  int
+}
+
+type gqlArgument struct {
+	name  string
+	value *gqlValue
+}
+
+func (p *gqlParser) parseDirectives() ([]gqlDirective, error) {
+	var directives []gqlDirective
+	for p.is("@") {
+		token := p.next()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		directive := gqlDirective{name: name, line: token.line, col: token.col}
+		if p.is("(") {
+			if directive.args, err = p.parseArguments(false); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+func (p *gqlParser) parseArguments(constant bool) ([]gqlArgument, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []gqlArgument
+	for !p.accept(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, gqlArgument{name: name, value: value})
+	}
+	return args, nil
+}
+
+func (p *gqlParser) parseValue(constant bool) (*gqlValue, error) {
+	token := p.next()
+	value := &gqlValue{raw: token.value, line: token.line, col: token.col}
+
+	switch {
+	case token.kind == "punct" && token.value == "$":
+		if constant {
+			return nil, p.errorf(token, "variables are not allowed here")
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		value.kind, value.raw = "variable", name
+	case token.kind == "int", token.kind == "float", token.kind == "string":
+		value.kind = token.kind
+	case token.kind == "name":
+		switch token.value {
+		case "true", "false":
+			value.kind = "boolean"
+		case "null":
+			value.kind = "null"
+		default:
+			value.kind = "enum"
+		}
+	case token.kind == "punct" && token.value == "[":
+		value.kind = "list"
+		for !p.accept("]") {
+			if p.peek().kind == "eof" {
+				return nil, p.errorf(p.peek(), "expected \"]\", got end of document")
+			}
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			value.list = append(value.list, item)
+		}
+	case token.kind == "punct" && token.value == "{":
+		value.kind = "object"
+		for !p.accept("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			field, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			value.fields = append(value.fields, gqlObjectField{name: name, value: field})
+		}
+	default:
+		return nil, p.errorf(token, "expected a value, got %s", token)
+	}
+	return value, nil
+}
+
+// deprecation reports whether the directives include @deprecated, and its
+// reason.
+func deprecation(directives []gqlDirective) (bool, string) {
+	for _, directive := range directives {
+		if directive.name != "deprecated" {
+			continue
+		}
+		reason := "No longer supported"
+		for _, arg := range directive.args {
+			if arg.name == "reason" && arg.value.kind == "string" {
+				reason = arg.value.raw
+			}
+		}
+		return true, reason
+	}
+	return false, ""
+}
@@ -0,0 +1,1045 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GraphQLQuery is a parsed executable document and the operation in it that
+// a request runs.
+type GraphQLQuery struct {
+	schema     *GraphQLSchema
+	operation  *gqlOperation
+	operations []*gqlOperation
+	fragments  map[string]*gqlFragment
+	order      []string // fragment names in document order
+}
+
+type gqlOperation struct {
+	kind       string
+	name       string
+	variables  []gqlVariableDef
+	directives []gqlDirective
+	selections []*gqlSelection
+}
+
+type gqlVariableDef struct {
+	name string
+	typ  *GraphQLTypeRef
+	def  *gqlValue
+}
+
+type gqlFragment struct {
+	name          string
+	typeCondition string
+	directives    []gqlDirective
+	selections    []*gqlSelection
+}
+
+// gqlSelection is a field, a fragment spread or an inline fragment. Name is
+// the fragment name of a spread.
+type gqlSelection struct {
+	kind          string
+	alias         string
+	name          string
+	args          []gqlArgument
+	directives    []gqlDirective
+	selections    []*gqlSelection
+	typeCondition string
+}
+
+// responseKey is the key a field is returned under.
+func (s *gqlSelection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// ParseQuery parses an executable document and picks the operation to run:
+// the one named operationName, or the only operation when the name is empty.
+func (s *GraphQLSchema) ParseQuery(document, operationName string) (*GraphQLQuery, error) {
+	tokens, err := lexGraphQL(document)
+	if err != nil {
+		return nil, err
+	}
+	p := &gqlParser{tokens: tokens}
+	q := &GraphQLQuery{schema: s, fragments: map[string]*gqlFragment{}}
+
+	for p.peek().kind != "eof" {
+		token := p.peek()
+		switch {
+		case p.is("{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			q.operations = append(q.operations, &gqlOperation{kind: "query", selections: selections})
+		case p.is("query"), p.is("mutation"), p.is("subscription"):
+			operation, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			q.operations = append(q.operations, operation)
+		case p.is("fragment"):
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := q.fragments[fragment.name]; exists {
+				return nil, p.errorf(token, "fragment %s is defined more than once", fragment.name)
+			}
+			q.fragments[fragment.name] = fragment
+			q.order = append(q.order, fragment.name)
+		default:
+			return nil, p.errorf(token, "expected an operation or fragment, got %s", token)
+		}
+	}
+
+	names := map[string]bool{}
+	for _, operation := range q.operations {
+		if operation.name == "" && len(q.operations) > 1 {
+			return nil, fmt.Errorf("anonymous operation must be the only operation in the document")
+		}
+		if names[operation.name] {
+			return nil, fmt.Errorf("operation %s is defined more than once", operation.name)
+		}
+		names[operation.name] = true
+		if operation.name == operationName || operationName == "" && len(q.operations) == 1 {
+			q.operation = operation
+		}
+	}
+	switch {
+	case len(q.operations) == 0:
+		return nil, fmt.Errorf("document has no operation")
+	case q.operation == nil && operationName == "":
+		return nil, fmt.Errorf("document has %d operations; operationName is required", len(q.operations))
+	case q.operation == nil:
+		return nil, fmt.Errorf("document has no operation named %s", operationName)
+	}
+	return q, nil
+}
+
+// Operation returns the kind of the selected operation: "query",
+// "mutation" or "subscription".
+func (q *GraphQLQuery) Operation() string {
+	return q.operation.kind
+}
+
+// Name returns the name of the selected operation, if it has one.
+func (q *GraphQLQuery) Name() string {
+	return q.operation.name
+}
+
+func (p *gqlParser) parseOperation() (*gqlOperation, error) {
+	operation := &gqlOperation{kind: p.next().value}
+	if p.peek().kind == "name" {
+		operation.name = p.next().value
+	}
+
+	if p.accept("(") {
+		for !p.accept(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			variable := gqlVariableDef{name: name}
+			if variable.typ, err = p.parseType(); err != nil {
+				return nil, err
+			}
+			if p.accept("=") {
+				if variable.def, err = p.parseValue(true); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := p.parseDirectives(); err != nil {
+				return nil, err
+			}
+			operation.variables = append(operation.variables, variable)
+		}
+	}
+
+	var err error
+	if operation.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if operation.selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (p *gqlParser) parseFragment() (*gqlFragment, error) {
+	p.next()
+	token := p.peek()
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.errorf(token, "fragment must be named")
+	}
+	if err := p.expect("on"); err != nil {
+		return nil, err
+	}
+	fragment := &gqlFragment{name: name}
+	if fragment.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if fragment.selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *gqlParser) parseSelectionSet() ([]*gqlSelection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []*gqlSelection
+	for !p.accept("}") {
+		if p.peek().kind == "eof" {
+			return nil, p.errorf(p.peek(), "expected \"}\", got end of document")
+		}
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf(p.tokens[p.pos-1], "selection set is empty")
+	}
+	return selections, nil
+}
+
+func (p *gqlParser) parseSelection() (*gqlSelection, error) {
+	var err error
+	if p.accept("...") {
+		selection := &gqlSelection{kind: "inline"}
+		switch {
+		case p.accept("on"):
+			if selection.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		case p.peek().kind == "name":
+			selection.kind, selection.name = "spread", p.next().value
+		}
+		if selection.directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		if selection.kind == "inline" {
+			if selection.selections, err = p.parseSelectionSet(); err != nil {
+				return nil, err
+			}
+		}
+		return selection, nil
+	}
+
+	selection := &gqlSelection{kind: "field"}
+	if selection.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.accept(":") {
+		selection.alias = selection.name
+		if selection.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.is("(") {
+		if selection.args, err = p.parseArguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if selection.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		if selection.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return selection, nil
+}
+
+// gqlValidator checks the selected operation, the fragments it reaches and
+// the variables sent with it.
+type gqlValidator struct {
+	q          *GraphQLQuery
+	variables  map[string]gqlVariableDef
+	used       map[string]bool
+	violations []Violation
+}
+
+func (v *gqlValidator) add(rule, path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the selected operation against the schema: fields,
+// arguments, directives, fragments and variable usages, and the variables
+// sent with the request. Paths are response paths such as "order.items",
+// "variables.id" or "fragment OrderFields.status". Uses of deprecated
+// fields, arguments and enum values are reported with the rule
+// "deprecated".
+func (q *GraphQLQuery) Validate(variables map[string]interface{}) []Violation {
+	v := &gqlValidator{q: q, variables: map[string]gqlVariableDef{}, used: map[string]bool{}}
+	operation := q.operation
+	root := ""
+
+	for _, variable := range operation.variables {
+		path := "variables." + variable.name
+		if _, exists := v.variables[variable.name]; exists {
+			v.add("variable", path, "variable $%s is declared more than once", variable.name)
+			continue
+		}
+		v.variables[variable.name] = variable
+		t, ok := q.schema.types[variable.typ.NamedType()]
+		if !ok {
+			v.add("variable", path, "unknown type %s", variable.typ.NamedType())
+			continue
+		}
+		if t.Kind != GraphQLScalar && t.Kind != GraphQLEnum && t.Kind != GraphQLInputObject {
+			v.add("variable", path, "%s is not an input type", t.Name)
+			continue
+		}
+		if variable.def != nil {
+			v.checkLiteral(variable.typ, variable.def, path+" default", false)
+		}
+
+		value, sent := variables[variable.name]
+		switch {
+		case sent:
+			v.checkInput(variable.typ, value, path)
+		case variable.typ.NonNull && variable.def == nil:
+			v.add("variable", path, "required variable $%s of type %s is not provided", variable.name, variable.typ)
+		}
+	}
+	for _, name := range sortedNames(variables) {
+		if _, ok := v.variables[name]; !ok {
+			v.add("variable", "variables."+name, "variable $%s is not declared by the operation", name)
+		}
+	}
+
+	rootType, ok := q.schema.RootType(operation.kind)
+	if !ok {
+		v.add("selection", root, "schema does not support %s operations", operation.kind)
+		return v.violations
+	}
+	v.checkDirectives(operation.directives, root)
+	v.checkSelections(rootType, operation.selections, root)
+
+	reached := map[string]bool{}
+	v.reach(operation.selections, reached, nil, root)
+	for _, name := range q.order {
+		fragment := q.fragments[name]
+		path := "fragment " + name
+		if !reached[name] {
+			if !q.usedByOtherOperation(name) {
+				v.add("fragment", path, "fragment %s is never used", name)
+			}
+			continue
+		}
+		t, ok := q.schema.types[fragment.typeCondition]
+		if !ok {
+			v.add("fragment", path, "unknown type %s", fragment.typeCondition)
+			continue
+		}
+		if !isCompositeKind(t.Kind) {
+			v.add("fragment", path, "fragment cannot be on %s type %s", kindName(t.Kind), t.Name)
+			continue
+		}
+		v.checkDirectives(fragment.directives, path)
+		v.checkSelections(t, fragment.selections, path)
+	}
+
+	for _, variable := range operation.variables {
+		if !v.used[variable.name] {
+			v.add("variable", "variables."+variable.name, "variable $%s is never used", variable.name)
+		}
+	}
+	return v.violations
+}
+
+// reach records the fragments the selections spread, directly or through
+// other fragments, and reports spread cycles.
+func (v *gqlValidator) reach(selections []*gqlSelection, reached map[string]bool, stack []string, path string) {
+	for _, selection := range selections {
+		if selection.kind != "spread" {
+			v.reach(selection.selections, reached, stack, path)
+			continue
+		}
+		fragment, ok := v.q.fragments[selection.name]
+		if !ok {
+			continue
+		}
+		for _, name := range stack {
+			if name == selection.name {
+				v.add("fragment", "fragment "+name, "fragment %s spreads itself", name)
+				return
+			}
+		}
+		if reached[selection.name] {
+			continue
+		}
+		reached[selection.name] = true
+		v.reach(fragment.selections, reached, append(stack, selection.name), path)
+	}
+}
+
+// usedByOtherOperation reports whether an operation other than the
+// selected one spreads the fragment.
+func (q *GraphQLQuery) usedByOtherOperation(name string) bool {
+	for _, operation := range q.operations {
+		if operation == q.operation {
+			continue
+		}
+		reached := map[string]bool{}
+		(&gqlValidator{q: q}).reach(operation.selections, reached, nil, "")
+		if reached[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *gqlValidator) checkSelections(parent *GraphQLTypeDef, selections []*gqlSelection, path string) {
+	fields := map[string]*gqlSelection{}
+
+	for _, selection := range selections {
+		if selection.kind == "field" {
+			v.checkDirectives(selection.directives, joinPath(path, selection.responseKey()))
+		} else {
+			v.checkDirectives(selection.directives, path)
+		}
+
+		switch selection.kind {
+		case "spread":
+			fragment, ok := v.q.fragments[selection.name]
+			if !ok {
+				v.add("fragment", path, "unknown fragment %s", selection.name)
+				continue
+			}
+			v.checkSpread(parent, fragment.typeCondition, path, "fragment "+selection.name)
+		case "inline":
+			t := parent
+			if selection.typeCondition != "" {
+				condition, ok := v.q.schema.types[selection.typeCondition]
+				if !ok {
+					v.add("fragment", path, "unknown type %s in inline fragment", selection.typeCondition)
+					continue
+				}
+				if !isCompositeKind(condition.Kind) {
+					v.add("fragment", path, "inline fragment cannot be on %s type %s", kindName(condition.Kind), condition.Name)
+					continue
+				}
+				if !v.checkSpread(parent, condition.Name, path, "inline fragment on "+condition.Name) {
+					continue
+				}
+				t = condition
+			}
+			v.checkSelections(t, selection.selections, path)
+		case "field":
+			key := selection.responseKey()
+			fieldPath := joinPath(path, key)
+			if previous, ok := fields[key]; ok && (previous.name != selection.name || !sameArguments(previous.args, selection.args)) {
+				v.add("selection", fieldPath, "%s selects %s and %s under the same name", key, previous.name, selection.name)
+			}
+			fields[key] = selection
+			v.checkField(parent, selection, fieldPath)
+		}
+	}
+}
+
+// checkSpread reports a fragment that can never apply to the parent type.
+func (v *gqlValidator) checkSpread(parent *GraphQLTypeDef, condition, path, what string) bool {
+	parentTypes := v.q.schema.possibleTypes(parent.Name)
+	for _, candidate := range v.q.schema.possibleTypes(condition) {
+		for _, possible := range parentTypes {
+			if candidate == possible {
+				return true
+			}
+		}
+	}
+	if _, ok := v.q.schema.types[condition]; ok {
+		v.add("fragment", path, "%s can never apply to %s", what, parent.Name)
+	}
+	return false
+}
+
+func (v *gqlValidator) checkField(parent *GraphQLTypeDef, selection *gqlSelection, path string) {
+	if selection.name == "__typename" {
+		if len(selection.selections) > 0 {
+			v.add("selection", path, "__typename is a String and cannot have a selection set")
+		}
+		return
+	}
+	if (selection.name == "__schema" || selection.name == "__type") && parent.Name == v.q.schema.query {
+		return // Introspection is answered by the server itself.
+	}
+
+	field, ok := parent.Field(selection.name)
+	if !ok || parent.Kind == GraphQLUnion {
+		v.add("field", path, "%s has no field %s", parent.Name, selection.name)
+		return
+	}
+	if field.Deprecated {
+		v.add("deprecated", path, "%s.%s is deprecated: %s", parent.Name, field.Name, field.DeprecationReason)
+	}
+	v.checkArguments(field.Args, selection.args, path, parent.Name+"."+field.Name)
+
+	t := v.q.schema.types[field.Type.NamedType()]
+	switch {
+	case isCompositeKind(t.Kind) && len(selection.selections) == 0:
+		v.add("selection", path, "%s.%s of type %s must have a selection set", parent.Name, field.Name, field.Type)
+	case !isCompositeKind(t.Kind) && len(selection.selections) > 0:
+		v.add("selection", path, "%s.%s of %s type %s cannot have a selection set", parent.Name, field.Name, kindName(t.Kind), field.Type)
+	case len(selection.selections) > 0:
+		v.checkSelections(t, selection.selections, path)
+	}
+}
+
+func (v *gqlValidator) checkArguments(defs []*GraphQLField, args []gqlArgument, path, owner string) {
+	seen := map[string]bool{}
+	for _, arg := range args {
+		if seen[arg.name] {
+			v.add("argument", path, "argument %s is given more than once", arg.name)
+			continue
+		}
+		seen[arg.name] = true
+		def, ok := findField(defs, arg.name)
+		if !ok {
+			v.add("argument", path, "%s has no argument %s", owner, arg.name)
+			continue
+		}
+		if def.Deprecated {
+			v.add("deprecated", path, "argument %s of %s is deprecated: %s", arg.name, owner, def.DeprecationReason)
+		}
+		v.checkLiteral(def.Type, arg.value, path+"("+arg.name+")", def.Default != nil)
+	}
+	for _, def := range defs {
+		if def.Type.NonNull && def.Default == nil && !seen[def.Name] {
+			v.add("argument", path, "required argument %s of %s is missing", def.Name, owner)
+		}
+	}
+}
+
+func (v *gqlValidator) checkDirectives(directives []gqlDirective, path string) {
+	seen := map[string]bool{}
+	for _, directive := range directives {
+		def, ok := v.q.schema.directives[directive.name]
+		if !ok {
+			v.add("directive", path, "unknown directive @%s", directive.name)
+			continue
+		}
+		if seen[directive.name] && (directive.name == "skip" || directive.name == "include") {
+			v.add("directive", path, "@%s is given more than once", directive.name)
+		}
+		seen[directive.name] = true
+		v.checkArguments(def.Args, directive.args, path, "@"+directive.name)
+	}
+}
+
+// checkLiteral checks a value written in the document against the type of
+// the argument, input field or variable it is given for.
+func (v *gqlValidator) checkLiteral(t *GraphQLTypeRef, value *gqlValue, path string, hasDefault bool) {
+	if value.kind == "variable" {
+		v.used[value.raw] = true
+		variable, ok := v.variables[value.raw]
+		if !ok {
+			v.add("variable", path, "variable $%s is not declared by the operation", value.raw)
+			return
+		}
+		if !variableAllowed(variable, t, hasDefault) {
+			v.add("variable", path, "variable $%s of type %s cannot be used where %s is expected", value.raw, variable.typ, t)
+		}
+		return
+	}
+
+	if value.kind == "null" {
+		if t.NonNull {
+			v.add("type", path, "expected %s, got null", t)
+		}
+		return
+	}
+	if t.OfType != nil {
+		if value.kind != "list" {
+			v.checkLiteral(t.OfType, value, path, false)
+			return
+		}
+		for i, item := range value.list {
+			v.checkLiteral(t.OfType, item, fmt.Sprintf("%s[%d]", path, i), false)
+		}
+		return
+	}
+
+	def, ok := v.q.schema.types[t.Name]
+	if !ok {
+		return
+	}
+	switch def.Kind {
+	case GraphQLScalar:
+		if !literalScalarAllowed(t.Name, value) {
+			v.add("type", path, "expected %s, got %s", t, literalDescription(value))
+		}
+	case GraphQLEnum:
+		if value.kind != "enum" {
+			v.add("type", path, "expected %s, got %s", t, literalDescription(value))
+			return
+		}
+		enumValue, ok := def.EnumValue(value.raw)
+		if !ok {
+			v.add("enum", path, "%s is not a value of %s", value.raw, def.Name)
+			return
+		}
+		if enumValue.Deprecated {
+			v.add("deprecated", path, "%s.%s is deprecated: %s", def.Name, value.raw, enumValue.DeprecationReason)
+		}
+	case GraphQLInputObject:
+		if value.kind != "object" {
+			v.add("type", path, "expected %s, got %s", t, literalDescription(value))
+			return
+		}
+		given := map[string]bool{}
+		for _, field := range value.fields {
+			given[field.name] = true
+			fieldDef, ok := def.Field(field.name)
+			if !ok {
+				v.add("field", joinPath(path, field.name), "%s has no field %s", def.Name, field.name)
+				continue
+			}
+			if fieldDef.Deprecated {
+				v.add("deprecated", joinPath(path, field.name), "%s.%s is deprecated: %s", def.Name, field.name, fieldDef.DeprecationReason)
+			}
+			v.checkLiteral(fieldDef.Type, field.value, joinPath(path, field.name), fieldDef.Default != nil)
+		}
+		for _, fieldDef := range def.Fields {
+			if fieldDef.Type.NonNull && fieldDef.Default == nil && !given[fieldDef.Name] {
+				v.add("field", path, "required field %s of %s is missing", fieldDef.Name, def.Name)
+			}
+		}
+	}
+}
+
+// checkInput checks a JSON variable value against its declared type,
+// following GraphQL's input coercion rules.
+func (v *gqlValidator) checkInput(t *GraphQLTypeRef, value interface{}, path string) {
+	if value == nil {
+		if t.NonNull {
+			v.add("type", path, "expected %s, got null", t)
+		}
+		return
+	}
+	if t.OfType != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			v.checkInput(t.OfType, value, path)
+			return
+		}
+		for i, item := range items {
+			v.checkInput(t.OfType, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	}
+
+	def, ok := v.q.schema.types[t.Name]
+	if !ok {
+		return
+	}
+	switch def.Kind {
+	case GraphQLScalar:
+		if !jsonScalarAllowed(t.Name, value) {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+		}
+	case GraphQLEnum:
+		name, ok := value.(string)
+		if !ok {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+			return
+		}
+		enumValue, ok := def.EnumValue(name)
+		if !ok {
+			v.add("enum", path, "%s is not a value of %s", name, def.Name)
+			return
+		}
+		if enumValue.Deprecated {
+			v.add("deprecated", path, "%s.%s is deprecated: %s", def.Name, name, enumValue.DeprecationReason)
+		}
+	case GraphQLInputObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+			return
+		}
+		for _, name := range sortedNames(object) {
+			fieldDef, ok := def.Field(name)
+			if !ok {
+				v.add("field", joinPath(path, name), "%s has no field %s", def.Name, name)
+				continue
+			}
+			if fieldDef.Deprecated {
+				v.add("deprecated", joinPath(path, name), "%s.%s is deprecated: %s", def.Name, name, fieldDef.DeprecationReason)
+			}
+			v.checkInput(fieldDef.Type, object[name], joinPath(path, name))
+		}
+		for _, fieldDef := range def.Fields {
+			if _, given := object[fieldDef.Name]; fieldDef.Type.NonNull && fieldDef.Default == nil && !given {
+				v.add("field", path, "required field %s of %s is missing", fieldDef.Name, def.Name)
+			}
+		}
+	}
+}
+
+// ValidateResponse checks the data of a response against the selection set
+// of the operation: every key must be a selected field, and every value
+// must fit the field's type. Fields left out of the data are not reported,
+// since consumers often spell out only the part of a response they read.
+func (q *GraphQLQuery) ValidateResponse(data interface{}, path string) []Violation {
+	if data == nil {
+		return nil
+	}
+	root, ok := q.schema.RootType(q.operation.kind)
+	if !ok {
+		return nil
+	}
+	v := &gqlValidator{q: q}
+	v.checkResponse(&GraphQLTypeRef{Name: root.Name, NonNull: true}, q.operation.selections, data, path)
+	return v.violations
+}
+
+// ResponseSchema returns a schema, in the form Spec reads, of the data the
+// operation's selection set returns. Abstract types list the fields of every
+// fragment that could apply.
+func (q *GraphQLQuery) ResponseSchema() interface{} {
+	root, ok := q.schema.RootType(q.operation.kind)
+	if !ok {
+		return nil
+	}
+	v := &gqlValidator{q: q}
+	return v.responseSchema(&GraphQLTypeRef{Name: root.Name, NonNull: true}, q.operation.selections)
+}
+
+func (v *gqlValidator) responseSchema(t *GraphQLTypeRef, selections []*gqlSelection) map[interface{}]interface{} {
+	if t.OfType != nil {
+		return map[interface{}]interface{}{"type": "array", "items": v.responseSchema(t.OfType, selections)}
+	}
+
+	def, ok := v.q.schema.types[t.Name]
+	if !ok {
+		return map[interface{}]interface{}{}
+	}
+	switch def.Kind {
+	case GraphQLScalar:
+		switch t.Name {
+		case "Int":
+			return map[interface{}]interface{}{"type": "integer"}
+		case "Float":
+			return map[interface{}]interface{}{"type": "number"}
+		case "String":
+			return map[interface{}]interface{}{"type": "string"}
+		case "Boolean":
+			return map[interface{}]interface{}{"type": "boolean"}
+		case "ID":
+			return map[interface{}]interface{}{"type": []interface{}{"string", "integer"}}
+		}
+		return map[interface{}]interface{}{}
+	case GraphQLEnum:
+		return map[interface{}]interface{}{"type": "string"}
+	}
+
+	concrete := ""
+	if def.Kind == GraphQLObject {
+		concrete = def.Name
+	}
+	fields := map[string]*collectedField{}
+	v.collectFields(def, concrete, selections, fields)
+	properties := make(map[interface{}]interface{}, len(fields))
+	for key, field := range fields {
+		if field.typ == nil {
+			properties[key] = map[interface{}]interface{}{"type": "string"}
+			continue
+		}
+		properties[key] = v.responseSchema(field.typ, field.selections)
+	}
+	return map[interface{}]interface{}{"type": "object", "properties": properties}
+}
+
+func (v *gqlValidator) checkResponse(t *GraphQLTypeRef, selections []*gqlSelection, value interface{}, path string) {
+	if value == nil {
+		if t.NonNull {
+			v.add("null", path, "%s is non-null but the response has null", t)
+		}
+		return
+	}
+	if t.OfType != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+			return
+		}
+		for i, item := range items {
+			v.checkResponse(t.OfType, selections, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	}
+
+	def, ok := v.q.schema.types[t.Name]
+	if !ok {
+		return
+	}
+	switch def.Kind {
+	case GraphQLScalar:
+		if !jsonScalarAllowed(t.Name, value) {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+		}
+		return
+	case GraphQLEnum:
+		name, ok := value.(string)
+		if !ok {
+			v.add("type", path, "expected %s, got %s", t, valueType(value))
+		} else if _, ok := def.EnumValue(name); !ok {
+			v.add("enum", path, "%s is not a value of %s", name, def.Name)
+		}
+		return
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.add("type", path, "expected %s, got %s", t, valueType(value))
+		return
+	}
+
+	// The concrete type of an abstract value is known only from __typename;
+	// without it every fragment that could apply is taken into account.
+	concrete := ""
+	if def.Kind == GraphQLObject {
+		concrete = def.Name
+	} else if name, ok := object["__typename"].(string); ok {
+		if !containsString(v.q.schema.possibleTypes(def.Name), name) {
+			v.add("type", joinPath(path, "__typename"), "%s is not a possible type of %s", name, def.Name)
+			return
+		}
+		concrete = name
+	}
+
+	fields := map[string]*collectedField{}
+	v.collectFields(def, concrete, selections, fields)
+	for _, key := range sortedNames(object) {
+		fieldPath := joinPath(path, key)
+		field, ok := fields[key]
+		if !ok {
+			v.add("not-selected", fieldPath, "%s is not selected by the query", key)
+			continue
+		}
+		if field.typ == nil {
+			if _, ok := object[key].(string); !ok {
+				v.add("type", fieldPath, "expected String!, got %s", valueType(object[key]))
+			}
+			continue
+		}
+		v.checkResponse(field.typ, field.selections, object[key], fieldPath)
+	}
+}
+
+// collectedField is a field selected on an object, with the selection sets
+// of every selection of it merged. The type of __typename is nil.
+type collectedField struct {
+	typ        *GraphQLTypeRef
+	selections []*gqlSelection
+}
+
+// collectFields gathers the fields that apply to a value of the given type
+// by response key, following fragments whose type condition matches the
+// concrete type, or every fragment when the concrete type is not known.
+func (v *gqlValidator) collectFields(scope *GraphQLTypeDef, concrete string, selections []*gqlSelection, fields map[string]*collectedField) {
+	for _, selection := range selections {
+		switch selection.kind {
+		case "field":
+			key := selection.responseKey()
+			field := fields[key]
+			if field == nil {
+				field = &collectedField{}
+				if selection.name != "__typename" {
+					def, ok := scope.Field(selection.name)
+					if !ok {
+						continue
+					}
+					field.typ = def.Type
+				}
+				fields[key] = field
+			}
+			field.selections = append(field.selections, selection.selections...)
+		case "spread", "inline":
+			condition := selection.typeCondition
+			body := selection.selections
+			if selection.kind == "spread" {
+				fragment, ok := v.q.fragments[selection.name]
+				if !ok {
+					continue
+				}
+				condition, body = fragment.typeCondition, fragment.selections
+			}
+			t := scope
+			if condition != "" {
+				def, ok := v.q.schema.types[condition]
+				if !ok {
+					continue
+				}
+				if concrete != "" && !containsString(v.q.schema.possibleTypes(condition), concrete) {
+					continue
+				}
+				t = def
+			}
+			v.collectFields(t, concrete, body, fields)
+		}
+	}
+}
+
+// variableAllowed reports whether a variable may be used where a value of
+// type t is expected. A nullable variable can fill a non-null position only
+// if the variable or the position has a default.
+func variableAllowed(variable gqlVariableDef, t *GraphQLTypeRef, hasDefault bool) bool {
+	varType := variable.typ
+	if t.NonNull && !varType.NonNull {
+		if (variable.def == nil || variable.def.kind == "null") && !hasDefault {
+			return false
+		}
+		stripped := *t
+		stripped.NonNull = false
+		t = &stripped
+	}
+	return typeCompatible(varType, t)
+}
+
+func typeCompatible(varType, t *GraphQLTypeRef) bool {
+	if t.NonNull && !varType.NonNull {
+		return false
+	}
+	if (varType.OfType == nil) != (t.OfType == nil) {
+		return false
+	}
+	if t.OfType != nil {
+		return typeCompatible(varType.OfType, t.OfType)
+	}
+	return varType.Name == t.Name
+}
+
+func literalScalarAllowed(name string, value *gqlValue) bool {
+	switch name {
+	case "Int":
+		if value.kind != "int" {
+			return false
+		}
+		n, err := strconv.ParseInt(value.raw, 10, 64)
+		return err == nil && n >= math.MinInt32 && n <= math.MaxInt32
+	case "Float":
+		return value.kind == "int" || value.kind == "float"
+	case "String":
+		return value.kind == "string"
+	case "Boolean":
+		return value.kind == "boolean"
+	case "ID":
+		return value.kind == "string" || value.kind == "int"
+	default:
+		return true
+	}
+}
+
+func jsonScalarAllowed(name string, value interface{}) bool {
+	switch name {
+	case "Int":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32
+	case "Float":
+		_, ok := value.(float64)
+		return ok
+	case "String":
+		_, ok := value.(string)
+		return ok
+	case "Boolean":
+		_, ok := value.(bool)
+		return ok
+	case "ID":
+		if n, ok := value.(float64); ok {
+			return n == math.Trunc(n)
+		}
+		_, ok := value.(string)
+		return ok
+	default:
+		return true
+	}
+}
+
+func literalDescription(value *gqlValue) string {
+	switch value.kind {
+	case "list", "object":
+		return value.kind
+	case "string":
+		return strconv.Quote(value.raw)
+	default:
+		return value.raw
+	}
+}
+
+func sameArguments(a, b []gqlArgument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].name != b[i].name || a[i].value.kind != b[i].value.kind || a[i].value.raw != b[i].value.raw {
+			return false
+		}
+	}
+	return true
+}
+
+func findField(fields []*GraphQLField, name string) (*GraphQLField, bool) {
+	for _, field := range fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+func isCompositeKind(kind string) bool {
+	return kind == GraphQLObject || kind == GraphQLInterface || kind == GraphQLUnion
+}
+
+func kindName(kind string) string {
+	switch kind {
+	case GraphQLInputObject:
+		return "input"
+	default:
+		return strings.ToLower(kind)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
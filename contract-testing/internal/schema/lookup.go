@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"strings"
+)
+
+// SchemaAt returns the schema describing the value at path inside a value
+// described by root. Path segments are property names separated by dots,
+// with [n] or [*] for array items, e.g. "items[*].quantity". The second
+// result is false if the schema doesn't declare that location.
+func (s *Spec) SchemaAt(root interface{}, path string) (interface{}, bool) {
+	node := root
+	for _, segment := range splitPath(path) {
+		next, ok := s.child(node, segment, 0)
+		if !ok {
+			return nil, false
+		}
+		node = next
+	}
+	return node, true
+}
+
+// child returns the schema of a property, or of the items for an index segment.
+func (s *Spec) child(schema interface{}, segment string, depth int) (interface{}, bool) {
+	node := asMap(s.Resolve(schema))
+	if node == nil || depth > 16 {
+		return nil, false
+	}
+
+	if strings.HasPrefix(segment, "[") {
+		if items, ok := node["items"]; ok {
+			return items, true
+		}
+	} else {
+		if property, ok := asMap(node["properties"])[segment]; ok {
+			return property, true
+		}
+		if additional := asMap(node["additionalProperties"]); additional != nil {
+			return additional, true
+		}
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		for _, sub := range asSlice(node[key]) {
+			if found, ok := s.child(sub, segment, depth+1); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// AllowsType reports whether a schema admits values of a JSON type ("string",
+// "number", "integer", "boolean", "array", "object" or "null"). Schemas
+// without a type admit everything; "number" schemas admit integers.
+func (s *Spec) AllowsType(schema interface{}, name string) bool {
+	node := asMap(s.Resolve(schema))
+	if node == nil {
+		return true
+	}
+	if _, typed := node["type"]; !typed {
+		for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+			if options := asSlice(node[key]); len(options) > 0 {
+				for _, sub := range options {
+					if s.AllowsType(sub, name) {
+						return true
+					}
+				}
+				return false
+			}
+		}
+		return true
+	}
+
+	if typeAllows(node, name) {
+		return true
+	}
+	return name == "integer" && typeAllows(node, "number")
+}
+
+// Format returns a schema's format keyword.
+func (s *Spec) Format(schema interface{}) string {
+	return asString(asMap(s.Resolve(schema))["format"])
+}
+
+// ItemBounds returns an array schema's minItems and, if set, maxItems.
+func (s *Spec) ItemBounds(schema interface{}) (min int, max int, hasMax bool) {
+	node := asMap(s.Resolve(schema))
+	if n, ok := asFloat(node["minItems"]); ok {
+		min = int(n)
+	}
+	if n, ok := asFloat(node["maxItems"]); ok {
+		max, hasMax = int(n), true
+	}
+	return min, max, hasMax
+}
+
+// splitPath splits "items[0].name" into "items", "[0]" and "name".
+func splitPath(path string) []string {
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			open := strings.Index(part, "[")
+			switch {
+			case open == -1:
+				segments = append(segments, part)
+				part = ""
+			case open > 0:
+				segments = append(segments, part[:open])
+				part = part[open:]
+			default:
+				end := strings.Index(part, "]")
+				if end == -1 {
+					segments = append(segments, part)
+					part = ""
+					continue
+				}
+				segments = append(segments, part[:end+1])
+				part = part[end+1:]
+			}
+		}
+	}
+	return segments
+}
+
+// RequestBodySchema returns the schema of the request body sent with
+// contentType, or of the JSON body if contentType is empty.
+func (op *Operation) RequestBodySchema(contentType string) (interface{}, bool) {
+	content, _, ok := op.RequestBody()
+	if !ok {
+		return nil, false
+	}
+	return mediaSchema(content, contentType)
+}
+
+// ResponseBodySchema returns the schema of the body of the response declared
+// for status, as sent with contentType.
+func (op *Operation) ResponseBodySchema(status int, contentType string) (interface{}, bool) {
+	response, ok := op.Response(status)
+	if !ok {
+		return nil, false
+	}
+	return mediaSchema(stringKeys(asMap(response["content"])), contentType)
+}
+
+func mediaSchema(content map[string]interface{}, contentType string) (interface{}, bool) {
+	_, media, ok := SelectMediaType(content, contentType)
+	if !ok {
+		return nil, false
+	}
+	node, ok := asMap(media)["schema"]
+	return node, ok && node != nil
+}
@@ -0,0 +1,709 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Body kinds, by how a media type encodes a body.
+const (
+	bodyJSON      = "json"
+	bodyForm      = "form"
+	bodyMultipart = "multipart"
+	bodyXML       = "xml"
+	bodyText      = "text"
+)
+
+// bodyKind returns how a media type encodes a body, or "" for media types
+// that carry no structured data, such as images.
+func bodyKind(mediaType string) string {
+	mediaType = strings.ToLower(mediaType)
+	switch {
+	case IsJSONMediaType(mediaType):
+		return bodyJSON
+	case mediaType == "application/x-www-form-urlencoded":
+		return bodyForm
+	case mediaType == "multipart/form-data" || mediaType == "multipart/mixed":
+		return bodyMultipart
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return bodyXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return bodyText
+	}
+	return ""
+}
+
+// parseMediaType returns the media type of a Content-Type, lowercased and
+// without parameters.
+func parseMediaType(contentType string) (string, map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])), nil
+	}
+	return mediaType, params
+}
+
+// mediaTypeRank reports how well a declared media type, which may be a
+// wildcard such as application/* or */*, serves a requested one: 4 for the
+// same type, 3 for a structured syntax suffix match (application/vnd.x+json
+// served by application/json), 2 for a subtype wildcard, 1 for */* and 0
+// for no match.
+func mediaTypeRank(declared, requested string) int {
+	declared, _ = parseMediaType(declared)
+	if declared == requested {
+		return 4
+	}
+	if declared == "*/*" || requested == "*/*" {
+		return 1
+	}
+
+	declaredType, declaredSubtype, _ := strings.Cut(declared, "/")
+	requestedType, requestedSubtype, _ := strings.Cut(requested, "/")
+	if declaredType != requestedType {
+		return 0
+	}
+	if declaredSubtype == "*" || requestedSubtype == "*" {
+		return 2
+	}
+	if _, suffix, ok := strings.Cut(requestedSubtype, "+"); ok && suffix == declaredSubtype {
+		return 3
+	}
+	if _, suffix, ok := strings.Cut(declaredSubtype, "+"); ok && suffix == requestedSubtype {
+		return 3
+	}
+	return 0
+}
+
+// validateBody decodes a body sent with contentType, served by the declared
+// mediaType, and validates it against the media type's schema.
+func (s *Spec) validateBody(mediaType, contentType string, media interface{}, body []byte) []Violation {
+	if contentType == "" || strings.Contains(contentType, "*") {
+		contentType = mediaType
+	}
+	value, violations, ok := s.DecodeBody(contentType, media, body)
+	if !ok || len(violations) > 0 && value == nil {
+		return violations
+	}
+	return append(violations, s.Validate(asMap(s.Resolve(media))["schema"], value, "body")...)
+}
+
+// DecodeBody decodes a body sent with contentType into a value that can be
+// validated against the schema of media, the declared media type serving
+// it. JSON, form, multipart and XML bodies become objects; XML honours the
+// schema's xml mappings and form, multipart and XML values are converted to
+// the types their schemas declare. Text bodies are strings. ok is false for
+// media types that carry no structured data. Violation paths are rooted at
+// "body".
+func (s *Spec) DecodeBody(contentType string, media interface{}, body []byte) (value interface{}, violations []Violation, ok bool) {
+	mediaType, params := parseMediaType(contentType)
+	node := asMap(s.Resolve(media))
+	schema := node["schema"]
+
+	switch bodyKind(mediaType) {
+	case bodyJSON:
+		if err := json.Unmarshal(body, &value); err != nil {
+			return nil, []Violation{{Rule: "syntax", Path: "body", Message: fmt.Sprintf("is not valid JSON: %v", err)}}, true
+		}
+		return value, nil, true
+	case bodyForm:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, []Violation{{Rule: "syntax", Path: "body", Message: fmt.Sprintf("is not valid form data: %v", err)}}, true
+		}
+		fields := map[string][]string{}
+		for name, list := range values {
+			fields[name] = list
+		}
+		value, violations = s.decodeFields(schema, fields, nil)
+		return value, violations, true
+	case bodyMultipart:
+		return s.decodeMultipart(node, params["boundary"], body)
+	case bodyXML:
+		value, violations = s.decodeXML(schema, body)
+		return value, violations, true
+	case bodyText:
+		if primaryType(asMap(s.Resolve(schema))) == "" {
+			return string(body), nil, true
+		}
+		value, err := s.CoerceParameter(schema, string(body))
+		if err != nil {
+			return nil, []Violation{{Rule: "type", Path: "body", Message: err.Error()}}, true
+		}
+		return value, nil, true
+	}
+	return nil, nil, false
+}
+
+// decodeFields converts form fields to the types of the object schema's
+// properties. Array properties take every value of their field. Parts that
+// were sent as JSON are decoded already, in decoded.
+func (s *Spec) decodeFields(schema interface{}, fields map[string][]string, decoded map[string][]interface{}) (map[string]interface{}, []Violation) {
+	properties := stringKeys(asMap(mergeAllOf(s, asMap(s.Resolve(schema)), 0)["properties"]))
+	object := map[string]interface{}{}
+	var violations []Violation
+
+	names := make([]string, 0, len(fields)+len(decoded))
+	for name := range fields {
+		names = append(names, name)
+	}
+	for name := range decoded {
+		if _, ok := fields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := asMap(s.Resolve(properties[name]))
+		var values []interface{}
+		for _, raw := range fields[name] {
+			itemSchema := interface{}(property)
+			if primaryType(property) == "array" {
+				itemSchema = property["items"]
+			}
+			itemNode := asMap(s.Resolve(itemSchema))
+			switch primaryType(itemNode) {
+			case "object", "array":
+				var item interface{}
+				if err := json.Unmarshal([]byte(raw), &item); err != nil {
+					violations = append(violations, Violation{Rule: "type", Path: joinPath("body", name), Message: fmt.Sprintf("must be JSON, got %q", raw)})
+					continue
+				}
+				values = append(values, item)
+			default:
+				item, err := s.CoerceParameter(itemNode, raw)
+				if err != nil {
+					violations = append(violations, Violation{Rule: "type", Path: joinPath("body", name), Message: err.Error()})
+					continue
+				}
+				values = append(values, item)
+			}
+		}
+		values = append(values, decoded[name]...)
+
+		switch {
+		case primaryType(property) == "array":
+			object[name] = values
+		case len(values) > 0:
+			object[name] = values[len(values)-1]
+		}
+	}
+	return object, violations
+}
+
+// decodeMultipart decodes multipart/form-data into an object of its parts.
+// Parts sent as JSON are decoded; the others are converted to the types
+// their schemas declare. A part whose Content-Type doesn't match the one its
+// encoding object declares is reported.
+func (s *Spec) decodeMultipart(media map[interface{}]interface{}, boundary string, body []byte) (interface{}, []Violation, bool) {
+	if boundary == "" {
+		return nil, []Violation{{Rule: "syntax", Path: "body", Message: "multipart body has no boundary"}}, true
+	}
+	encoding := stringKeys(asMap(media["encoding"]))
+
+	fields := map[string][]string{}
+	decoded := map[string][]interface{}{}
+	var violations []Violation
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, []Violation{{Rule: "syntax", Path: "body", Message: fmt.Sprintf("is not valid multipart: %v", err)}}, true
+		}
+		name := part.FormName()
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, []Violation{{Rule: "syntax", Path: "body", Message: fmt.Sprintf("is not valid multipart: %v", err)}}, true
+		}
+
+		partType, _ := parseMediaType(part.Header.Get("Content-Type"))
+		if partType == "" {
+			partType = "text/plain"
+		}
+		if declared := asString(asMap(encoding[name])["contentType"]); declared != "" && !partTypeDeclared(declared, partType) {
+			violations = append(violations, Violation{
+				Rule:    "encoding",
+				Path:    joinPath("body", name),
+				Message: fmt.Sprintf("part is sent as %s, expected %s", partType, declared),
+			})
+		}
+
+		if bodyKind(partType) == bodyJSON {
+			var value interface{}
+			if err := json.Unmarshal(data, &value); err != nil {
+				violations = append(violations, Violation{Rule: "syntax", Path: joinPath("body", name), Message: fmt.Sprintf("is not valid JSON: %v", err)})
+				continue
+			}
+			decoded[name] = append(decoded[name], value)
+			continue
+		}
+		fields[name] = append(fields[name], string(data))
+	}
+
+	object, fieldViolations := s.decodeFields(media["schema"], fields, decoded)
+	return object, append(violations, fieldViolations...), true
+}
+
+// partTypeDeclared reports whether a part's media type is one of the
+// comma-separated media types an encoding object declares.
+func partTypeDeclared(declared, partType string) bool {
+	for _, candidate := range strings.Split(declared, ",") {
+		if mediaTypeRank(strings.TrimSpace(candidate), partType) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeBody encodes a value as mediaType, using the schema of media, the
+// declared media type, for XML element names and multipart part types. It
+// returns the body and the Content-Type to send it with, which for
+// multipart bodies carries the boundary. Strings are sent as they are for
+// every media type but JSON.
+func (s *Spec) EncodeBody(mediaType string, media interface{}, value interface{}) ([]byte, string, error) {
+	node := asMap(s.Resolve(media))
+	kind := bodyKind(mediaType)
+
+	if text, ok := value.(string); ok && kind != bodyJSON {
+		return []byte(text), mediaType, nil
+	}
+
+	switch kind {
+	case bodyForm:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("form body must be an object")
+		}
+		form := url.Values{}
+		for name, field := range object {
+			for _, item := range fieldValues(field) {
+				form.Add(name, item)
+			}
+		}
+		return []byte(form.Encode()), mediaType, nil
+	case bodyMultipart:
+		return s.encodeMultipart(node, value)
+	case bodyXML:
+		return s.encodeXML(node["schema"], value, mediaType)
+	case bodyText:
+		return []byte(scalarText(value)), mediaType, nil
+	default:
+		data, err := json.Marshal(value)
+		return data, mediaType, err
+	}
+}
+
+// fieldValues returns the form values of a field: one per array item, with
+// objects sent as JSON.
+func fieldValues(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			data, _ := json.Marshal(item)
+			values = append(values, string(data))
+		default:
+			values = append(values, scalarText(item))
+		}
+	}
+	return values
+}
+
+func (s *Spec) encodeMultipart(media map[interface{}]interface{}, value interface{}) ([]byte, string, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("multipart body must be an object")
+	}
+	encoding := stringKeys(asMap(media["encoding"]))
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range sortedKeys(object) {
+		items, isArray := object[name].([]interface{})
+		if !isArray {
+			items = []interface{}{object[name]}
+		}
+		for _, item := range items {
+			partType := strings.TrimSpace(strings.Split(asString(asMap(encoding[name])["contentType"]), ",")[0])
+			var data []byte
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				if partType == "" {
+					partType = "application/json"
+				}
+				data, _ = json.Marshal(item)
+			default:
+				if partType == "" {
+					partType = "text/plain"
+				}
+				data = []byte(scalarText(item))
+			}
+
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q`, name))
+			header.Set("Content-Type", partType)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			part.Write(data)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// scalarText formats a JSON scalar as text, without exponents for numbers.
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// xmlMapping is a schema's OpenAPI xml object.
+type xmlMapping struct {
+	name      string
+	namespace string
+	attribute bool
+	wrapped   bool
+}
+
+func (s *Spec) xmlMapping(node map[interface{}]interface{}, fallback string) xmlMapping {
+	mapping := asMap(node["xml"])
+	m := xmlMapping{
+		name:      asString(mapping["name"]),
+		namespace: asString(mapping["namespace"]),
+		attribute: asBool(mapping["attribute"]),
+		wrapped:   asBool(mapping["wrapped"]),
+	}
+	if m.name == "" {
+		m.name = fallback
+	}
+	return m
+}
+
+// rootElementName returns the name of a body's root element: the schema's
+// xml name, the name of the component it refers to, or "root".
+func (s *Spec) rootElementName(schema interface{}) string {
+	if name := asString(asMap(asMap(s.Resolve(schema))["xml"])["name"]); name != "" {
+		return name
+	}
+	if ref := asString(asMap(schema)["$ref"]); ref != "" {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	return "root"
+}
+
+// xmlElement is a parsed XML element.
+type xmlElement struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     string
+}
+
+func parseXML(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlElement
+	var root *xmlElement
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+			} else if root == nil {
+				root = element
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// decodeXML decodes an XML body into the value its schema describes.
+func (s *Spec) decodeXML(schema interface{}, body []byte) (interface{}, []Violation) {
+	root, err := parseXML(body)
+	if err != nil {
+		return nil, []Violation{{Rule: "syntax", Path: "body", Message: fmt.Sprintf("is not valid XML: %v", err)}}
+	}
+
+	var violations []Violation
+	node := asMap(s.Resolve(schema))
+	if name := s.rootElementName(schema); schema != nil && name != "root" && root.name.Local != name {
+		violations = append(violations, Violation{Rule: "xml", Path: "body", Message: fmt.Sprintf("root element is <%s>, expected <%s>", root.name.Local, name)})
+	}
+	if namespace := s.xmlMapping(node, "").namespace; namespace != "" && root.name.Space != namespace {
+		violations = append(violations, Violation{Rule: "xml", Path: "body", Message: fmt.Sprintf("root element is in namespace %q, expected %q", root.name.Space, namespace)})
+	}
+
+	value, elementViolations := s.xmlValue(schema, root, "body", 0)
+	return value, append(violations, elementViolations...)
+}
+
+// xmlValue converts an element to the value its schema describes. Without
+// a schema, elements with children become objects and the rest strings.
+func (s *Spec) xmlValue(schema interface{}, element *xmlElement, path string, depth int) (interface{}, []Violation) {
+	node := mergeAllOf(s, asMap(s.Resolve(schema)), depth)
+	if depth > 64 {
+		return nil, nil
+	}
+
+	kind := primaryType(node)
+	if kind == "" && (node["properties"] != nil || (node == nil && len(element.children) > 0)) {
+		kind = "object"
+	}
+
+	switch kind {
+	case "object":
+		return s.xmlObject(node, element, path, depth)
+	case "array":
+		items := asMap(s.Resolve(node["items"]))
+		var values []interface{}
+		var violations []Violation
+		for i, child := range element.children {
+			value, found := s.xmlValue(items, child, fmt.Sprintf("%s[%d]", path, i), depth+1)
+			values = append(values, value)
+			violations = append(violations, found...)
+		}
+		return values, violations
+	default:
+		text := strings.TrimSpace(element.text)
+		for _, attr := range element.attrs {
+			if attr.Name.Local == "nil" && attr.Value == "true" {
+				return nil, nil // xsi:nil
+			}
+		}
+		value, err := s.CoerceParameter(node, text)
+		if err != nil {
+			return nil, []Violation{{Rule: "type", Path: path, Message: err.Error()}}
+		}
+		return value, nil
+	}
+}
+
+func (s *Spec) xmlObject(node map[interface{}]interface{}, element *xmlElement, path string, depth int) (interface{}, []Violation) {
+	properties := stringKeys(asMap(node["properties"]))
+	object := map[string]interface{}{}
+	used := map[*xmlElement]bool{}
+	var violations []Violation
+
+	for _, name := range sortedKeys(properties) {
+		property := mergeAllOf(s, asMap(s.Resolve(properties[name])), depth+1)
+		mapping := s.xmlMapping(property, name)
+		fieldPath := joinPath(path, name)
+
+		if mapping.attribute {
+			for _, attr := range element.attrs {
+				if attr.Name.Local == mapping.name {
+					value, err := s.CoerceParameter(property, attr.Value)
+					if err != nil {
+						violations = append(violations, Violation{Rule: "type", Path: fieldPath, Message: err.Error()})
+						continue
+					}
+					object[name] = value
+				}
+			}
+			continue
+		}
+
+		if primaryType(property) == "array" {
+			items := asMap(s.Resolve(property["items"]))
+			itemName := s.xmlMapping(items, name).name
+			container := element
+			if mapping.wrapped {
+				container = nil
+				for _, child := range element.children {
+					if child.name.Local == mapping.name && !used[child] {
+						container = child
+						used[child] = true
+						break
+					}
+				}
+				if container == nil {
+					continue
+				}
+			}
+
+			values := []interface{}{}
+			for _, child := range container.children {
+				if child.name.Local != itemName || used[child] {
+					continue
+				}
+				used[child] = true
+				value, found := s.xmlValue(items, child, fmt.Sprintf("%s[%d]", fieldPath, len(values)), depth+1)
+				values = append(values, value)
+				violations = append(violations, found...)
+			}
+			if mapping.wrapped || len(values) > 0 {
+				object[name] = values
+			}
+			continue
+		}
+
+		for _, child := range element.children {
+			if child.name.Local == mapping.name && !used[child] {
+				used[child] = true
+				value, found := s.xmlValue(property, child, fieldPath, depth+1)
+				object[name] = value
+				violations = append(violations, found...)
+				break
+			}
+		}
+	}
+
+	// Undeclared elements are kept so that additionalProperties applies.
+	// Repeated ones become arrays.
+	undeclared := map[string][]interface{}{}
+	var names []string
+	for _, child := range element.children {
+		name := child.name.Local
+		if used[child] {
+			continue
+		}
+		if _, ok := object[name]; ok && undeclared[name] == nil {
+			continue
+		}
+		value, found := s.xmlValue(nil, child, joinPath(path, name), depth+1)
+		if undeclared[name] == nil {
+			names = append(names, name)
+		}
+		undeclared[name] = append(undeclared[name], value)
+		violations = append(violations, found...)
+	}
+	for _, name := range names {
+		if values := undeclared[name]; len(values) > 1 {
+			object[name] = values
+		} else {
+			object[name] = values[0]
+		}
+	}
+	return object, violations
+}
+
+// encodeXML encodes a value as an XML document described by schema.
+func (s *Spec) encodeXML(schema interface{}, value interface{}, mediaType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	node := asMap(s.Resolve(schema))
+	mapping := s.xmlMapping(node, s.rootElementName(schema))
+
+	if err := s.writeXML(encoder, node, value, mapping, 0); err != nil {
+		return nil, "", err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mediaType, nil
+}
+
+func (s *Spec) writeXML(encoder *xml.Encoder, node map[interface{}]interface{}, value interface{}, mapping xmlMapping, depth int) error {
+	if depth > 64 {
+		return nil
+	}
+	node = mergeAllOf(s, node, depth)
+	start := xml.StartElement{Name: xml.Name{Space: mapping.namespace, Local: mapping.name}}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := stringKeys(asMap(node["properties"]))
+		var children []string
+		for _, name := range sortedKeys(v) {
+			property := mergeAllOf(s, asMap(s.Resolve(properties[name])), depth+1)
+			if propertyMapping := s.xmlMapping(property, name); propertyMapping.attribute {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: propertyMapping.name}, Value: scalarText(v[name])})
+				continue
+			}
+			children = append(children, name)
+		}
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, name := range children {
+			property := mergeAllOf(s, asMap(s.Resolve(properties[name])), depth+1)
+			propertyMapping := s.xmlMapping(property, name)
+			items, isArray := v[name].([]interface{})
+			if !isArray {
+				if err := s.writeXML(encoder, property, v[name], propertyMapping, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.writeXMLItems(encoder, property, items, propertyMapping, name, depth+1); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case []interface{}:
+		return s.writeXMLItems(encoder, node, v, mapping, mapping.name, depth)
+	default:
+		return encoder.EncodeElement(scalarText(v), start)
+	}
+}
+
+// writeXMLItems writes an array's items, inside a wrapper element if the
+// array's mapping is wrapped.
+func (s *Spec) writeXMLItems(encoder *xml.Encoder, node map[interface{}]interface{}, items []interface{}, mapping xmlMapping, name string, depth int) error {
+	itemNode := asMap(s.Resolve(node["items"]))
+	itemMapping := s.xmlMapping(itemNode, name)
+	wrapper := xml.StartElement{Name: xml.Name{Space: mapping.namespace, Local: mapping.name}}
+	if mapping.wrapped {
+		if err := encoder.EncodeToken(wrapper); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		if err := s.writeXML(encoder, itemNode, item, itemMapping, depth+1); err != nil {
+			return err
+		}
+	}
+	if mapping.wrapped {
+		return encoder.EncodeToken(wrapper.End())
+	}
+	return nil
+}
@@ -48,4 +48,25 @@ func (p *Parser) GetEndpoints() ([]string, error) {
 	}
 	
 	return endpoints, nil
+}
+
+// GetServerURL returns the URL of the first server declared in the schema.
+func (p *Parser) GetServerURL() (string, error) {
+	schema, err := p.Parse()
+	if err != nil {
+		return "", err
+	}
+	
+	servers, _ := schema["servers"].([]interface{})
+	if len(servers) == 0 {
+		return "", fmt.Errorf("schema declares no servers")
+	}
+	
+	server, _ := servers[0].(map[interface{}]interface{})
+	serverURL, _ := server["url"].(string)
+	if serverURL == "" {
+		return "", fmt.Errorf("first server has no url")
+	}
+	
+	return serverURL, nil
 }
\ No newline at end of file
@@ -0,0 +1,941 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kinds of protobuf field types.
+const (
+	ProtoScalar  = "scalar"
+	ProtoMessage = "message"
+	ProtoEnum    = "enum"
+)
+
+// ProtoSpec is the set of messages, enums and services declared by a
+// provider's .proto files. The files are parsed directly, so no protoc binary
+// is needed; proto2 and proto3 syntax are understood, extensions and custom
+// options are skipped.
+type ProtoSpec struct {
+	files    []string
+	messages map[string]*ProtoMessageType
+	enums    map[string]*ProtoEnumType
+	services map[string]*ProtoService
+}
+
+// ProtoMessageType is a message declaration. Name is fully qualified, e.g.
+// "orders.v1.Order".
+type ProtoMessageType struct {
+	Name   string
+	Fields []ProtoField
+}
+
+// ProtoField is one field of a message. For map fields Type is the value
+// type and KeyType the key type. Type is a scalar type name such as "int64"
+// or the fully qualified name of a message or enum, as told by Kind.
+type ProtoField struct {
+	Name     string
+	Number   int
+	Type     string
+	Kind     string
+	Repeated bool
+	Optional bool // declared optional, so presence is tracked
+	Map      bool
+	KeyType  string
+	Oneof    string
+}
+
+// ProtoEnumType is an enum declaration with its values by name.
+type ProtoEnumType struct {
+	Name   string
+	Values map[string]int
+}
+
+// ProtoService is a service declaration.
+type ProtoService struct {
+	Name    string
+	Methods []ProtoMethod
+}
+
+// ProtoMethod is an rpc of a service. Input and Output are fully qualified
+// message names.
+type ProtoMethod struct {
+	Service         string
+	Name            string
+	Input           string
+	Output          string
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+// FullName returns the method's name as written in contracts, e.g.
+// "orders.v1.OrderService/GetOrder".
+func (m ProtoMethod) FullName() string {
+	return m.Service + "/" + m.Name
+}
+
+// Path returns the HTTP/2 path the method is called on.
+func (m ProtoMethod) Path() string {
+	return "/" + m.FullName()
+}
+
+// JSONName returns the field's name in the proto3 JSON mapping, the
+// lowerCamelCase form of its name.
+func (f ProtoField) JSONName() string {
+	var b strings.Builder
+	upper := false
+	for _, r := range f.Name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Cardinality describes how many values the field holds: "map", "repeated"
+// or "singular".
+func (f ProtoField) Cardinality() string {
+	switch {
+	case f.Map:
+		return "map"
+	case f.Repeated:
+		return "repeated"
+	default:
+		return "singular"
+	}
+}
+
+// TypeName describes the field's type the way it is declared, e.g. "int64",
+// "repeated orders.v1.Item" or "map<string, int32>".
+func (f ProtoField) TypeName() string {
+	switch {
+	case f.Map:
+		return fmt.Sprintf("map<%s, %s>", f.KeyType, f.Type)
+	case f.Repeated:
+		return "repeated " + f.Type
+	default:
+		return f.Type
+	}
+}
+
+// LoadProtoSpec parses every .proto file under dir. Imports of files that
+// aren't under dir are ignored, apart from the well-known google.protobuf
+// types, which are always available.
+func LoadProtoSpec(dir string) (*ProtoSpec, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".proto") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proto files: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .proto files found under %s", dir)
+	}
+	return ParseProtoFiles(paths...)
+}
+
+// ParseProtoFiles parses the given .proto files as one set, resolving type
+// references between them.
+func ParseProtoFiles(paths ...string) (*ProtoSpec, error) {
+	spec := &ProtoSpec{
+		messages: map[string]*ProtoMessageType{},
+		enums:    map[string]*ProtoEnumType{},
+		services: map[string]*ProtoService{},
+	}
+	addWellKnownTypes(spec)
+
+	var pending []pendingField
+	var methods []pendingMethod
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proto file: %w", err)
+		}
+		p := &protoParser{spec: spec, tokens: tokenizeProto(string(data))}
+		if err := p.parseFile(); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		spec.files = append(spec.files, path)
+		pending = append(pending, p.fields...)
+		methods = append(methods, p.methods...)
+	}
+
+	for _, field := range pending {
+		message := spec.messages[field.message]
+		f := &message.Fields[field.index]
+		if f.Kind != "" {
+			continue
+		}
+		name, kind, ok := spec.resolveType(field.scope, f.Type)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: unknown type %s", field.message, f.Name, f.Type)
+		}
+		f.Type, f.Kind = name, kind
+	}
+
+	for _, method := range methods {
+		service := spec.services[method.service]
+		m := &service.Methods[method.index]
+		for _, t := range []*string{&m.Input, &m.Output} {
+			name, kind, ok := spec.resolveType(method.scope, *t)
+			if !ok || kind != ProtoMessage {
+				return nil, fmt.Errorf("%s: unknown message type %s", m.FullName(), *t)
+			}
+			*t = name
+		}
+	}
+
+	return spec, nil
+}
+
+// Files returns the parsed .proto files.
+func (p *ProtoSpec) Files() []string {
+	return p.files
+}
+
+// Services returns the declared services in sorted order.
+func (p *ProtoSpec) Services() []*ProtoService {
+	names := make([]string, 0, len(p.services))
+	for name := range p.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	services := make([]*ProtoService, len(names))
+	for i, name := range names {
+		services[i] = p.services[name]
+	}
+	return services
+}
+
+// Method finds an rpc by its fully qualified name, written as
+// "pkg.Service/Method", "/pkg.Service/Method" or "pkg.Service.Method".
+func (p *ProtoSpec) Method(name string) (ProtoMethod, bool) {
+	name = strings.TrimPrefix(name, "/")
+	service, method, ok := strings.Cut(name, "/")
+	if !ok {
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return ProtoMethod{}, false
+		}
+		service, method = name[:i], name[i+1:]
+	}
+
+	if s, ok := p.services[service]; ok {
+		for _, m := range s.Methods {
+			if m.Name == method {
+				return m, true
+			}
+		}
+	}
+	return ProtoMethod{}, false
+}
+
+// MessageType returns a message declaration by its fully qualified name.
+func (p *ProtoSpec) MessageType(name string) (*ProtoMessageType, bool) {
+	message, ok := p.messages[strings.TrimPrefix(name, ".")]
+	return message, ok
+}
+
+// EnumType returns an enum declaration by its fully qualified name.
+func (p *ProtoSpec) EnumType(name string) (*ProtoEnumType, bool) {
+	enum, ok := p.enums[strings.TrimPrefix(name, ".")]
+	return enum, ok
+}
+
+// Field returns the field of a message named by its proto name or its JSON
+// name.
+func (m *ProtoMessageType) Field(name string) (ProtoField, bool) {
+	for _, field := range m.Fields {
+		if field.Name == name || field.JSONName() == name {
+			return field, true
+		}
+	}
+	return ProtoField{}, false
+}
+
+// FieldByNumber returns the field of a message with the given number.
+func (m *ProtoMessageType) FieldByNumber(number int) (ProtoField, bool) {
+	for _, field := range m.Fields {
+		if field.Number == number {
+			return field, true
+		}
+	}
+	return ProtoField{}, false
+}
+
+// resolveType finds the declaration a type reference names, searching the
+// enclosing scopes from the innermost outwards as protoc does.
+func (p *ProtoSpec) resolveType(scope, name string) (string, string, bool) {
+	if protoScalars[name] {
+		return name, ProtoScalar, true
+	}
+
+	lookup := func(full string) (string, string, bool) {
+		if _, ok := p.messages[full]; ok {
+			return full, ProtoMessage, true
+		}
+		if _, ok := p.enums[full]; ok {
+			return full, ProtoEnum, true
+		}
+		return "", "", false
+	}
+
+	if strings.HasPrefix(name, ".") {
+		return lookup(name[1:])
+	}
+	for {
+		candidate := name
+		if scope != "" {
+			candidate = scope + "." + name
+		}
+		if full, kind, ok := lookup(candidate); ok {
+			return full, kind, true
+		}
+		if scope == "" {
+			return "", "", false
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+var protoScalars = map[string]bool{
+	"double": true, "float": true,
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+	"sfixed32": true, "sfixed64": true,
+	"bool": true, "string": true, "bytes": true,
+}
+
+// Well-known types with a special JSON mapping.
+const (
+	protoTimestamp = "google.protobuf.Timestamp"
+	protoDuration  = "google.protobuf.Duration"
+	protoEmpty     = "google.protobuf.Empty"
+	protoStruct    = "google.protobuf.Struct"
+	protoValue     = "google.protobuf.Value"
+	protoListValue = "google.protobuf.ListValue"
+	protoNullValue = "google.protobuf.NullValue"
+	protoAny       = "google.protobuf.Any"
+	protoFieldMask = "google.protobuf.FieldMask"
+)
+
+// protoWrappers maps the wrapper types to the scalar they wrap.
+var protoWrappers = map[string]string{
+	"google.protobuf.DoubleValue": "double",
+	"google.protobuf.FloatValue":  "float",
+	"google.protobuf.Int64Value":  "int64",
+	"google.protobuf.UInt64Value": "uint64",
+	"google.protobuf.Int32Value":  "int32",
+	"google.protobuf.UInt32Value": "uint32",
+	"google.protobuf.BoolValue":   "bool",
+	"google.protobuf.StringValue": "string",
+	"google.protobuf.BytesValue":  "bytes",
+}
+
+// addWellKnownTypes declares the google.protobuf types that .proto files
+// commonly import.
+func addWellKnownTypes(spec *ProtoSpec) {
+	scalar := func(name string, number int, typ string) ProtoField {
+		return ProtoField{Name: name, Number: number, Type: typ, Kind: ProtoScalar}
+	}
+
+	spec.messages[protoTimestamp] = &ProtoMessageType{Name: protoTimestamp, Fields: []ProtoField{scalar("seconds", 1, "int64"), scalar("nanos", 2, "int32")}}
+	spec.messages[protoDuration] = &ProtoMessageType{Name: protoDuration, Fields: []ProtoField{scalar("seconds", 1, "int64"), scalar("nanos", 2, "int32")}}
+	spec.messages[protoEmpty] = &ProtoMessageType{Name: protoEmpty}
+	spec.messages[protoFieldMask] = &ProtoMessageType{Name: protoFieldMask, Fields: []ProtoField{{Name: "paths", Number: 1, Type: "string", Kind: ProtoScalar, Repeated: true}}}
+	spec.messages[protoAny] = &ProtoMessageType{Name: protoAny, Fields: []ProtoField{scalar("type_url", 1, "string"), scalar("value", 2, "bytes")}}
+	spec.enums[protoNullValue] = &ProtoEnumType{Name: protoNullValue, Values: map[string]int{"NULL_VALUE": 0}}
+	spec.messages[protoStruct] = &ProtoMessageType{Name: protoStruct, Fields: []ProtoField{
+		{Name: "fields", Number: 1, Type: protoValue, Kind: ProtoMessage, Map: true, KeyType: "string"},
+	}}
+	spec.messages[protoValue] = &ProtoMessageType{Name: protoValue, Fields: []ProtoField{
+		{Name: "null_value", Number: 1, Type: protoNullValue, Kind: ProtoEnum, Oneof: "kind"},
+		{Name: "number_value", Number: 2, Type: "double", Kind: ProtoScalar, Oneof: "kind"},
+		{Name: "string_value", Number: 3, Type: "string", Kind: ProtoScalar, Oneof: "kind"},
+		{Name: "bool_value", Number: 4, Type: "bool", Kind: ProtoScalar, Oneof: "kind"},
+		{Name: "struct_value", Number: 5, Type: protoStruct, Kind: ProtoMessage, Oneof: "kind"},
+		{Name: "list_value", Number: 6, Type: protoListValue, Kind: ProtoMessage, Oneof: "kind"},
+	}}
+	spec.messages[protoListValue] = &ProtoMessageType{Name: protoListValue, Fields: []ProtoField{
+		{Name: "values", Number: 1, Type: protoValue, Kind: ProtoMessage, Repeated: true},
+	}}
+	for name, typ := range protoWrappers {
+		spec.messages[name] = &ProtoMessageType{Name: name, Fields: []ProtoField{scalar("value", 1, typ)}}
+	}
+}
+
+// pendingField is a field whose type is resolved once every file is parsed.
+type pendingField struct {
+	scope   string
+	message string
+	index   int
+}
+
+// pendingMethod is an rpc whose message types are resolved once every file
+// is parsed.
+type pendingMethod struct {
+	scope   string
+	service string
+	index   int
+}
+
+// protoParser is a recursive-descent parser over the tokens of one file.
+type protoParser struct {
+	spec    *ProtoSpec
+	tokens  []string
+	pos     int
+	pkg     string
+	fields  []pendingField
+	methods []pendingMethod
+}
+
+func (p *protoParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *protoParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *protoParser) expect(want string) error {
+	if got := p.next(); got != want {
+		if got == "" {
+			got = "end of file"
+		}
+		return fmt.Errorf("expected %q, got %q", want, got)
+	}
+	return nil
+}
+
+func (p *protoParser) accept(token string) bool {
+	if p.peek() == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *protoParser) qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (p *protoParser) parseFile() error {
+	for p.pos < len(p.tokens) {
+		switch token := p.next(); token {
+		case ";":
+		case "syntax", "edition":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "package":
+			p.pkg = p.next()
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import", "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "message":
+			if err := p.parseMessage(p.pkg); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(p.pkg); err != nil {
+				return err
+			}
+		case "service":
+			if err := p.parseService(); err != nil {
+				return err
+			}
+		case "extend":
+			p.next()
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected %q", token)
+		}
+	}
+	return nil
+}
+
+func (p *protoParser) parseMessage(scope string) error {
+	name := p.qualify(scope, p.next())
+	if _, exists := p.spec.messages[name]; exists {
+		return fmt.Errorf("message %s is declared more than once", name)
+	}
+	message := &ProtoMessageType{Name: name}
+	p.spec.messages[name] = message
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseMessageBody(message, "")
+}
+
+// parseMessageBody parses fields and nested declarations up to the closing
+// brace. oneof is the name of the enclosing oneof, if any.
+func (p *protoParser) parseMessageBody(message *ProtoMessageType, oneof string) error {
+	for {
+		switch token := p.next(); token {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("unterminated message %s", message.Name)
+		case ";":
+		case "message":
+			if err := p.parseMessage(message.Name); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(message.Name); err != nil {
+				return err
+			}
+		case "oneof":
+			group := p.next()
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(message, group); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "extend":
+			p.next()
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		case "map":
+			if err := p.parseMapField(message, oneof); err != nil {
+				return err
+			}
+		default:
+			field := ProtoField{Oneof: oneof}
+			switch token {
+			case "repeated":
+				field.Repeated = true
+				token = p.next()
+			case "optional":
+				field.Optional = true
+				token = p.next()
+			case "required":
+				token = p.next()
+			}
+			if token == "group" {
+				return fmt.Errorf("%s: groups are not supported", message.Name)
+			}
+			field.Type = token
+			if err := p.parseFieldTail(message, field); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseMapField(message *ProtoMessageType, oneof string) error {
+	field := ProtoField{Map: true, Oneof: oneof}
+	if err := p.expect("<"); err != nil {
+		return err
+	}
+	field.KeyType = p.next()
+	if err := p.expect(","); err != nil {
+		return err
+	}
+	field.Type = p.next()
+	if err := p.expect(">"); err != nil {
+		return err
+	}
+	return p.parseFieldTail(message, field)
+}
+
+// parseFieldTail parses "name = number [options];" and records the field.
+func (p *protoParser) parseFieldTail(message *ProtoMessageType, field ProtoField) error {
+	field.Name = p.next()
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(p.next())
+	if err != nil || number < 1 {
+		return fmt.Errorf("%s.%s: invalid field number", message.Name, field.Name)
+	}
+	field.Number = number
+
+	if p.accept("[") {
+		if err := p.skipUntil("]"); err != nil {
+			return err
+		}
+	}
+	if err := p.expect(";"); err != nil {
+		return err
+	}
+
+	if existing, ok := message.FieldByNumber(number); ok {
+		return fmt.Errorf("%s: fields %s and %s both use number %d", message.Name, existing.Name, field.Name, number)
+	}
+	message.Fields = append(message.Fields, field)
+	p.fields = append(p.fields, pendingField{scope: message.Name, message: message.Name, index: len(message.Fields) - 1})
+	return nil
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	enum := &ProtoEnumType{Name: p.qualify(scope, p.next()), Values: map[string]int{}}
+	p.spec.enums[enum.Name] = enum
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch token := p.next(); token {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("unterminated enum %s", enum.Name)
+		case ";":
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			value := p.next()
+			if value == "-" {
+				value = "-" + p.next()
+			}
+			number, err := strconv.ParseInt(value, 0, 32)
+			if err != nil {
+				return fmt.Errorf("%s.%s: invalid value %q", enum.Name, token, value)
+			}
+			enum.Values[token] = int(number)
+			if p.accept("[") {
+				if err := p.skipUntil("]"); err != nil {
+					return err
+				}
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseService() error {
+	service := &ProtoService{Name: p.qualify(p.pkg, p.next())}
+	p.spec.services[service.Name] = service
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch token := p.next(); token {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("unterminated service %s", service.Name)
+		case ";":
+		case "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "rpc":
+			method := ProtoMethod{Service: service.Name, Name: p.next()}
+			if err := p.expect("("); err != nil {
+				return err
+			}
+			method.ClientStreaming = p.accept("stream")
+			method.Input = p.next()
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+			if err := p.expect("returns"); err != nil {
+				return err
+			}
+			if err := p.expect("("); err != nil {
+				return err
+			}
+			method.ServerStreaming = p.accept("stream")
+			method.Output = p.next()
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+			if p.accept("{") {
+				if err := p.skipUntil("}"); err != nil {
+					return err
+				}
+			} else if err := p.expect(";"); err != nil {
+				return err
+			}
+
+			service.Methods = append(service.Methods, method)
+			p.methods = append(p.methods, pendingMethod{scope: p.pkg, service: service.Name, index: len(service.Methods) - 1})
+		default:
+			return fmt.Errorf("unexpected %q in service %s", token, service.Name)
+		}
+	}
+}
+
+// skipStatement skips to the end of a statement, including any aggregate
+// option value in braces.
+func (p *protoParser) skipStatement() error {
+	for {
+		switch p.next() {
+		case ";":
+			return nil
+		case "{":
+			if err := p.skipUntil("}"); err != nil {
+				return err
+			}
+			if p.peek() != ";" {
+				return nil
+			}
+		case "":
+			return fmt.Errorf("unterminated statement")
+		}
+	}
+}
+
+// skipBlock skips a brace-delimited block starting at the next token.
+func (p *protoParser) skipBlock() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.skipUntil("}")
+}
+
+// skipUntil skips past the matching closing token, honouring nesting.
+func (p *protoParser) skipUntil(closing string) error {
+	opening := map[string]string{"}": "{", "]": "[", ")": "(", ">": "<"}[closing]
+	depth := 1
+	for depth > 0 {
+		switch p.next() {
+		case opening:
+			depth++
+		case closing:
+			depth--
+		case "":
+			return fmt.Errorf("expected %q, got end of file", closing)
+		}
+	}
+	return nil
+}
+
+// tokenizeProto splits a .proto file into identifiers, numbers, string
+// literals and punctuation, dropping comments.
+func tokenizeProto(source string) []string {
+	var tokens []string
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				i = len(source)
+			} else {
+				i += end + 4
+			}
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(source) && source[j] != c {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(source) {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		case isProtoWordByte(c):
+			j := i
+			for j < len(source) && isProtoWordByte(source[j]) {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+// isProtoWordByte reports whether c can be part of an identifier, a dotted
+// type name or a number.
+func isProtoWordByte(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// CompareMessage checks a consumer's copy of a message type, declared in
+// consumer, against the provider's declaration in p. Every field the
+// consumer declares must exist in the provider's message under the same
+// number, with a wire-compatible type and the same cardinality; nested
+// messages and enums are compared too. Violation rules are "missing-field",
+// "type", "cardinality", "enum", and the compatible differences
+// "field-name" and "type-compatible". Paths use the consumer's JSON names.
+func (p *ProtoSpec) CompareMessage(consumer *ProtoSpec, consumerType, providerType, path string) []Violation {
+	c := &protoComparison{consumer: consumer, provider: p, seen: map[string]bool{}}
+	c.message(consumerType, providerType, path)
+	return c.violations
+}
+
+type protoComparison struct {
+	consumer   *ProtoSpec
+	provider   *ProtoSpec
+	seen       map[string]bool
+	violations []Violation
+}
+
+func (c *protoComparison) add(rule, path, format string, args ...interface{}) {
+	c.violations = append(c.violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *protoComparison) message(consumerType, providerType, path string) {
+	key := consumerType + "=" + providerType
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+
+	ours, ok := c.consumer.MessageType(consumerType)
+	if !ok {
+		c.add("missing-field", path, "consumer message %s is not declared", consumerType)
+		return
+	}
+	theirs, ok := c.provider.MessageType(providerType)
+	if !ok {
+		c.add("type", path, "provider message %s is not declared", providerType)
+		return
+	}
+
+	for _, field := range ours.Fields {
+		fieldPath := joinPath(path, field.JSONName())
+		provided, ok := theirs.FieldByNumber(field.Number)
+		if !ok {
+			if renamed, exists := theirs.Field(field.Name); exists {
+				c.add("missing-field", fieldPath, "field %s is number %d, the provider declares it as number %d", field.Name, field.Number, renamed.Number)
+			} else {
+				c.add("missing-field", fieldPath, "provider message %s has no field number %d (%s)", providerType, field.Number, field.Name)
+			}
+			continue
+		}
+
+		if provided.Name != field.Name {
+			c.add("field-name", fieldPath, "field number %d is named %s by the provider, its JSON name differs", field.Number, provided.Name)
+		}
+		if field.Map != provided.Map || field.Repeated != provided.Repeated {
+			c.add("cardinality", fieldPath, "field is %s, the provider declares it %s", field.Cardinality(), provided.Cardinality())
+			continue
+		}
+		if field.Map && field.KeyType != provided.KeyType {
+			c.add("type", fieldPath, "map key is %s, the provider declares %s", field.KeyType, provided.KeyType)
+			continue
+		}
+		c.fieldType(field, provided, fieldPath)
+	}
+}
+
+func (c *protoComparison) fieldType(field, provided ProtoField, path string) {
+	switch {
+	case field.Kind == ProtoMessage && provided.Kind == ProtoMessage:
+		if strings.HasPrefix(provided.Type, "google.protobuf.") {
+			if field.Type != provided.Type {
+				c.add("type", path, "field is %s, the provider declares %s", field.Type, provided.Type)
+			}
+			return
+		}
+		c.message(field.Type, provided.Type, path)
+	case field.Kind == ProtoEnum && provided.Kind == ProtoEnum:
+		c.enum(field.Type, provided.Type, path)
+	case field.Type == provided.Type:
+	case protoWireGroup(field) == protoWireGroup(provided):
+		c.add("type-compatible", path, "field is %s, the provider declares %s; they share a wire encoding, but not every value converts", field.Type, provided.Type)
+	default:
+		c.add("type", path, "field is %s, the provider declares %s", field.Type, provided.Type)
+	}
+}
+
+func (c *protoComparison) enum(consumerType, providerType, path string) {
+	ours, ok := c.consumer.EnumType(consumerType)
+	theirs, found := c.provider.EnumType(providerType)
+	if !ok || !found {
+		return
+	}
+	for _, name := range ours.Names() {
+		number, declared := theirs.Values[name]
+		switch {
+		case !declared:
+			c.add("enum", joinPath(path, name), "enum value %s is not declared by the provider's %s", name, providerType)
+		case number != ours.Values[name]:
+			c.add("enum", joinPath(path, name), "enum value %s is %d, the provider declares %d", name, ours.Values[name], number)
+		}
+	}
+}
+
+// protoWireGroup names the set of types a field's values can be exchanged
+// with on the wire.
+func protoWireGroup(field ProtoField) string {
+	switch {
+	case field.Kind == ProtoEnum:
+		return "varint"
+	case field.Kind == ProtoMessage:
+		return "length-delimited"
+	}
+	switch field.Type {
+	case "int32", "int64", "uint32", "uint64", "bool":
+		return "varint"
+	case "sint32", "sint64":
+		return "zigzag"
+	case "fixed32", "sfixed32":
+		return "fixed32"
+	case "fixed64", "sfixed64":
+		return "fixed64"
+	case "string", "bytes":
+		return "length-delimited"
+	default:
+		return field.Type
+	}
+}
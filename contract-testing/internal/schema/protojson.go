@@ -0,0 +1,562 @@
+package schema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateJSON checks a JSON value against a message type using the proto3
+// JSON mapping: every field must be declared, by its JSON or proto name, and
+// hold a value of a compatible type and cardinality. Violation paths are
+// rooted at path.
+func (p *ProtoSpec) ValidateJSON(message string, value interface{}, path string) []Violation {
+	c := &protoJSON{spec: p}
+	c.message(message, value, path, 0)
+	return c.violations
+}
+
+// NormalizeJSON rewrites a JSON value of a message type into the canonical
+// form DecodeJSON produces: fields keyed by their JSON names, 64-bit integers
+// as strings, enums by name and well-known types in their JSON form. Values
+// that don't fit their field are left as they are.
+func (p *ProtoSpec) NormalizeJSON(message string, value interface{}) interface{} {
+	c := &protoJSON{spec: p}
+	return c.message(message, value, "", 0)
+}
+
+// JSONSchema returns a schema, in the form Spec reads, of the proto3 JSON
+// mapping of a message type, with fields under their JSON names. Recursive
+// messages are described to a limited depth, below which any value fits.
+func (p *ProtoSpec) JSONSchema(message string) interface{} {
+	return p.messageSchema(message, 0)
+}
+
+func (p *ProtoSpec) messageSchema(name string, depth int) map[interface{}]interface{} {
+	if depth > 16 {
+		return map[interface{}]interface{}{}
+	}
+
+	switch name {
+	case protoTimestamp:
+		return map[interface{}]interface{}{"type": "string", "format": "date-time"}
+	case protoDuration, protoFieldMask:
+		return map[interface{}]interface{}{"type": "string"}
+	case protoListValue:
+		return map[interface{}]interface{}{"type": "array"}
+	case protoStruct, protoAny:
+		return map[interface{}]interface{}{"type": "object"}
+	}
+	if scalar, ok := protoWrappers[name]; ok {
+		return protoScalarSchema(scalar)
+	}
+
+	message, ok := p.MessageType(name)
+	if !ok {
+		return map[interface{}]interface{}{}
+	}
+	properties := make(map[interface{}]interface{}, len(message.Fields))
+	for _, field := range message.Fields {
+		properties[field.JSONName()] = p.fieldSchema(field, depth)
+	}
+	return map[interface{}]interface{}{"type": "object", "properties": properties}
+}
+
+func (p *ProtoSpec) fieldSchema(field ProtoField, depth int) map[interface{}]interface{} {
+	var item map[interface{}]interface{}
+	switch field.Kind {
+	case ProtoMessage:
+		item = p.messageSchema(field.Type, depth+1)
+	case ProtoEnum:
+		item = map[interface{}]interface{}{"type": []interface{}{"string", "integer"}}
+	default:
+		item = protoScalarSchema(field.Type)
+	}
+
+	switch {
+	case field.Map:
+		return map[interface{}]interface{}{"type": "object", "additionalProperties": item}
+	case field.Repeated:
+		return map[interface{}]interface{}{"type": "array", "items": item}
+	default:
+		return item
+	}
+}
+
+// protoScalarSchema describes a scalar in JSON. 64-bit integers are written
+// as strings and floats may be "NaN" or "Infinity", but numbers are read too.
+func protoScalarSchema(typ string) map[interface{}]interface{} {
+	switch typ {
+	case "bool":
+		return map[interface{}]interface{}{"type": "boolean"}
+	case "string":
+		return map[interface{}]interface{}{"type": "string"}
+	case "bytes":
+		return map[interface{}]interface{}{"type": "string", "format": "byte"}
+	case "double", "float":
+		return map[interface{}]interface{}{"type": []interface{}{"number", "string"}}
+	}
+	if protoIntegerBits(typ) == 64 {
+		return map[interface{}]interface{}{"type": []interface{}{"integer", "string"}}
+	}
+	return map[interface{}]interface{}{"type": "integer"}
+}
+
+// protoJSON walks a JSON value alongside a message type, collecting
+// violations and building the value's canonical form.
+type protoJSON struct {
+	spec       *ProtoSpec
+	violations []Violation
+}
+
+func (c *protoJSON) add(rule, path, format string, args ...interface{}) {
+	c.violations = append(c.violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *protoJSON) message(name string, value interface{}, path string, depth int) interface{} {
+	if value == nil || depth > 64 {
+		return value
+	}
+
+	switch name {
+	case protoTimestamp:
+		return c.timestamp(value, path)
+	case protoDuration:
+		return c.duration(value, path)
+	case protoFieldMask:
+		if _, ok := value.(string); !ok {
+			c.add("type", path, "must be a string of comma-separated paths, got %s", valueType(value))
+		}
+		return value
+	case protoValue:
+		return value
+	case protoListValue:
+		if _, ok := value.([]interface{}); !ok {
+			c.add("type", path, "must be an array, got %s", valueType(value))
+		}
+		return value
+	case protoStruct:
+		if _, ok := value.(map[string]interface{}); !ok {
+			c.add("type", path, "must be an object, got %s", valueType(value))
+		}
+		return value
+	case protoAny:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.add("type", path, "must be an object, got %s", valueType(value))
+		} else if _, ok := object["@type"].(string); !ok {
+			c.add("type", path, "must name its type in @type")
+		}
+		return value
+	}
+	if scalar, ok := protoWrappers[name]; ok {
+		return c.scalar(scalar, value, path)
+	}
+
+	message, ok := c.spec.MessageType(name)
+	if !ok {
+		return value
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		c.add("type", path, "must be an object (%s), got %s", name, valueType(value))
+		return value
+	}
+
+	normalized := make(map[string]interface{}, len(object))
+	oneofs := map[string]string{}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := joinPath(path, key)
+		field, ok := message.Field(key)
+		if !ok {
+			c.add("unknown-field", fieldPath, "field %s is not declared in %s", key, name)
+			normalized[key] = object[key]
+			continue
+		}
+		if field.Oneof != "" && object[key] != nil {
+			if other, set := oneofs[field.Oneof]; set {
+				c.add("oneof", fieldPath, "fields %s and %s of oneof %s are both set", other, key, field.Oneof)
+			}
+			oneofs[field.Oneof] = key
+		}
+		normalized[field.JSONName()] = c.field(field, object[key], fieldPath, depth)
+	}
+	return normalized
+}
+
+func (c *protoJSON) field(field ProtoField, value interface{}, path string, depth int) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch {
+	case field.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.add("cardinality", path, "must be an object (%s), got %s", field.TypeName(), valueType(value))
+			return value
+		}
+		normalized := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			if _, err := protoMapKey(field.KeyType, key); err != nil {
+				c.add("type", joinPath(path, key), "map key %q is not a valid %s", key, field.KeyType)
+			}
+			normalized[key] = c.single(field, item, joinPath(path, key), depth)
+		}
+		return normalized
+	case field.Repeated:
+		items, ok := value.([]interface{})
+		if !ok {
+			c.add("cardinality", path, "must be an array (%s), got %s", field.TypeName(), valueType(value))
+			return value
+		}
+		normalized := make([]interface{}, len(items))
+		for i, item := range items {
+			normalized[i] = c.single(field, item, fmt.Sprintf("%s[%d]", path, i), depth)
+		}
+		return normalized
+	default:
+		if _, ok := value.([]interface{}); ok && !(field.Kind == ProtoMessage && (field.Type == protoListValue || field.Type == protoValue)) {
+			c.add("cardinality", path, "must be a single %s, got an array", field.Type)
+			return value
+		}
+		return c.single(field, value, path, depth)
+	}
+}
+
+// single checks one value of the field's type, an element of it if the
+// field is repeated or a map.
+func (c *protoJSON) single(field ProtoField, value interface{}, path string, depth int) interface{} {
+	switch field.Kind {
+	case ProtoMessage:
+		return c.message(field.Type, value, path, depth+1)
+	case ProtoEnum:
+		return c.enum(field.Type, value, path)
+	default:
+		return c.scalar(field.Type, value, path)
+	}
+}
+
+func (c *protoJSON) enum(name string, value interface{}, path string) interface{} {
+	enum, ok := c.spec.EnumType(name)
+	if !ok || value == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		if _, ok := enum.Values[v]; !ok {
+			c.add("enum", path, "%q is not a value of %s, expected one of %s", v, name, strings.Join(enum.Names(), ", "))
+		}
+		return v
+	default:
+		n, ok := asFloat(v)
+		if !ok || n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+			c.add("type", path, "must be a value name or number of %s, got %s", name, valueType(value))
+			return value
+		}
+		if valueName, ok := enum.ValueName(int(n)); ok {
+			return valueName
+		}
+		return v // Open enums accept unknown numbers
+	}
+}
+
+func (c *protoJSON) scalar(typ string, value interface{}, path string) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch typ {
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			c.add("type", path, "must be a boolean, got %s", valueType(value))
+		}
+		return value
+	case "string":
+		if _, ok := value.(string); !ok {
+			c.add("type", path, "must be a string, got %s", valueType(value))
+		}
+		return value
+	case "bytes":
+		s, ok := value.(string)
+		if !ok {
+			c.add("type", path, "must be a base64 string, got %s", valueType(value))
+			return value
+		}
+		data, err := decodeProtoBytes(s)
+		if err != nil {
+			c.add("type", path, "must be base64 encoded: %v", err)
+			return value
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	case "double", "float":
+		n, err := protoFloat(typ, value)
+		if err != nil {
+			c.add("type", path, "%v", err)
+			return value
+		}
+		return formatProtoFloat(n)
+	default:
+		bits, err := protoInteger(typ, value)
+		if err != nil {
+			c.add("type", path, "%v", err)
+			return value
+		}
+		return formatProtoInteger(typ, bits)
+	}
+}
+
+func (c *protoJSON) timestamp(value interface{}, path string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		c.add("type", path, "must be an RFC 3339 timestamp string, got %s", valueType(value))
+		return value
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		c.add("format", path, "%q is not an RFC 3339 timestamp", s)
+		return value
+	}
+	return formatTimestamp(t.Unix(), int32(t.Nanosecond()))
+}
+
+func (c *protoJSON) duration(value interface{}, path string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		c.add("type", path, "must be a duration string such as \"1.5s\", got %s", valueType(value))
+		return value
+	}
+	seconds, nanos, err := parseDuration(s)
+	if err != nil {
+		c.add("format", path, "%v", err)
+		return value
+	}
+	return formatDuration(seconds, nanos)
+}
+
+// Names returns the enum's value names ordered by number.
+func (e *ProtoEnumType) Names() []string {
+	names := make([]string, 0, len(e.Values))
+	for name := range e.Values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if e.Values[names[i]] != e.Values[names[j]] {
+			return e.Values[names[i]] < e.Values[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ValueName returns the first value name with the given number.
+func (e *ProtoEnumType) ValueName(number int) (string, bool) {
+	for _, name := range e.Names() {
+		if e.Values[name] == number {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// protoInteger parses a JSON number or numeric string as an integer of the
+// given type and returns its two's-complement bits.
+func protoInteger(typ string, value interface{}) (uint64, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	default:
+		n, ok := asFloat(v)
+		if !ok {
+			return 0, fmt.Errorf("must be an integer (%s), got %s", typ, valueType(value))
+		}
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("must be an integer (%s), got %v", typ, n)
+		}
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	switch typ {
+	case "int32", "sint32", "sfixed32", "int64", "sint64", "sfixed64":
+		n, err := strconv.ParseInt(s, 10, protoIntegerBits(typ))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid %s", s, typ)
+		}
+		return uint64(n), nil
+	case "uint32", "fixed32", "uint64", "fixed64":
+		n, err := strconv.ParseUint(s, 10, protoIntegerBits(typ))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid %s", s, typ)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unknown scalar type %s", typ)
+	}
+}
+
+func protoIntegerBits(typ string) int {
+	if strings.HasSuffix(typ, "64") {
+		return 64
+	}
+	return 32
+}
+
+// formatProtoInteger renders integer bits of the given type in canonical
+// JSON: 64-bit integers as decimal strings, others as numbers.
+func formatProtoInteger(typ string, bits uint64) interface{} {
+	switch typ {
+	case "int64", "sint64", "sfixed64":
+		return strconv.FormatInt(int64(bits), 10)
+	case "uint64", "fixed64":
+		return strconv.FormatUint(bits, 10)
+	case "int32", "sint32", "sfixed32":
+		return float64(int32(bits))
+	default:
+		return float64(uint32(bits))
+	}
+}
+
+// protoFloat parses a JSON number, numeric string or one of "NaN",
+// "Infinity" and "-Infinity" as a double or float.
+func protoFloat(typ string, value interface{}) (float64, error) {
+	var n float64
+	switch v := value.(type) {
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid %s", v, typ)
+		}
+		n = parsed
+	default:
+		parsed, ok := asFloat(v)
+		if !ok {
+			return 0, fmt.Errorf("must be a number (%s), got %s", typ, valueType(value))
+		}
+		n = parsed
+	}
+	if typ == "float" && math.Abs(n) > math.MaxFloat32 {
+		return 0, fmt.Errorf("%v is out of range for float", n)
+	}
+	return n, nil
+}
+
+func formatProtoFloat(n float64) interface{} {
+	switch {
+	case math.IsNaN(n):
+		return "NaN"
+	case math.IsInf(n, 1):
+		return "Infinity"
+	case math.IsInf(n, -1):
+		return "-Infinity"
+	default:
+		return n
+	}
+}
+
+// protoMapKey parses a JSON object key as a map key of the given type.
+func protoMapKey(typ, key string) (interface{}, error) {
+	switch typ {
+	case "string":
+		return key, nil
+	case "bool":
+		switch key {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a valid bool", key)
+	default:
+		return protoInteger(typ, key)
+	}
+}
+
+// decodeProtoBytes accepts standard and URL-safe base64, padded or not.
+func decodeProtoBytes(s string) ([]byte, error) {
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		var data []byte
+		if data, err = encoding.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// formatTimestamp renders a Timestamp as protobuf's JSON mapping does: UTC,
+// with 0, 3, 6 or 9 fractional digits.
+func formatTimestamp(seconds int64, nanos int32) string {
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02T15:04:05") + formatNanos(nanos) + "Z"
+}
+
+func formatDuration(seconds int64, nanos int32) string {
+	sign := ""
+	if seconds < 0 || nanos < 0 {
+		sign = "-"
+		if seconds < 0 {
+			seconds = -seconds
+		}
+		if nanos < 0 {
+			nanos = -nanos
+		}
+	}
+	return fmt.Sprintf("%s%d%ss", sign, seconds, formatNanos(nanos))
+}
+
+func formatNanos(nanos int32) string {
+	switch {
+	case nanos == 0:
+		return ""
+	case nanos%1000000 == 0:
+		return fmt.Sprintf(".%03d", nanos/1000000)
+	case nanos%1000 == 0:
+		return fmt.Sprintf(".%06d", nanos/1000)
+	default:
+		return fmt.Sprintf(".%09d", nanos)
+	}
+}
+
+// parseDuration parses a Duration in its JSON form, e.g. "1.5s" or "-3s".
+func parseDuration(s string) (int64, int32, error) {
+	if !strings.HasSuffix(s, "s") {
+		return 0, 0, fmt.Errorf("%q is not a duration, expected seconds with an \"s\" suffix", s)
+	}
+	number := strings.TrimSuffix(s, "s")
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+
+	whole, fraction, _ := strings.Cut(number, ".")
+	seconds, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || len(fraction) > 9 {
+		return 0, 0, fmt.Errorf("%q is not a valid duration", s)
+	}
+	var nanos int64
+	if fraction != "" {
+		nanos, err = strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%q is not a valid duration", s)
+		}
+	}
+	if negative {
+		seconds, nanos = -seconds, -nanos
+	}
+	return seconds, int32(nanos), nil
+}
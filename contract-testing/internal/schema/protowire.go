@@ -0,0 +1,655 @@
+package schema
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// EncodeJSON encodes a JSON value of a message type in the protobuf binary
+// format. The value is read with the proto3 JSON mapping, so fields may be
+// named by their JSON or proto names. google.protobuf.Any isn't supported.
+func (p *ProtoSpec) EncodeJSON(message string, value interface{}) ([]byte, error) {
+	return p.encodeMessage(message, value, "", 0)
+}
+
+// DecodeJSON decodes a message in the protobuf binary format into the
+// canonical JSON form NormalizeJSON produces. Singular fields without
+// presence are included with their default values, repeated fields as empty
+// arrays and maps as empty objects; unknown fields are dropped.
+func (p *ProtoSpec) DecodeJSON(message string, data []byte) (interface{}, error) {
+	return p.decodeMessage(message, data, 0)
+}
+
+func (p *ProtoSpec) encodeMessage(name string, value interface{}, path string, depth int) ([]byte, error) {
+	if depth > 64 {
+		return nil, fmt.Errorf("%s: message nested too deeply", path)
+	}
+
+	// Well-known types are converted from their JSON form to the fields
+	// they are declared with.
+	switch name {
+	case protoTimestamp:
+		s, _ := value.(string)
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not an RFC 3339 timestamp", path, s)
+		}
+		value = map[string]interface{}{"seconds": float64(t.Unix()), "nanos": float64(t.Nanosecond())}
+	case protoDuration:
+		s, _ := value.(string)
+		seconds, nanos, err := parseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		value = map[string]interface{}{"seconds": fmt.Sprint(seconds), "nanos": float64(nanos)}
+	case protoFieldMask:
+		s, _ := value.(string)
+		var paths []interface{}
+		for _, field := range strings.Split(s, ",") {
+			if field != "" {
+				paths = append(paths, snakeCase(field))
+			}
+		}
+		value = map[string]interface{}{"paths": paths}
+	case protoStruct:
+		value = map[string]interface{}{"fields": value}
+	case protoListValue:
+		value = map[string]interface{}{"values": value}
+	case protoValue:
+		switch v := value.(type) {
+		case nil:
+			value = map[string]interface{}{"null_value": "NULL_VALUE"}
+		case bool:
+			value = map[string]interface{}{"bool_value": v}
+		case string:
+			value = map[string]interface{}{"string_value": v}
+		case []interface{}:
+			value = map[string]interface{}{"list_value": v}
+		case map[string]interface{}:
+			value = map[string]interface{}{"struct_value": v}
+		default:
+			value = map[string]interface{}{"number_value": v}
+		}
+	case protoAny:
+		return nil, fmt.Errorf("%s: google.protobuf.Any is not supported", path)
+	}
+	if _, ok := protoWrappers[name]; ok {
+		value = map[string]interface{}{"value": value}
+	}
+
+	message, ok := p.MessageType(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown message type %s", path, name)
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: must be an object (%s), got %s", path, name, valueType(value))
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := message.Field(keys[i])
+		b, _ := message.Field(keys[j])
+		return a.Number < b.Number
+	})
+
+	var out []byte
+	for _, key := range keys {
+		fieldPath := joinPath(path, key)
+		field, ok := message.Field(key)
+		if !ok {
+			return nil, fmt.Errorf("%s: field is not declared in %s", fieldPath, name)
+		}
+		if object[key] == nil {
+			continue
+		}
+
+		encoded, err := p.encodeField(field, object[key], fieldPath, depth)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, encoded...)
+	}
+	return out, nil
+}
+
+func (p *ProtoSpec) encodeField(field ProtoField, value interface{}, path string, depth int) ([]byte, error) {
+	switch {
+	case field.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an object (%s), got %s", path, field.TypeName(), valueType(value))
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var out []byte
+		for _, key := range keys {
+			keyValue, err := protoMapKey(field.KeyType, key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", joinPath(path, key), err)
+			}
+			if bits, ok := keyValue.(uint64); ok {
+				keyValue = formatProtoInteger(field.KeyType, bits)
+			}
+			keyField := ProtoField{Name: "key", Number: 1, Type: field.KeyType, Kind: ProtoScalar, Optional: true}
+			valueField := ProtoField{Name: "value", Number: 2, Type: field.Type, Kind: field.Kind, Optional: true}
+
+			entry, err := p.encodeSingle(keyField, keyValue, joinPath(path, key), depth)
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err := p.encodeSingle(valueField, object[key], joinPath(path, key), depth)
+			if err != nil {
+				return nil, err
+			}
+			entry = append(entry, encodedValue...)
+			out = appendTag(out, field.Number, wireBytes)
+			out = appendBytes(out, entry)
+		}
+		return out, nil
+	case field.Repeated:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an array (%s), got %s", path, field.TypeName(), valueType(value))
+		}
+		element := field
+		element.Repeated = false
+		element.Optional = true
+
+		if field.Kind != ProtoMessage && field.Type != "string" && field.Type != "bytes" {
+			// Repeated numeric fields are packed.
+			var packed []byte
+			for i, item := range items {
+				encoded, err := p.encodeSingle(element, item, fmt.Sprintf("%s[%d]", path, i), depth)
+				if err != nil {
+					return nil, err
+				}
+				_, n := consumeVarint(encoded)
+				packed = append(packed, encoded[n:]...)
+			}
+			if len(packed) == 0 {
+				return nil, nil
+			}
+			return appendBytes(appendTag(nil, field.Number, wireBytes), packed), nil
+		}
+
+		var out []byte
+		for i, item := range items {
+			encoded, err := p.encodeSingle(element, item, fmt.Sprintf("%s[%d]", path, i), depth)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, encoded...)
+		}
+		return out, nil
+	default:
+		return p.encodeSingle(field, value, path, depth)
+	}
+}
+
+// encodeSingle encodes one value of a field with its tag. Zero values of
+// fields without presence are omitted, as protobuf encoders do.
+func (p *ProtoSpec) encodeSingle(field ProtoField, value interface{}, path string, depth int) ([]byte, error) {
+	present := field.Optional || field.Oneof != ""
+
+	switch field.Kind {
+	case ProtoMessage:
+		encoded, err := p.encodeMessage(field.Type, value, path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(appendTag(nil, field.Number, wireBytes), encoded), nil
+	case ProtoEnum:
+		enum, ok := p.EnumType(field.Type)
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown enum type %s", path, field.Type)
+		}
+		var number int64
+		if name, ok := value.(string); ok {
+			n, declared := enum.Values[name]
+			if !declared {
+				return nil, fmt.Errorf("%s: %q is not a value of %s", path, name, field.Type)
+			}
+			number = int64(n)
+		} else {
+			bits, err := protoInteger("int32", value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			number = int64(int32(bits))
+		}
+		if number == 0 && !present {
+			return nil, nil
+		}
+		return appendVarint(appendTag(nil, field.Number, wireVarint), uint64(number)), nil
+	}
+
+	out := appendTag(nil, field.Number, protoWireType(field.Type))
+	switch field.Type {
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be a boolean, got %s", path, valueType(value))
+		}
+		if !b && !present {
+			return nil, nil
+		}
+		if b {
+			return appendVarint(out, 1), nil
+		}
+		return appendVarint(out, 0), nil
+	case "string", "bytes":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be a string, got %s", path, valueType(value))
+		}
+		data := []byte(s)
+		if field.Type == "bytes" {
+			var err error
+			if data, err = decodeProtoBytes(s); err != nil {
+				return nil, fmt.Errorf("%s: must be base64 encoded: %v", path, err)
+			}
+		}
+		if len(data) == 0 && !present {
+			return nil, nil
+		}
+		return appendBytes(out, data), nil
+	case "double", "float":
+		n, err := protoFloat(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if n == 0 && !math.Signbit(n) && !present {
+			return nil, nil
+		}
+		if field.Type == "float" {
+			return binary.LittleEndian.AppendUint32(out, math.Float32bits(float32(n))), nil
+		}
+		return binary.LittleEndian.AppendUint64(out, math.Float64bits(n)), nil
+	}
+
+	bits, err := protoInteger(field.Type, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if bits == 0 && !present {
+		return nil, nil
+	}
+	switch field.Type {
+	case "sint32":
+		n := int32(bits)
+		return appendVarint(out, uint64(uint32(n<<1)^uint32(n>>31))), nil
+	case "sint64":
+		n := int64(bits)
+		return appendVarint(out, uint64(n<<1)^uint64(n>>63)), nil
+	case "fixed32", "sfixed32":
+		return binary.LittleEndian.AppendUint32(out, uint32(bits)), nil
+	case "fixed64", "sfixed64":
+		return binary.LittleEndian.AppendUint64(out, bits), nil
+	default:
+		return appendVarint(out, bits), nil
+	}
+}
+
+func (p *ProtoSpec) decodeMessage(name string, data []byte, depth int) (interface{}, error) {
+	if depth > 64 {
+		return nil, fmt.Errorf("message nested too deeply")
+	}
+	message, ok := p.MessageType(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %s", name)
+	}
+
+	object := map[string]interface{}{}
+	for len(data) > 0 {
+		tag, n := consumeVarint(data)
+		if n == 0 {
+			return nil, fmt.Errorf("%s: malformed tag", name)
+		}
+		data = data[n:]
+		number, wireType := int(tag>>3), int(tag&7)
+
+		raw, n, err := consumeField(data, wireType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: field %d: %w", name, number, err)
+		}
+		data = data[n:]
+
+		field, ok := message.FieldByNumber(number)
+		if !ok {
+			continue // Unknown fields are skipped
+		}
+		key := field.JSONName()
+
+		switch {
+		case field.Map:
+			entry, err := p.decodeMapEntry(field, raw, depth)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+			}
+			entries, _ := object[key].(map[string]interface{})
+			if entries == nil {
+				entries = map[string]interface{}{}
+			}
+			for k, v := range entry {
+				entries[k] = v
+			}
+			object[key] = entries
+		case field.Repeated:
+			items, _ := object[key].([]interface{})
+			if wireType == wireBytes && field.Kind != ProtoMessage && field.Type != "string" && field.Type != "bytes" {
+				for packed := raw; len(packed) > 0; {
+					value, n, err := consumeField(packed, protoWireType(field.Type))
+					if err != nil {
+						return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+					}
+					packed = packed[n:]
+					decoded, err := p.decodeSingle(field, value, depth)
+					if err != nil {
+						return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+					}
+					items = append(items, decoded)
+				}
+			} else {
+				decoded, err := p.decodeSingle(field, raw, depth)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+				}
+				items = append(items, decoded)
+			}
+			object[key] = items
+		default:
+			decoded, err := p.decodeSingle(field, raw, depth)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+			}
+			if field.Oneof != "" {
+				for _, other := range message.Fields {
+					if other.Oneof == field.Oneof {
+						delete(object, other.JSONName())
+					}
+				}
+			}
+			object[key] = decoded
+		}
+	}
+
+	for _, field := range message.Fields {
+		key := field.JSONName()
+		if _, set := object[key]; set {
+			continue
+		}
+		switch {
+		case field.Map:
+			object[key] = map[string]interface{}{}
+		case field.Repeated:
+			object[key] = []interface{}{}
+		case field.Optional || field.Oneof != "" || field.Kind == ProtoMessage:
+		default:
+			object[key] = p.defaultValue(field)
+		}
+	}
+
+	return p.wellKnownJSON(name, object), nil
+}
+
+// wellKnownJSON converts a decoded well-known type into its JSON form.
+func (p *ProtoSpec) wellKnownJSON(name string, object map[string]interface{}) interface{} {
+	switch name {
+	case protoTimestamp, protoDuration:
+		bits, _ := protoInteger("int64", object["seconds"])
+		nanos, _ := asFloat(object["nanos"])
+		if name == protoTimestamp {
+			return formatTimestamp(int64(bits), int32(nanos))
+		}
+		return formatDuration(int64(bits), int32(nanos))
+	case protoFieldMask:
+		var paths []string
+		for _, path := range object["paths"].([]interface{}) {
+			paths = append(paths, ProtoField{Name: fmt.Sprint(path)}.JSONName())
+		}
+		return strings.Join(paths, ",")
+	case protoStruct:
+		return object["fields"]
+	case protoListValue:
+		return object["values"]
+	case protoValue:
+		for _, key := range []string{"numberValue", "stringValue", "boolValue", "structValue", "listValue"} {
+			if value, ok := object[key]; ok {
+				return value
+			}
+		}
+		return nil
+	case protoAny:
+		return map[string]interface{}{"@type": object["typeUrl"], "value": object["value"]}
+	}
+	if _, ok := protoWrappers[name]; ok {
+		return object["value"]
+	}
+	return object
+}
+
+func (p *ProtoSpec) decodeMapEntry(field ProtoField, data []byte, depth int) (map[string]interface{}, error) {
+	keyField := ProtoField{Name: "key", Number: 1, Type: field.KeyType, Kind: ProtoScalar}
+	valueField := ProtoField{Name: "value", Number: 2, Type: field.Type, Kind: field.Kind}
+
+	key := p.defaultValue(keyField)
+	var value interface{}
+	if field.Kind != ProtoMessage {
+		value = p.defaultValue(valueField)
+	}
+	for len(data) > 0 {
+		tag, n := consumeVarint(data)
+		if n == 0 {
+			return nil, fmt.Errorf("malformed map entry")
+		}
+		data = data[n:]
+		raw, n, err := consumeField(data, int(tag&7))
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		switch tag >> 3 {
+		case 1:
+			if key, err = p.decodeSingle(keyField, raw, depth); err != nil {
+				return nil, err
+			}
+		case 2:
+			if value, err = p.decodeSingle(valueField, raw, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if value == nil && field.Kind == ProtoMessage {
+		value = map[string]interface{}{}
+	}
+	return map[string]interface{}{fmt.Sprint(key): value}, nil
+}
+
+// decodeSingle decodes one value of a field from the bytes consumeField
+// returned for it.
+func (p *ProtoSpec) decodeSingle(field ProtoField, raw []byte, depth int) (interface{}, error) {
+	switch field.Kind {
+	case ProtoMessage:
+		return p.decodeMessage(field.Type, raw, depth+1)
+	case ProtoEnum:
+		bits, _ := consumeVarint(raw)
+		number := int(int32(bits))
+		if enum, ok := p.EnumType(field.Type); ok {
+			if name, ok := enum.ValueName(number); ok {
+				if field.Type == protoNullValue {
+					return nil, nil
+				}
+				return name, nil
+			}
+		}
+		return float64(number), nil
+	}
+
+	switch field.Type {
+	case "string":
+		return string(raw), nil
+	case "bytes":
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case "double":
+		if len(raw) != 8 {
+			return nil, fmt.Errorf("malformed double")
+		}
+		return formatProtoFloat(math.Float64frombits(binary.LittleEndian.Uint64(raw))), nil
+	case "float":
+		if len(raw) != 4 {
+			return nil, fmt.Errorf("malformed float")
+		}
+		return formatProtoFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(raw)))), nil
+	case "fixed32", "sfixed32":
+		if len(raw) != 4 {
+			return nil, fmt.Errorf("malformed %s", field.Type)
+		}
+		bits := uint64(binary.LittleEndian.Uint32(raw))
+		if field.Type == "sfixed32" {
+			bits = uint64(int32(bits))
+		}
+		return formatProtoInteger(field.Type, bits), nil
+	case "fixed64", "sfixed64":
+		if len(raw) != 8 {
+			return nil, fmt.Errorf("malformed %s", field.Type)
+		}
+		return formatProtoInteger(field.Type, binary.LittleEndian.Uint64(raw)), nil
+	}
+
+	bits, n := consumeVarint(raw)
+	if n == 0 {
+		return nil, fmt.Errorf("malformed varint")
+	}
+	switch field.Type {
+	case "bool":
+		return bits != 0, nil
+	case "sint32", "sint64":
+		bits = uint64(int64(bits>>1) ^ -int64(bits&1))
+	}
+	return formatProtoInteger(field.Type, bits), nil
+}
+
+// defaultValue is the JSON value of a field that isn't set.
+func (p *ProtoSpec) defaultValue(field ProtoField) interface{} {
+	switch field.Kind {
+	case ProtoEnum:
+		if enum, ok := p.EnumType(field.Type); ok {
+			if name, ok := enum.ValueName(0); ok {
+				return name
+			}
+		}
+		return float64(0)
+	case ProtoMessage:
+		return nil
+	}
+	switch field.Type {
+	case "bool":
+		return false
+	case "string", "bytes":
+		return ""
+	case "double", "float":
+		return float64(0)
+	default:
+		return formatProtoInteger(field.Type, 0)
+	}
+}
+
+func protoWireType(typ string) int {
+	switch typ {
+	case "double", "fixed64", "sfixed64":
+		return wireFixed64
+	case "float", "fixed32", "sfixed32":
+		return wireFixed32
+	case "string", "bytes":
+		return wireBytes
+	default:
+		return wireVarint
+	}
+}
+
+func appendTag(out []byte, number, wireType int) []byte {
+	return appendVarint(out, uint64(number)<<3|uint64(wireType))
+}
+
+func appendVarint(out []byte, v uint64) []byte {
+	return binary.AppendUvarint(out, v)
+}
+
+func appendBytes(out, data []byte) []byte {
+	return append(appendVarint(out, uint64(len(data))), data...)
+}
+
+// consumeVarint reads a varint; n is 0 if data doesn't start with one.
+func consumeVarint(data []byte) (uint64, int) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, 0
+	}
+	return v, n
+}
+
+// consumeField reads the value of a field of the given wire type. For varints
+// and fixed-width values raw holds their bytes; for length-delimited values
+// it holds the contents without the length.
+func consumeField(data []byte, wireType int) (raw []byte, n int, err error) {
+	switch wireType {
+	case wireVarint:
+		_, n := consumeVarint(data)
+		if n == 0 {
+			return nil, 0, fmt.Errorf("malformed varint")
+		}
+		return data[:n], n, nil
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("truncated fixed64")
+		}
+		return data[:8], 8, nil
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("truncated fixed32")
+		}
+		return data[:4], 4, nil
+	case wireBytes:
+		length, n := consumeVarint(data)
+		if n == 0 || uint64(len(data)-n) < length {
+			return nil, 0, fmt.Errorf("truncated length-delimited value")
+		}
+		return data[n : n+int(length)], n + int(length), nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported wire type %d", wireType)
+	}
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,188 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RequestData holds the parts of an HTTP request that a contract constrains.
+type RequestData struct {
+	PathParams map[string]string
+	Query      url.Values
+	Header     http.Header
+	Body       []byte
+}
+
+// ValidateRequest checks a request against the operation's parameters and
+// request body. Violation paths are rooted at the parameter location ("path",
+// "query", "header") or at "body".
+func (op *Operation) ValidateRequest(req RequestData) []Violation {
+	var violations []Violation
+
+	for _, param := range op.Parameters() {
+		if param.In == "header" && ignoredHeader(param.Name) {
+			continue
+		}
+		raw, present := parameterValue(param, req)
+		violations = append(violations, op.spec.validateParameter(param, raw, present)...)
+	}
+
+	violations = append(violations, op.ValidateRequestBody(req)...)
+	return violations
+}
+
+// ValidateRequestHeaders checks only the request's header parameters:
+// required headers must be present and values must match their schemas,
+// with names compared case-insensitively.
+func (op *Operation) ValidateRequestHeaders(header http.Header) []Violation {
+	if header == nil {
+		header = http.Header{}
+	}
+	var violations []Violation
+	for _, param := range op.Parameters() {
+		if param.In != "header" || ignoredHeader(param.Name) {
+			continue
+		}
+		raw, present := parameterValue(param, RequestData{Header: header})
+		violations = append(violations, op.spec.validateParameter(param, raw, present)...)
+	}
+	return violations
+}
+
+// ignoredHeader reports whether OpenAPI ignores a header parameter of this
+// name, as the request body, media types and security schemes describe it.
+func ignoredHeader(name string) bool {
+	return strings.EqualFold(name, "Accept") || strings.EqualFold(name, "Content-Type") || strings.EqualFold(name, "Authorization")
+}
+
+// validateParameter checks a parameter's raw value, present or not,
+// against its declaration. Violation paths are "<in>.<name>".
+func (s *Spec) validateParameter(param Parameter, raw string, present bool) []Violation {
+	path := param.In + "." + param.Name
+	if !present {
+		if param.Required {
+			return []Violation{{Rule: "required", Path: path, Message: "is required"}}
+		}
+		return nil
+	}
+	if param.Schema == nil {
+		return nil
+	}
+
+	value, err := s.CoerceParameter(param.Schema, raw)
+	if err != nil {
+		return []Violation{{Rule: "type", Path: path, Message: err.Error()}}
+	}
+	return s.Validate(param.Schema, value, path)
+}
+
+// ValidateRequestBody checks only the request body: that it is present when
+// required, that its Content-Type is declared and that the body, decoded as
+// its media type, matches the declared schema.
+func (op *Operation) ValidateRequestBody(req RequestData) []Violation {
+	content, required, declared := op.RequestBody()
+	hasBody := len(strings.TrimSpace(string(req.Body))) > 0
+
+	if !declared || len(content) == 0 {
+		return nil
+	}
+	if !hasBody {
+		if required {
+			return []Violation{{Rule: "required", Path: "body", Message: "request body is required"}}
+		}
+		return nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, media, ok := SelectMediaType(content, contentType)
+	if !ok {
+		return []Violation{{
+			Rule:    "mediaType",
+			Path:    "header.Content-Type",
+			Message: fmt.Sprintf("%q is not accepted, expected one of %s", contentType, strings.Join(sortedMediaTypes(content), ", ")),
+		}}
+	}
+
+	return op.spec.validateBody(mediaType, contentType, media, req.Body)
+}
+
+func parameterValue(param Parameter, req RequestData) (string, bool) {
+	switch param.In {
+	case "path":
+		value, ok := req.PathParams[param.Name]
+		return value, ok
+	case "query":
+		values, ok := req.Query[param.Name]
+		if !ok || len(values) == 0 {
+			return "", false
+		}
+		return strings.Join(values, ","), true
+	case "header":
+		values := req.Header.Values(param.Name)
+		if len(values) == 0 {
+			return "", false
+		}
+		return strings.Join(values, ","), true
+	case "cookie":
+		for _, cookie := range (&http.Request{Header: req.Header}).Cookies() {
+			if cookie.Name == param.Name {
+				return cookie.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SelectMediaType picks the declared media type that serves a Content-Type:
+// the same type, else one matching through a structured syntax suffix
+// (application/problem+json served by application/json, or the reverse),
+// else a wildcard such as application/* or */*. An empty Content-Type
+// selects application/json if declared, else another JSON media type, else
+// the first declared media type.
+func SelectMediaType(content map[string]interface{}, contentType string) (string, interface{}, bool) {
+	types := sortedMediaTypes(content)
+	if contentType == "" {
+		if media, ok := content["application/json"]; ok {
+			return "application/json", media, true
+		}
+		for _, declared := range types {
+			if IsJSONMediaType(declared) {
+				return declared, content[declared], true
+			}
+		}
+		if len(types) == 0 {
+			return "", nil, false
+		}
+		return types[0], content[types[0]], true
+	}
+
+	requested, _ := parseMediaType(contentType)
+	best, bestRank := "", 0
+	for _, declared := range types {
+		if rank := mediaTypeRank(declared, requested); rank > bestRank {
+			best, bestRank = declared, rank
+		}
+	}
+	if bestRank == 0 {
+		return "", nil, false
+	}
+	return best, content[best], true
+}
+
+// IsJSONMediaType reports whether a media type carries JSON.
+func IsJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func sortedMediaTypes(content map[string]interface{}) []string {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	return types
+}
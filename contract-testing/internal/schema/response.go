@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseData holds the parts of an HTTP response that a contract constrains.
+type ResponseData struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Response returns the response object declared for a status code, as
+// resolved by ResponseKey.
+func (op *Operation) Response(status int) (map[interface{}]interface{}, bool) {
+	key, ok := op.ResponseKey(status)
+	if !ok {
+		return nil, false
+	}
+	return op.Responses()[key], true
+}
+
+// ResponseKey returns the key of the response declared for a status code:
+// the code itself, else its range such as "4XX", else "default".
+func (op *Operation) ResponseKey(status int) (string, bool) {
+	responses := op.Responses()
+	if _, ok := responses[strconv.Itoa(status)]; ok {
+		return strconv.Itoa(status), true
+	}
+	for key := range responses {
+		if IsStatusRange(key) && StatusInRange(status, key) {
+			return key, true
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return "default", true
+	}
+	return "", false
+}
+
+// IsStatusRange reports whether key is a status code range such as "2XX".
+// The X may be lower case.
+func IsStatusRange(key string) bool {
+	return len(key) == 3 && key[0] >= '1' && key[0] <= '5' && strings.EqualFold(key[1:], "XX")
+}
+
+// StatusInRange reports whether status falls in a range such as "2XX".
+func StatusInRange(status int, statusRange string) bool {
+	return IsStatusRange(statusRange) && status/100 == int(statusRange[0]-'0')
+}
+
+// ValidateResponse checks a response against the operation: its status must
+// be declared, its headers must match the response's header objects and its
+// body, decoded as its media type, must match the declared schema. Violation
+// paths are rooted at "status", "header" or "body".
+func (op *Operation) ValidateResponse(resp ResponseData) []Violation {
+	if _, ok := op.Response(resp.Status); !ok {
+		return []Violation{{Rule: "status", Path: "status", Message: fmt.Sprintf("%d is not a declared response", resp.Status)}}
+	}
+	violations := op.ValidateResponseHeaders(resp)
+	return append(violations, op.ValidateResponseBody(resp)...)
+}
+
+// ResponseHeaders returns the headers declared on the response for status,
+// as header parameters. Content-Type is left out: OpenAPI ignores it there,
+// as the response's content declares the media types.
+func (op *Operation) ResponseHeaders(status int) []Parameter {
+	response, ok := op.Response(status)
+	if !ok {
+		return nil
+	}
+	headers := stringKeys(asMap(response["headers"]))
+
+	var params []Parameter
+	for _, name := range sortedKeys(headers) {
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		node := asMap(op.spec.Resolve(headers[name]))
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "header",
+			Required: asBool(node["required"]),
+			Schema:   node["schema"],
+		})
+	}
+	return params
+}
+
+// ValidateResponseHeaders checks only the response's headers: required
+// headers must be present and values must match their schemas, with names
+// compared case-insensitively. A Content-Type must be one of the media
+// types the response declares.
+func (op *Operation) ValidateResponseHeaders(resp ResponseData) []Violation {
+	response, ok := op.Response(resp.Status)
+	if !ok {
+		return nil
+	}
+	header := resp.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	var violations []Violation
+	for _, param := range op.ResponseHeaders(resp.Status) {
+		raw, present := parameterValue(param, RequestData{Header: header})
+		violations = append(violations, op.spec.validateParameter(param, raw, present)...)
+	}
+
+	contentType := header.Get("Content-Type")
+	content := stringKeys(asMap(response["content"]))
+	hasBody := len(strings.TrimSpace(string(resp.Body))) > 0
+	switch {
+	case contentType == "":
+	case len(content) == 0 && hasBody:
+		violations = append(violations, Violation{
+			Rule:    "mediaType",
+			Path:    "header.Content-Type",
+			Message: fmt.Sprintf("%q is not declared, the response declares no content", contentType),
+		})
+	case len(content) > 0:
+		if _, _, ok := SelectMediaType(content, contentType); !ok {
+			violations = append(violations, Violation{
+				Rule:    "mediaType",
+				Path:    "header.Content-Type",
+				Message: fmt.Sprintf("%q is not declared, expected one of %s", contentType, strings.Join(sortedMediaTypes(content), ", ")),
+			})
+		}
+	}
+	return violations
+}
+
+// ValidateResponseBody checks only the response's body, decoded as the
+// media type its Content-Type selects, against the declared schema. Bodies
+// of undeclared media types are left to ValidateResponseHeaders.
+func (op *Operation) ValidateResponseBody(resp ResponseData) []Violation {
+	response, ok := op.Response(resp.Status)
+	if !ok {
+		return nil
+	}
+	content := stringKeys(asMap(response["content"]))
+	if len(content) == 0 || len(strings.TrimSpace(string(resp.Body))) == 0 {
+		return nil
+	}
+
+	contentType := ""
+	if resp.Header != nil {
+		contentType = resp.Header.Get("Content-Type")
+	}
+	mediaType, media, ok := SelectMediaType(content, contentType)
+	if !ok {
+		return nil
+	}
+	return op.spec.validateBody(mediaType, contentType, media, resp.Body)
+}
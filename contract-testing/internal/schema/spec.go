@@ -0,0 +1,372 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Spec is a parsed OpenAPI document with helpers for looking up operations,
+// resolving references and validating values against its schemas.
+type Spec struct {
+	doc map[string]interface{}
+}
+
+// Operation is one HTTP method on one path template of a Spec.
+type Operation struct {
+	Method   string // lower case, as in the document
+	Path     string // path template, e.g. /orders/{orderId}
+	Node     map[interface{}]interface{}
+	pathItem map[interface{}]interface{}
+	spec     *Spec
+}
+
+// Parameter is a resolved operation parameter.
+type Parameter struct {
+	Name     string
+	In       string // "path", "query", "header", "cookie"
+	Required bool
+	Schema   interface{}
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// LoadSpec parses the OpenAPI document at path.
+func LoadSpec(path string) (*Spec, error) {
+	doc, err := NewParser(path).Parse()
+	if err != nil {
+		return nil, err
+	}
+	return NewSpec(doc), nil
+}
+
+// NewSpec wraps an already parsed OpenAPI document.
+func NewSpec(doc map[string]interface{}) *Spec {
+	return &Spec{doc: doc}
+}
+
+// Document returns the raw parsed document.
+func (s *Spec) Document() map[string]interface{} {
+	return s.doc
+}
+
+// Title returns the document's info.title.
+func (s *Spec) Title() string {
+	return asString(asMap(s.doc["info"])["title"])
+}
+
+// BasePaths returns the path components of the document's server URLs,
+// e.g. "/api/v1" for https://example.com/api/v1. Root paths are left out.
+func (s *Spec) BasePaths() []string {
+	var paths []string
+	for _, server := range asSlice(s.doc["servers"]) {
+		parsed, err := url.Parse(asString(asMap(server)["url"]))
+		if err != nil {
+			continue
+		}
+		if path := strings.TrimSuffix(parsed.Path, "/"); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Operations returns every operation in the document, ordered by path and method.
+func (s *Spec) Operations() []Operation {
+	paths := asMap(s.doc["paths"])
+
+	var templates []string
+	for path := range paths {
+		templates = append(templates, fmt.Sprintf("%v", path))
+	}
+	sort.Strings(templates)
+
+	var operations []Operation
+	for _, template := range templates {
+		pathItem := asMap(s.Resolve(paths[template]))
+		for _, method := range httpMethods {
+			if node, ok := pathItem[method]; ok {
+				operations = append(operations, Operation{
+					Method:   method,
+					Path:     template,
+					Node:     asMap(s.Resolve(node)),
+					pathItem: pathItem,
+					spec:     s,
+				})
+			}
+		}
+	}
+
+	return operations
+}
+
+// Operation returns the operation declared for a path template and method.
+func (s *Spec) Operation(method, template string) (*Operation, bool) {
+	method = strings.ToLower(method)
+	for _, op := range s.Operations() {
+		if op.Path == template && op.Method == method {
+			return &op, true
+		}
+	}
+	return nil, false
+}
+
+// FindOperation matches a concrete request path against the document's path
+// templates and returns the operation for method together with the path
+// parameter values. Templates with fewer parameters win, so /orders/latest is
+// preferred over /orders/{orderId}. pathFound reports whether any template
+// matched the path, which distinguishes unknown paths from unsupported methods.
+func (s *Spec) FindOperation(method, path string) (op *Operation, params map[string]string, pathFound bool) {
+	method = strings.ToLower(method)
+	bestParams := -1
+
+	for _, candidate := range s.Operations() {
+		values, ok := MatchPath(candidate.Path, path)
+		if !ok {
+			continue
+		}
+		pathFound = true
+
+		if candidate.Method != method {
+			continue
+		}
+		if bestParams == -1 || len(values) < bestParams {
+			found := candidate
+			op, params, bestParams = &found, values, len(values)
+		}
+	}
+
+	return op, params, pathFound
+}
+
+// MatchPath matches a concrete path against an OpenAPI path template and
+// returns the values of the template's parameters.
+func MatchPath(template, path string) (map[string]string, bool) {
+	pattern, names := compilePathTemplate(template)
+	matches := pattern.FindStringSubmatch(path)
+	if matches == nil {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(names))
+	for i, name := range names {
+		value, err := url.PathUnescape(matches[i+1])
+		if err != nil {
+			value = matches[i+1]
+		}
+		params[name] = value
+	}
+	return params, true
+}
+
+var templateParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+func compilePathTemplate(template string) (*regexp.Regexp, []string) {
+	var names []string
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range templateParam.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		pattern.WriteString("([^/]+)")
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("/?$")
+
+	return regexp.MustCompile(pattern.String()), names
+}
+
+// Resolve follows local $ref pointers (#/components/...) until it reaches a
+// node without one. Unresolvable references return the node unchanged.
+func (s *Spec) Resolve(node interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := asMap(node)["$ref"].(string)
+		if !ok {
+			return node
+		}
+
+		target, ok := s.lookupRef(ref)
+		if !ok {
+			return node
+		}
+		node = target
+	}
+	return node
+}
+
+func (s *Spec) lookupRef(ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+
+	var node interface{} = s.doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch current := node.(type) {
+		case map[string]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return nil, false
+			}
+			node = next
+		case map[interface{}]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return nil, false
+			}
+			node = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+			node = current[index]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// String formats the operation as "METHOD /path".
+func (op *Operation) String() string {
+	return strings.ToUpper(op.Method) + " " + op.Path
+}
+
+// Parameters returns the operation's parameters, including those declared on
+// its path item. Operation-level parameters override path-level ones.
+func (op *Operation) Parameters() []Parameter {
+	byKey := make(map[string]Parameter)
+	var order []string
+
+	for _, source := range []interface{}{op.pathItem["parameters"], op.Node["parameters"]} {
+		for _, raw := range asSlice(source) {
+			node := asMap(op.spec.Resolve(raw))
+			param := Parameter{
+				Name:     asString(node["name"]),
+				In:       asString(node["in"]),
+				Required: asBool(node["required"]),
+				Schema:   node["schema"],
+			}
+			if param.In == "path" {
+				param.Required = true
+			}
+
+			key := param.In + ":" + strings.ToLower(param.Name)
+			if _, seen := byKey[key]; !seen {
+				order = append(order, key)
+			}
+			byKey[key] = param
+		}
+	}
+
+	params := make([]Parameter, 0, len(order))
+	for _, key := range order {
+		params = append(params, byKey[key])
+	}
+	return params
+}
+
+// RequestBody returns the operation's request body content by media type and
+// whether the body is required.
+func (op *Operation) RequestBody() (content map[string]interface{}, required bool, ok bool) {
+	body := asMap(op.spec.Resolve(op.Node["requestBody"]))
+	if body == nil {
+		return nil, false, false
+	}
+	return stringKeys(asMap(body["content"])), asBool(body["required"]), true
+}
+
+// Responses returns the operation's responses keyed by status code, with
+// references resolved.
+func (op *Operation) Responses() map[string]map[interface{}]interface{} {
+	responses := make(map[string]map[interface{}]interface{})
+	for code, response := range asMap(op.Node["responses"]) {
+		responses[fmt.Sprintf("%v", code)] = asMap(op.spec.Resolve(response))
+	}
+	return responses
+}
+
+// Spec returns the document the operation belongs to.
+func (op *Operation) Spec() *Spec {
+	return op.spec
+}
+
+func stringKeys(m map[interface{}]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[fmt.Sprintf("%v", key)] = value
+	}
+	return result
+}
+
+// asMap returns YAML and JSON objects as a map[interface{}]interface{}, the
+// shape yaml.v2 produces, and nil for anything else.
+func asMap(value interface{}) map[interface{}]interface{} {
+	switch m := value.(type) {
+	case map[interface{}]interface{}:
+		return m
+	case map[string]interface{}:
+		result := make(map[interface{}]interface{}, len(m))
+		for key, value := range m {
+			result[key] = value
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func asSlice(value interface{}) []interface{} {
+	switch s := value.(type) {
+	case []interface{}:
+		return s
+	case []string:
+		result := make([]interface{}, len(s))
+		for i, item := range s {
+			result[i] = item
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func asString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func asBool(value interface{}) bool {
+	b, _ := value.(bool)
+	return b
+}
+
+func asFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,531 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Violation is one way a value breaks a schema. Rule names the schema keyword
+// that was violated, e.g. "required", "type" or "enum".
+type Violation struct {
+	Rule    string `json:"rule"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	patternMu   sync.Mutex
+	patterns    = map[string]*regexp.Regexp{}
+)
+
+// Validate checks a decoded JSON value against a schema node of the document
+// and returns every violation, with paths rooted at path.
+func (s *Spec) Validate(schema interface{}, value interface{}, path string) []Violation {
+	return s.validate(schema, value, path, 0)
+}
+
+func (s *Spec) validate(schema interface{}, value interface{}, path string, depth int) []Violation {
+	node := asMap(s.Resolve(schema))
+	if node == nil || depth > 64 {
+		return nil
+	}
+
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if asBool(node["nullable"]) || typeAllows(node, "null") || len(node) == 0 {
+			return nil
+		}
+		if _, typed := node["type"]; typed {
+			add("type", "must be %s, got null", describeTypes(node))
+			return violations
+		}
+	}
+
+	if _, typed := node["type"]; typed && value != nil && !matchesType(node, value) {
+		add("type", "must be %s, got %s", describeTypes(node), valueType(value))
+		return violations
+	}
+
+	if enum := asSlice(node["enum"]); enum != nil && !containsValue(enum, value) {
+		add("enum", "must be one of %s, got %s", formatValues(enum), formatValue(value))
+	}
+	if constant, ok := node["const"]; ok && !equalValues(constant, value) {
+		add("const", "must be %s, got %s", formatValue(constant), formatValue(value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		violations = append(violations, s.validateString(node, v, path)...)
+	case float64, int, int64:
+		n, _ := asFloat(v)
+		violations = append(violations, validateNumber(node, n, path)...)
+	case []interface{}:
+		if min, ok := asFloat(node["minItems"]); ok && float64(len(v)) < min {
+			add("minItems", "must have at least %v items, got %d", min, len(v))
+		}
+		if max, ok := asFloat(node["maxItems"]); ok && float64(len(v)) > max {
+			add("maxItems", "must have at most %v items, got %d", max, len(v))
+		}
+		if asBool(node["uniqueItems"]) {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if equalValues(v[i], v[j]) {
+						add("uniqueItems", "items %d and %d are equal", i, j)
+					}
+				}
+			}
+		}
+		if items, ok := node["items"]; ok {
+			for i, item := range v {
+				violations = append(violations, s.validate(items, item, fmt.Sprintf("%s[%d]", path, i), depth+1)...)
+			}
+		}
+	case map[string]interface{}:
+		violations = append(violations, s.validateObject(node, v, path, depth)...)
+	}
+
+	for _, sub := range asSlice(node["allOf"]) {
+		violations = append(violations, s.validate(sub, value, path, depth+1)...)
+	}
+
+	if anyOf := asSlice(node["anyOf"]); anyOf != nil {
+		matched := false
+		for _, sub := range anyOf {
+			if len(s.validate(sub, value, path, depth+1)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("anyOf", "must match at least one of %d schemas", len(anyOf))
+		}
+	}
+
+	if oneOf := asSlice(node["oneOf"]); oneOf != nil {
+		matched := 0
+		for _, sub := range oneOf {
+			if len(s.validate(sub, value, path, depth+1)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			add("oneOf", "must match exactly one of %d schemas, matched %d", len(oneOf), matched)
+		}
+	}
+
+	if not, ok := node["not"]; ok && len(s.validate(not, value, path, depth+1)) == 0 {
+		add("not", "must not match the excluded schema")
+	}
+
+	return violations
+}
+
+func (s *Spec) validateObject(node map[interface{}]interface{}, object map[string]interface{}, path string, depth int) []Violation {
+	var violations []Violation
+
+	for _, required := range asSlice(node["required"]) {
+		name := asString(required)
+		if _, ok := object[name]; !ok {
+			violations = append(violations, Violation{
+				Rule:    "required",
+				Path:    joinPath(path, name),
+				Message: "is required",
+			})
+		}
+	}
+
+	if min, ok := asFloat(node["minProperties"]); ok && float64(len(object)) < min {
+		violations = append(violations, Violation{Rule: "minProperties", Path: path, Message: fmt.Sprintf("must have at least %v properties", min)})
+	}
+	if max, ok := asFloat(node["maxProperties"]); ok && float64(len(object)) > max {
+		violations = append(violations, Violation{Rule: "maxProperties", Path: path, Message: fmt.Sprintf("must have at most %v properties", max)})
+	}
+
+	properties := asMap(node["properties"])
+	additional, hasAdditional := node["additionalProperties"]
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldPath := joinPath(path, name)
+		if property, ok := properties[name]; ok {
+			violations = append(violations, s.validate(property, object[name], fieldPath, depth+1)...)
+			continue
+		}
+
+		if !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok {
+			if !allowed {
+				violations = append(violations, Violation{
+					Rule:    "additionalProperties",
+					Path:    fieldPath,
+					Message: "is not allowed by the schema",
+				})
+			}
+			continue
+		}
+		violations = append(violations, s.validate(additional, object[name], fieldPath, depth+1)...)
+	}
+
+	return violations
+}
+
+func (s *Spec) validateString(node map[interface{}]interface{}, value, path string) []Violation {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := float64(len([]rune(value)))
+	if min, ok := asFloat(node["minLength"]); ok && length < min {
+		add("minLength", "must be at least %v characters long", min)
+	}
+	if max, ok := asFloat(node["maxLength"]); ok && length > max {
+		add("maxLength", "must be at most %v characters long", max)
+	}
+
+	if pattern := asString(node["pattern"]); pattern != "" {
+		re, err := compilePattern(pattern)
+		if err == nil && !re.MatchString(value) {
+			add("pattern", "must match pattern %s", pattern)
+		}
+	}
+
+	if format := asString(node["format"]); format != "" && !matchesFormat(format, value) {
+		add("format", "must be a valid %s, got %q", format, value)
+	}
+
+	return violations
+}
+
+func validateNumber(node map[interface{}]interface{}, value float64, path string) []Violation {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if min, ok := asFloat(node["minimum"]); ok {
+		if asBool(node["exclusiveMinimum"]) && value <= min {
+			add("exclusiveMinimum", "must be greater than %v, got %v", min, value)
+		} else if value < min {
+			add("minimum", "must be at least %v, got %v", min, value)
+		}
+	}
+	if max, ok := asFloat(node["maximum"]); ok {
+		if asBool(node["exclusiveMaximum"]) && value >= max {
+			add("exclusiveMaximum", "must be less than %v, got %v", max, value)
+		} else if value > max {
+			add("maximum", "must be at most %v, got %v", max, value)
+		}
+	}
+
+	// OpenAPI 3.1 / JSON Schema numeric exclusive bounds
+	if min, ok := asFloat(node["exclusiveMinimum"]); ok && value <= min {
+		add("exclusiveMinimum", "must be greater than %v, got %v", min, value)
+	}
+	if max, ok := asFloat(node["exclusiveMaximum"]); ok && value >= max {
+		add("exclusiveMaximum", "must be less than %v, got %v", max, value)
+	}
+
+	if multiple, ok := asFloat(node["multipleOf"]); ok && multiple > 0 {
+		quotient := value / multiple
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			add("multipleOf", "must be a multiple of %v, got %v", multiple, value)
+		}
+	}
+
+	switch asString(node["format"]) {
+	case "int32":
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			add("format", "must fit in int32, got %v", value)
+		}
+	}
+
+	return violations
+}
+
+// CoerceParameter converts a raw path, query or header value to the type its
+// schema declares so that it can be validated like a JSON value.
+func (s *Spec) CoerceParameter(schema interface{}, raw string) (interface{}, error) {
+	node := asMap(s.Resolve(schema))
+
+	switch primaryType(node) {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer, got %q", raw)
+		}
+		return float64(n), nil
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number, got %q", raw)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean, got %q", raw)
+		}
+		return b, nil
+	case "array":
+		var items []interface{}
+		for _, part := range strings.Split(raw, ",") {
+			item, err := s.CoerceParameter(node["items"], part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return raw, nil
+	}
+}
+
+// primaryType returns the first non-null type a schema declares.
+func primaryType(node map[interface{}]interface{}) string {
+	switch t := node["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name := asString(item); name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func typeAllows(node map[interface{}]interface{}, name string) bool {
+	switch t := node["type"].(type) {
+	case string:
+		return t == name
+	case []interface{}:
+		for _, item := range t {
+			if asString(item) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesType(node map[interface{}]interface{}, value interface{}) bool {
+	types := []string{asString(node["type"])}
+	if list, ok := node["type"].([]interface{}); ok {
+		types = nil
+		for _, item := range list {
+			types = append(types, asString(item))
+		}
+	}
+
+	for _, name := range types {
+		switch name {
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := asFloat(value); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := asFloat(value); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeTypes(node map[interface{}]interface{}) string {
+	if list, ok := node["type"].([]interface{}); ok {
+		var names []string
+		for _, item := range list {
+			names = append(names, asString(item))
+		}
+		return "one of " + strings.Join(names, ", ")
+	}
+	t := asString(node["type"])
+	switch t {
+	case "array", "integer", "object":
+		return "an " + t
+	default:
+		return "a " + t
+	}
+}
+
+func valueType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		if n, ok := asFloat(v); ok {
+			if n == math.Trunc(n) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func matchesFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uri", "url":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	default:
+		return true // Unknown formats are annotations only
+	}
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternMu.Lock()
+	defer patternMu.Unlock()
+
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares YAML and JSON values, treating numbers of different Go
+// types as equal when their values are and a YAML map as equal to a JSON
+// object with the same members.
+func equalValues(a, b interface{}) bool {
+	if x, ok := asFloat(a); ok {
+		y, ok := asFloat(b)
+		return ok && x == y
+	}
+	if x := stringKeys(asMap(a)); x != nil {
+		y := stringKeys(asMap(b))
+		if y == nil || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := a.([]interface{}); ok {
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = formatValue(value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,223 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Webhook is a request a provider sends to its consumers: an OpenAPI 3.1
+// webhook, or a callback declared on one of the provider's operations
+// (OpenAPI 3.0). Operation describes the request and the responses the
+// provider accepts; its Path is the webhook name or the callback's URL
+// expression, such as {$request.body#/callbackUrl}.
+type Webhook struct {
+	Name      string
+	Operation Operation
+	Callback  *Operation // operation declaring the callback; nil for webhooks
+}
+
+func (w Webhook) String() string {
+	if w.Callback != nil {
+		return fmt.Sprintf("callback %s %s of %s", w.Name, strings.ToUpper(w.Operation.Method), w.Callback)
+	}
+	return fmt.Sprintf("webhook %s %s", w.Name, strings.ToUpper(w.Operation.Method))
+}
+
+// Webhooks returns the document's webhooks ordered by name, followed by the
+// callbacks of its operations in operation order.
+func (s *Spec) Webhooks() []Webhook {
+	var webhooks []Webhook
+
+	declared := asMap(s.doc["webhooks"])
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, fmt.Sprintf("%v", name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		webhooks = append(webhooks, s.pathItemWebhooks(name, name, declared[name], nil)...)
+	}
+
+	for _, op := range s.Operations() {
+		op := op
+		callbacks := asMap(op.Node["callbacks"])
+		names := make([]string, 0, len(callbacks))
+		for name := range callbacks {
+			names = append(names, fmt.Sprintf("%v", name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			expressions := asMap(s.Resolve(callbacks[name]))
+			keys := make([]string, 0, len(expressions))
+			for expression := range expressions {
+				keys = append(keys, fmt.Sprintf("%v", expression))
+			}
+			sort.Strings(keys)
+			for _, expression := range keys {
+				webhooks = append(webhooks, s.pathItemWebhooks(name, expression, expressions[expression], &op)...)
+			}
+		}
+	}
+
+	return webhooks
+}
+
+func (s *Spec) pathItemWebhooks(name, path string, item interface{}, callback *Operation) []Webhook {
+	pathItem := asMap(s.Resolve(item))
+	var webhooks []Webhook
+	for _, method := range httpMethods {
+		if node, ok := pathItem[method]; ok {
+			webhooks = append(webhooks, Webhook{
+				Name: name,
+				Operation: Operation{
+					Method:   method,
+					Path:     path,
+					Node:     asMap(s.Resolve(node)),
+					pathItem: pathItem,
+					spec:     s,
+				},
+				Callback: callback,
+			})
+		}
+	}
+	return webhooks
+}
+
+// FindWebhook returns the webhook or callback with the given name and
+// method. The second result reports whether any webhook has the name, which
+// distinguishes unknown webhooks from unsupported methods.
+func (s *Spec) FindWebhook(name, method string) (*Webhook, bool) {
+	method = strings.ToLower(method)
+	named := false
+	for _, webhook := range s.Webhooks() {
+		if webhook.Name != name {
+			continue
+		}
+		named = true
+		if webhook.Operation.Method == method {
+			return &webhook, true
+		}
+	}
+	return nil, named
+}
+
+// SampleDelivery builds a request the provider could send for the webhook:
+// the body from the declared example of its preferred media type, or else
+// generated from its schema, and a value for every required header
+// parameter.
+func (w Webhook) SampleDelivery() (contentType string, body interface{}, headers map[string]string) {
+	s := w.Operation.spec
+	headers = map[string]string{}
+
+	for _, param := range w.Operation.Parameters() {
+		if param.In != "header" || !param.Required {
+			continue
+		}
+		headers[param.Name] = fmt.Sprintf("%v", s.Example(param.Schema))
+	}
+
+	content, _, ok := w.Operation.RequestBody()
+	if !ok || len(content) == 0 {
+		return "", nil, headers
+	}
+	contentType, media, _ := SelectMediaType(content, "")
+	if example, ok := s.MediaExample(media, ""); ok {
+		return contentType, example, headers
+	}
+	return contentType, s.Example(asMap(media)["schema"]), headers
+}
+
+// CheckReliance reports the parts of value that a receiver relies on but a
+// sender described by schema does not promise to send: fields the schema
+// does not declare (rule "undeclared") and fields it declares without
+// requiring them (rule "optional"). Value is typically a consumer's example
+// of a payload it accepts.
+func (s *Spec) CheckReliance(schema interface{}, value interface{}, path string) []Violation {
+	return s.checkReliance(schema, value, path, 0)
+}
+
+func (s *Spec) checkReliance(schema interface{}, value interface{}, path string, depth int) []Violation {
+	if depth > 64 {
+		return nil
+	}
+	node := asMap(s.Resolve(schema))
+	if node == nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		var violations []Violation
+		for i, item := range v {
+			violations = append(violations, s.checkReliance(node["items"], item, fmt.Sprintf("%s[%d]", path, i), depth+1)...)
+		}
+		return violations
+	case map[string]interface{}:
+		properties, required, branching := s.objectShape(node, depth)
+		if properties == nil || branching {
+			return nil // A free-form object promises nothing about its fields
+		}
+
+		var violations []Violation
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := joinPath(path, key)
+			property, declared := properties[key]
+			if !declared {
+				violations = append(violations, Violation{Rule: "undeclared", Path: fieldPath, Message: "is not declared by the sender"})
+				continue
+			}
+			if !required[key] {
+				violations = append(violations, Violation{Rule: "optional", Path: fieldPath, Message: "is optional, so the sender may leave it out"})
+			}
+			violations = append(violations, s.checkReliance(property, v[key], fieldPath, depth+1)...)
+		}
+		return violations
+	default:
+		return nil
+	}
+}
+
+// objectShape merges the properties and required fields of an object
+// schema and its allOf parts. branching reports a oneOf or anyOf, whose
+// fields depend on the branch the sender picks.
+func (s *Spec) objectShape(node map[interface{}]interface{}, depth int) (properties map[string]interface{}, required map[string]bool, branching bool) {
+	if depth > 64 || len(asSlice(node["oneOf"])) > 0 || len(asSlice(node["anyOf"])) > 0 {
+		return nil, nil, true
+	}
+
+	required = map[string]bool{}
+	for _, name := range asSlice(node["required"]) {
+		required[asString(name)] = true
+	}
+	if declared := asMap(node["properties"]); declared != nil {
+		properties = map[string]interface{}{}
+		for name, property := range declared {
+			properties[fmt.Sprintf("%v", name)] = property
+		}
+	}
+
+	for _, part := range asSlice(node["allOf"]) {
+		partProperties, partRequired, partBranching := s.objectShape(asMap(s.Resolve(part)), depth+1)
+		if partBranching {
+			return nil, nil, true
+		}
+		if partProperties != nil && properties == nil {
+			properties = map[string]interface{}{}
+		}
+		for name, property := range partProperties {
+			properties[name] = property
+		}
+		for name := range partRequired {
+			required[name] = true
+		}
+	}
+
+	return properties, required, false
+}
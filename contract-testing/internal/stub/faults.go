@@ -0,0 +1,417 @@
+package stub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// FaultProfile describes the faults injected into requests for one route.
+// Route is a path template optionally preceded by a method, e.g.
+// "GET /orders/{orderId}" or "/orders". Percentages are 0-100 and are rolled
+// independently per request, in the order error, reset, truncate.
+type FaultProfile struct {
+	Route           string    `yaml:"route" json:"route"`
+	Latency         Duration  `yaml:"latency,omitempty" json:"latency,omitempty"`
+	Jitter          Duration  `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+	ErrorPercent    float64   `yaml:"errorPercent,omitempty" json:"errorPercent,omitempty"`
+	ErrorStatuses   []int     `yaml:"errorStatuses,omitempty" json:"errorStatuses,omitempty"`
+	ResetPercent    float64   `yaml:"resetPercent,omitempty" json:"resetPercent,omitempty"`
+	TruncatePercent float64   `yaml:"truncatePercent,omitempty" json:"truncatePercent,omitempty"`
+	SlowDrip        *SlowDrip `yaml:"slowDrip,omitempty" json:"slowDrip,omitempty"`
+}
+
+// SlowDrip streams response bodies a few bytes at a time.
+type SlowDrip struct {
+	Bytes    int      `yaml:"bytes" json:"bytes"`
+	Interval Duration `yaml:"interval" json:"interval"`
+}
+
+// FaultFile is the layout of a fault profile file.
+type FaultFile struct {
+	Profiles []FaultProfile `yaml:"profiles" json:"profiles"`
+}
+
+// Duration is a time.Duration written as a string such as "250ms" in YAML and JSON.
+type Duration time.Duration
+
+// DefaultErrorStatuses are injected when a profile sets errorPercent without errorStatuses.
+var DefaultErrorStatuses = []int{http.StatusInternalServerError, http.StatusServiceUnavailable}
+
+// Faults injects fault profiles into the requests of the handler it wraps.
+// Profiles can be replaced at runtime through the admin endpoints:
+//
+//	GET    /__stub/faults   list the active profiles
+//	PUT    /__stub/faults   replace them ({"profiles": [...]})
+//	POST   /__stub/faults   add one profile, replacing any for the same route
+//	DELETE /__stub/faults   remove all profiles
+type Faults struct {
+	next     http.Handler
+	mu       sync.Mutex
+	profiles []FaultProfile
+	random   *rand.Rand
+}
+
+// LoadFaultProfiles reads a YAML or JSON fault profile file.
+func LoadFaultProfiles(path string) ([]FaultProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fault profiles: %w", err)
+	}
+
+	var file FaultFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fault profiles %s: %w", path, err)
+	}
+
+	if err := ValidateFaultProfiles(file.Profiles); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Profiles, nil
+}
+
+// ValidateFaultProfiles checks that every profile names a route and uses
+// percentages, statuses and durations that make sense.
+func ValidateFaultProfiles(profiles []FaultProfile) error {
+	for i, profile := range profiles {
+		if strings.TrimSpace(profile.Route) == "" {
+			return fmt.Errorf("profile %d: route is required", i+1)
+		}
+
+		percents := []struct {
+			name  string
+			value float64
+		}{
+			{"errorPercent", profile.ErrorPercent},
+			{"resetPercent", profile.ResetPercent},
+			{"truncatePercent", profile.TruncatePercent},
+		}
+		for _, percent := range percents {
+			if percent.value < 0 || percent.value > 100 {
+				return fmt.Errorf("profile %s: %s must be between 0 and 100", profile.Route, percent.name)
+			}
+		}
+
+		for _, status := range profile.ErrorStatuses {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("profile %s: invalid error status %d", profile.Route, status)
+			}
+		}
+
+		if profile.Latency < 0 || profile.Jitter < 0 {
+			return fmt.Errorf("profile %s: latency and jitter must not be negative", profile.Route)
+		}
+
+		if drip := profile.SlowDrip; drip != nil && (drip.Bytes <= 0 || drip.Interval <= 0) {
+			return fmt.Errorf("profile %s: slowDrip needs positive bytes and interval", profile.Route)
+		}
+	}
+	return nil
+}
+
+// WithFaults wraps next so that requests matching a profile are delayed or
+// fail as the profile describes. Everything else reaches next untouched.
+func WithFaults(next http.Handler, profiles []FaultProfile) *Faults {
+	return &Faults{
+		next:     next,
+		profiles: profiles,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Profiles returns the active fault profiles.
+func (f *Faults) Profiles() []FaultProfile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FaultProfile{}, f.profiles...)
+}
+
+// SetProfiles replaces the active fault profiles.
+func (f *Faults) SetProfiles(profiles []FaultProfile) error {
+	if err := ValidateFaultProfiles(profiles); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.profiles = profiles
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Faults) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == AdminPrefix+"faults" {
+		f.serveAdmin(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, AdminPrefix) {
+		f.next.ServeHTTP(w, r)
+		return
+	}
+
+	profile, ok := f.match(r)
+	if !ok {
+		f.next.ServeHTTP(w, r)
+		return
+	}
+
+	if delay := f.delay(profile); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if f.roll(profile.ErrorPercent) {
+		statuses := profile.ErrorStatuses
+		if len(statuses) == 0 {
+			statuses = DefaultErrorStatuses
+		}
+		status := statuses[f.intn(len(statuses))]
+		writeJSON(w, status, map[string]string{
+			"error": fmt.Sprintf("injected fault: %d %s", status, http.StatusText(status)),
+			"route": profile.Route,
+		})
+		return
+	}
+
+	if f.roll(profile.ResetPercent) {
+		closeConnection(w, true)
+		return
+	}
+
+	truncate := f.roll(profile.TruncatePercent)
+	if !truncate && profile.SlowDrip == nil {
+		f.next.ServeHTTP(w, r)
+		return
+	}
+
+	// Buffer the real response so it can be cut short or trickled out.
+	buffered := newBufferedResponse()
+	f.next.ServeHTTP(buffered, r)
+
+	for name, values := range buffered.header {
+		w.Header()[name] = values
+	}
+	body := buffered.body.Bytes()
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(buffered.status)
+
+	if truncate {
+		body = body[:len(body)/2]
+	}
+	if profile.SlowDrip != nil {
+		drip(w, r, body, *profile.SlowDrip)
+	} else {
+		w.Write(body)
+	}
+
+	if truncate {
+		// The declared Content-Length promises more than was sent; closing
+		// the connection now makes clients see a truncated body.
+		http.NewResponseController(w).Flush()
+		closeConnection(w, false)
+	}
+}
+
+// match returns the first profile whose route matches the request.
+func (f *Faults) match(r *http.Request) (FaultProfile, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, profile := range f.profiles {
+		method, template := splitRoute(profile.Route)
+		if method != "" && !strings.EqualFold(method, r.Method) {
+			continue
+		}
+		if _, ok := schema.MatchPath(template, r.URL.Path); ok {
+			return profile, true
+		}
+	}
+	return FaultProfile{}, false
+}
+
+func (f *Faults) delay(profile FaultProfile) time.Duration {
+	delay := time.Duration(profile.Latency)
+	if profile.Jitter > 0 {
+		f.mu.Lock()
+		delay += time.Duration(f.random.Int63n(int64(profile.Jitter) + 1))
+		f.mu.Unlock()
+	}
+	return delay
+}
+
+func (f *Faults) roll(percent float64) bool {
+	if percent <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.random.Float64()*100 < percent
+}
+
+func (f *Faults) intn(n int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.random.Intn(n)
+}
+
+func (f *Faults) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, FaultFile{Profiles: f.Profiles()})
+
+	case http.MethodPut:
+		var file FaultFile
+		if err := json.NewDecoder(r.Body).Decode(&file); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid fault profiles: %v", err)})
+			return
+		}
+		if err := f.SetProfiles(file.Profiles); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, FaultFile{Profiles: f.Profiles()})
+
+	case http.MethodPost:
+		var profile FaultProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid fault profile: %v", err)})
+			return
+		}
+
+		var profiles []FaultProfile
+		for _, existing := range f.Profiles() {
+			if existing.Route != profile.Route {
+				profiles = append(profiles, existing)
+			}
+		}
+		if err := f.SetProfiles(append(profiles, profile)); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, FaultFile{Profiles: f.Profiles()})
+
+	case http.MethodDelete:
+		f.SetProfiles(nil)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "use GET, PUT, POST or DELETE"})
+	}
+}
+
+// splitRoute splits "GET /orders" into its method and path template.
+func splitRoute(route string) (string, string) {
+	route = strings.TrimSpace(route)
+	if method, template, ok := strings.Cut(route, " "); ok {
+		return strings.ToUpper(method), strings.TrimSpace(template)
+	}
+	return "", route
+}
+
+// drip writes body in chunks, flushing and pausing after each one.
+func drip(w http.ResponseWriter, r *http.Request, body []byte, slow SlowDrip) {
+	controller := http.NewResponseController(w)
+	for len(body) > 0 {
+		n := slow.Bytes
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		controller.Flush()
+		body = body[n:]
+
+		if len(body) > 0 {
+			select {
+			case <-time.After(time.Duration(slow.Interval)):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// closeConnection drops the client connection. A reset sets SO_LINGER to
+// zero so that the kernel sends a TCP RST instead of a clean FIN.
+func closeConnection(w http.ResponseWriter, reset bool) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok && reset {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// bufferedResponse captures a response so it can be replayed with faults.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return d.set(value)
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return d.set(value)
+}
+
+// set accepts a duration string such as "1.5s" or a number of milliseconds.
+func (d *Duration) set(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*d = Duration(parsed)
+	case int:
+		*d = Duration(time.Duration(v) * time.Millisecond)
+	case float64:
+		*d = Duration(time.Duration(v * float64(time.Millisecond)))
+	default:
+		return fmt.Errorf("invalid duration %v", value)
+	}
+	return nil
+}
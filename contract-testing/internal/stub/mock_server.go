@@ -0,0 +1,530 @@
+package stub
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"github.com/Arpit529srivastava/internal/verifier"
+)
+
+// AdminPrefix is the path prefix of a stub's admin endpoints. Requests under
+// it are never matched against the contract or the mocks.
+const AdminPrefix = "/__stub/"
+
+// MockServer answers requests exactly as a set of consumer mocks describe.
+// A request matches a mock when its method, endpoint, parameters, headers and
+// body agree with the mock's request; the mock's response is returned as is.
+// Unmatched requests get a 404 describing the closest mock.
+//
+// {{name}} placeholders in a mock's request stand for the value an earlier
+// response captured under that name, and for any value until one has; the
+// response takes whatever they stood for.
+//
+// GET /__stub/mocks lists every mock with its hit count and
+// GET /__stub/mocks/unused lists the mocks that were never hit.
+type MockServer struct {
+	mocks     []*mockEntry
+	unmatched []NoMatchError
+	captures  map[string]interface{}
+	mu        sync.Mutex
+}
+
+type mockEntry struct {
+	path string
+	mock verifier.Mock
+	hits int
+}
+
+// MockStatus reports how often a mock was hit.
+type MockStatus struct {
+	Path        string `json:"path"`
+	Provider    string `json:"provider"`
+	Consumer    string `json:"consumer"`
+	Description string `json:"description"`
+	Method      string `json:"method"`
+	Endpoint    string `json:"endpoint"`
+	Hits        int    `json:"hits"`
+}
+
+// NoMatchError is the body of a 404 answer for a request no mock matches.
+type NoMatchError struct {
+	Error   string       `json:"error"`
+	Closest *ClosestMock `json:"closest,omitempty"`
+}
+
+// ClosestMock is the mock that came nearest to matching a request, with the
+// reasons it didn't.
+type ClosestMock struct {
+	Path        string   `json:"path"`
+	Description string   `json:"description"`
+	Method      string   `json:"method"`
+	Endpoint    string   `json:"endpoint"`
+	Mismatches  []string `json:"mismatches"`
+}
+
+// LoadMocks reads every HTTP mock under dir, skipping message, gRPC,
+// GraphQL, webhook and stream contracts, which have no provider request to
+// serve. Mocks for other providers are skipped unless provider is empty;
+// mocks that name no provider are always kept. Files are returned in
+// lexical order.
+func LoadMocks(dir, provider string) ([]string, []verifier.Mock, error) {
+	contracts, err := verifier.LoadContracts(dir, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load mocks: %w", err)
+	}
+
+	var paths []string
+	var mocks []verifier.Mock
+	for i, mock := range contracts.Mocks {
+		if provider == "" || mock.Provider == "" || mock.Provider == provider {
+			paths = append(paths, contracts.MockPaths[i])
+			mocks = append(mocks, mock)
+		}
+	}
+	return paths, mocks, nil
+}
+
+// NewMockServer creates a stub serving mocks; paths name the file each mock
+// was loaded from and are used in diagnostics.
+func NewMockServer(paths []string, mocks []verifier.Mock) *MockServer {
+	server := &MockServer{}
+	for i, mock := range mocks {
+		server.mocks = append(server.mocks, &mockEntry{path: paths[i], mock: mock})
+	}
+	return server
+}
+
+func (s *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, AdminPrefix) {
+		s.serveAdmin(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, NoMatchError{Error: "failed to read request body"})
+		return
+	}
+
+	s.mu.Lock()
+	captures := make(map[string]interface{}, len(s.captures))
+	for name, value := range s.captures {
+		captures[name] = value
+	}
+	s.mu.Unlock()
+
+	var best *mockEntry
+	var bestRequest verifier.MockRequest
+	var bindings map[string]interface{}
+	var closest *mockEntry
+	var closestMismatches []string
+	closestScore := -1
+
+	for _, entry := range s.mocks {
+		expected := verifier.SubstituteCaptures(entry.mock, captures).Request
+		mismatches, score, bound := matchRequest(expected, r, body)
+		if len(mismatches) == 0 {
+			if best == nil || specificity(expected) > specificity(bestRequest) {
+				best, bestRequest, bindings = entry, expected, bound
+			}
+			continue
+		}
+		if closestScore == -1 || score < closestScore {
+			closest, closestMismatches, closestScore = entry, mismatches, score
+		}
+	}
+
+	if best == nil {
+		noMatch := NoMatchError{Error: fmt.Sprintf("no mock matches %s %s", r.Method, r.URL.RequestURI())}
+		if closest != nil {
+			noMatch.Closest = &ClosestMock{
+				Path:        closest.path,
+				Description: closest.mock.Description,
+				Method:      strings.ToUpper(closest.mock.Request.Method),
+				Endpoint:    closest.mock.Request.Endpoint,
+				Mismatches:  closestMismatches,
+			}
+		}
+		s.mu.Lock()
+		s.unmatched = append(s.unmatched, noMatch)
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusNotFound, noMatch)
+		return
+	}
+
+	for name, value := range captures {
+		if _, ok := bindings[name]; !ok {
+			bindings[name] = value
+		}
+	}
+	response := verifier.SubstituteCaptures(best.mock, bindings).Response
+
+	s.mu.Lock()
+	best.hits++
+	s.capture(best.mock, response.Body)
+	s.mu.Unlock()
+
+	writeMockResponse(w, response)
+}
+
+// capture keeps the values a mock captures from the response it was
+// answered with, for the placeholders of later requests. s.mu must be held.
+func (s *MockServer) capture(mock verifier.Mock, body interface{}) {
+	for name, expression := range mock.Captures {
+		value, err := verifier.EvaluateJSONPath(body, expression)
+		if err != nil {
+			continue
+		}
+		if s.captures == nil {
+			s.captures = make(map[string]interface{})
+		}
+		s.captures[name] = value
+	}
+}
+
+// Status returns the hit count of every mock in load order.
+func (s *MockServer) Status() []MockStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]MockStatus, 0, len(s.mocks))
+	for _, entry := range s.mocks {
+		statuses = append(statuses, MockStatus{
+			Path:        entry.path,
+			Provider:    entry.mock.Provider,
+			Consumer:    entry.mock.Consumer,
+			Description: entry.mock.Description,
+			Method:      strings.ToUpper(entry.mock.Request.Method),
+			Endpoint:    entry.mock.Request.Endpoint,
+			Hits:        entry.hits,
+		})
+	}
+	return statuses
+}
+
+// Unused returns the mocks that were never hit.
+func (s *MockServer) Unused() []MockStatus {
+	unused := []MockStatus{}
+	for _, status := range s.Status() {
+		if status.Hits == 0 {
+			unused = append(unused, status)
+		}
+	}
+	return unused
+}
+
+// Unmatched returns the requests no mock matched, in arrival order.
+func (s *MockServer) Unmatched() []NoMatchError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]NoMatchError{}, s.unmatched...)
+}
+
+func (s *MockServer) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, NoMatchError{Error: "admin endpoints only support GET"})
+		return
+	}
+
+	switch strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/") {
+	case "mocks":
+		writeJSON(w, http.StatusOK, s.Status())
+	case "mocks/unused":
+		writeJSON(w, http.StatusOK, s.Unused())
+	default:
+		writeJSON(w, http.StatusNotFound, NoMatchError{Error: fmt.Sprintf("unknown admin endpoint %s", r.URL.Path)})
+	}
+}
+
+// matchRequest lists every way a request differs from a mock's request. The
+// score weighs a wrong method or endpoint above other differences so that
+// the closest mock is one for the same route whenever there is one. It also
+// returns what the request's values for the mock's {{name}} placeholders
+// were.
+func matchRequest(expected verifier.MockRequest, r *http.Request, body []byte) ([]string, int, map[string]interface{}) {
+	var mismatches []string
+	score := 0
+	bindings := make(map[string]interface{})
+
+	if !strings.EqualFold(expected.Method, r.Method) {
+		mismatches = append(mismatches, fmt.Sprintf("method: expected %s, got %s", strings.ToUpper(expected.Method), r.Method))
+		score += 100
+	}
+
+	// A placeholder in the endpoint matches like a path parameter.
+	pathParams, ok := schema.MatchPath(verifier.PlaceholderPattern.ReplaceAllString(expected.Endpoint, "{$1}"), r.URL.Path)
+	if !ok {
+		mismatches = append(mismatches, fmt.Sprintf("endpoint: expected %s, got %s", expected.Endpoint, r.URL.Path))
+		score += 100
+	} else {
+		for _, match := range verifier.PlaceholderPattern.FindAllStringSubmatch(expected.Endpoint, -1) {
+			bindings[match[1]] = pathParams[match[1]]
+		}
+	}
+
+	query := r.URL.Query()
+	for _, name := range sortedNames(expected.Parameters) {
+		want := expected.Parameters[name]
+		got, inPath := pathParams[name]
+		if !inPath {
+			if !ok && strings.Contains(expected.Endpoint, "{"+name+"}") {
+				continue // already reported as an endpoint mismatch
+			}
+			got = query.Get(name)
+		}
+		if !matchValue(want, got, bindings) {
+			mismatches = append(mismatches, fmt.Sprintf("parameters.%s: expected %q, got %q", name, want, got))
+			score++
+		}
+	}
+
+	for _, name := range sortedNames(expected.Headers) {
+		want := expected.Headers[name]
+		got := r.Header.Get(name)
+		matched := verifier.HeaderMatches(name, want, got)
+		if verifier.PlaceholderPattern.MatchString(want) {
+			matched = matchValue(want, got, bindings)
+		}
+		if !matched {
+			mismatches = append(mismatches, fmt.Sprintf("headers.%s: expected %q, got %q", name, want, got))
+			score++
+		}
+	}
+
+	if expected.Body != nil {
+		// The mock's Content-Type decides how the body is read, but the
+		// request's keeps parameters such as the multipart boundary.
+		contentType := r.Header.Get("Content-Type")
+		if declared := headerValue(expected.Headers, "Content-Type"); declared != "" && !strings.EqualFold(bodyMediaType(declared), bodyMediaType(contentType)) {
+			contentType = declared
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		actual, ok := decodeBody(contentType, body)
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("body: expected a %s body", bodyMediaType(contentType)))
+			score++
+		} else {
+			want := bindBody(expectedBody(contentType, expected.Body), actual, bindings)
+			for _, mismatch := range verifier.CompareBody("body", want, actual, expected.MatchingRules) {
+				mismatches = append(mismatches, mismatch.Path+": "+mismatch.Description)
+				score++
+			}
+		}
+	}
+
+	return mismatches, score, bindings
+}
+
+// matchValue compares a request value with a mock's. Each {{name}}
+// placeholder left in the mock's value stands for any non-empty text, which
+// is recorded in bindings; a placeholder already bound must stand for the
+// same text again.
+func matchValue(want, got string, bindings map[string]interface{}) bool {
+	matches := verifier.PlaceholderPattern.FindAllStringSubmatchIndex(want, -1)
+	if matches == nil {
+		return want == got
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range matches {
+		pattern.WriteString(regexp.QuoteMeta(want[last:match[0]]))
+		pattern.WriteString("(.+?)")
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(want[last:]) + "$")
+
+	values := regexp.MustCompile(pattern.String()).FindStringSubmatch(got)
+	if values == nil {
+		return false
+	}
+	bound := make(map[string]interface{}, len(matches))
+	for i, match := range matches {
+		name, value := want[match[2]:match[3]], values[i+1]
+		for _, known := range []map[string]interface{}{bindings, bound} {
+			if previous, ok := known[name]; ok && fmt.Sprint(previous) != value {
+				return false
+			}
+		}
+		bound[name] = value
+	}
+	for name, value := range bound {
+		bindings[name] = value
+	}
+	return true
+}
+
+// bindBody returns a mock's body with every string holding placeholders
+// replaced by the request's value at the same place, if it fits, recording
+// what the placeholders stood for in bindings. A string that is exactly one
+// placeholder stands for a value of any type.
+func bindBody(want, got interface{}, bindings map[string]interface{}) interface{} {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		actual, _ := got.(map[string]interface{})
+		bound := make(map[string]interface{}, len(w))
+		for key, value := range w {
+			bound[key] = bindBody(value, actual[key], bindings)
+		}
+		return bound
+	case []interface{}:
+		actual, _ := got.([]interface{})
+		bound := make([]interface{}, len(w))
+		for i, item := range w {
+			var value interface{}
+			if i < len(actual) {
+				value = actual[i]
+			}
+			bound[i] = bindBody(item, value, bindings)
+		}
+		return bound
+	case string:
+		if match := verifier.PlaceholderPattern.FindStringSubmatch(w); match != nil && match[0] == w && got != nil {
+			if previous, ok := bindings[match[1]]; ok && fmt.Sprint(previous) != fmt.Sprint(got) {
+				return w
+			}
+			bindings[match[1]] = got
+			return got
+		}
+		if text, ok := got.(string); ok && matchValue(w, text, bindings) {
+			return text
+		}
+		return w
+	default:
+		return want
+	}
+}
+
+// specificity counts the constraints a mock request places on a request, so
+// that a mock pinning parameters or a body wins over a catch-all one. A
+// parameter or header that is a placeholder pins nothing.
+func specificity(request verifier.MockRequest) int {
+	count := 0
+	for _, values := range []map[string]string{request.Parameters, request.Headers} {
+		for _, value := range values {
+			if !verifier.PlaceholderPattern.MatchString(value) {
+				count++
+			}
+		}
+	}
+	if fields, ok := request.Body.(map[string]interface{}); ok {
+		count += len(fields)
+	} else if request.Body != nil {
+		count++
+	}
+	if !strings.Contains(request.Endpoint, "{") {
+		count++
+	}
+	return count
+}
+
+func writeMockResponse(w http.ResponseWriter, response verifier.MockResponse) {
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+
+	status := response.StatusCode
+	if status == 0 && schema.IsStatusRange(strings.ToUpper(response.StatusRange)) {
+		status = int(response.StatusRange[0]-'0') * 100
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if response.Body == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	data, sentAs, err := bodySpec.EncodeBody(bodyMediaType(contentType), nil, response.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("mock response body cannot be sent as %s: %v", contentType, err), http.StatusInternalServerError)
+		return
+	}
+	if !strings.HasPrefix(sentAs, "multipart/") {
+		sentAs = contentType // Keep parameters such as charset
+	}
+	w.Header().Set("Content-Type", sentAs)
+	w.WriteHeader(status)
+	w.Write(data)
+	if schema.IsJSONMediaType(sentAs) {
+		io.WriteString(w, "\n")
+	}
+}
+
+// bodySpec encodes and decodes bodies by media type alone, as the mock
+// server has no provider schema.
+var bodySpec = schema.NewSpec(nil)
+
+// decodeBody decodes a request body sent with contentType, JSON by default.
+// Form, multipart and XML values are strings, as there is no schema giving
+// their types, and bodies of other media types are compared as text.
+func decodeBody(contentType string, data []byte) (interface{}, bool) {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	value, violations, ok := bodySpec.DecodeBody(contentType, nil, data)
+	if !ok {
+		return string(data), true
+	}
+	for _, violation := range violations {
+		if violation.Rule == "syntax" {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// expectedBody returns a mock's body as it reads once sent with
+// contentType and decoded again, so that it compares with a decoded request
+// body.
+func expectedBody(contentType string, body interface{}) interface{} {
+	data, sentAs, err := bodySpec.EncodeBody(bodyMediaType(contentType), nil, body)
+	if err != nil {
+		return body
+	}
+	if value, ok := decodeBody(sentAs, data); ok {
+		return value
+	}
+	return body
+}
+
+// bodyMediaType returns the media type of a Content-Type, without parameters.
+func bodyMediaType(contentType string) string {
+	return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+}
+
+// headerValue returns a header from a mock's headers, whatever the case of
+// its name.
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,214 @@
+package stub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// SchemaServer answers requests on behalf of a provider using only its
+// OpenAPI document. Requests are routed by the document's path templates and
+// validated against the declared parameters and request bodies; responses are
+// taken from declared examples or generated from the response schema.
+//
+// Clients can pick a response with a Prefer header, e.g. "Prefer: code=404"
+// or "Prefer: example=shipped".
+type SchemaServer struct {
+	provider string
+	spec     *schema.Spec
+}
+
+// ContractError is the body of a 4xx answer for a request the contract doesn't allow.
+type ContractError struct {
+	Error      string             `json:"error"`
+	Provider   string             `json:"provider,omitempty"`
+	Operation  string             `json:"operation,omitempty"`
+	Violations []schema.Violation `json:"violations,omitempty"`
+}
+
+// NewSchemaServer creates a stub for the provider described by spec.
+func NewSchemaServer(provider string, spec *schema.Spec) *SchemaServer {
+	return &SchemaServer{
+		provider: provider,
+		spec:     spec,
+	}
+}
+
+func (s *SchemaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op, params, pathFound := s.spec.FindOperation(r.Method, r.URL.Path)
+	if op == nil {
+		status := http.StatusNotFound
+		message := fmt.Sprintf("%s is not declared in the %s contract", r.URL.Path, s.provider)
+		if pathFound {
+			status = http.StatusMethodNotAllowed
+			message = fmt.Sprintf("%s %s is not declared in the %s contract", r.Method, r.URL.Path, s.provider)
+		}
+		writeJSON(w, status, ContractError{Error: message, Provider: s.provider})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ContractError{Error: "failed to read request body", Provider: s.provider})
+		return
+	}
+
+	violations := op.ValidateRequest(schema.RequestData{
+		PathParams: params,
+		Query:      r.URL.Query(),
+		Header:     r.Header,
+		Body:       body,
+	})
+	if len(violations) > 0 {
+		writeJSON(w, http.StatusBadRequest, ContractError{
+			Error:      fmt.Sprintf("request violates the %s contract for %s", s.provider, op),
+			Provider:   s.provider,
+			Operation:  op.String(),
+			Violations: violations,
+		})
+		return
+	}
+
+	preferences := parsePrefer(r.Header.Get("Prefer"))
+	code, response, ok := selectResponse(op, preferences["code"])
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, ContractError{
+			Error:     fmt.Sprintf("%s declares no response %s", op, preferences["code"]),
+			Provider:  s.provider,
+			Operation: op.String(),
+		})
+		return
+	}
+
+	s.writeResponse(w, code, response, preferences["example"])
+}
+
+// writeResponse synthesizes a response from a response object of the spec.
+func (s *SchemaServer) writeResponse(w http.ResponseWriter, status int, response map[interface{}]interface{}, exampleName string) {
+	for name, header := range toStringMap(response["headers"]) {
+		headerNode, _ := s.spec.Resolve(header).(map[interface{}]interface{})
+		if value := s.spec.Example(headerNode["schema"]); value != nil {
+			w.Header().Set(name, fmt.Sprintf("%v", value))
+		}
+	}
+
+	content := toStringMap(response["content"])
+	if len(content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	mediaType, media, _ := schema.SelectMediaType(content, "")
+	body, ok := s.spec.MediaExample(media, exampleName)
+	if !ok {
+		mediaNode, _ := s.spec.Resolve(media).(map[interface{}]interface{})
+		body = s.spec.Example(mediaNode["schema"])
+	}
+
+	if schema.IsJSONMediaType(mediaType) {
+		w.Header().Set("Content-Type", mediaType)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+
+	data, contentType, err := s.spec.EncodeBody(mediaType, media, body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("example cannot be sent as %s: %v", mediaType, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// selectResponse picks the response for a preferred status code, or else the
+// lowest declared 2xx response, the default response or the lowest declared code.
+func selectResponse(op *schema.Operation, preferred string) (int, map[interface{}]interface{}, bool) {
+	responses := op.Responses()
+
+	if preferred != "" {
+		code, err := strconv.Atoi(preferred)
+		if err != nil {
+			return 0, nil, false
+		}
+		if response, ok := op.Response(code); ok {
+			return code, response, true
+		}
+		return 0, nil, false
+	}
+
+	var codes []string
+	for code := range responses {
+		if code != "default" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			if status, ok := rangeStatus(code); ok {
+				return status, responses[code], true
+			}
+		}
+	}
+	if response, ok := responses["default"]; ok {
+		return http.StatusOK, response, true
+	}
+	for _, code := range codes {
+		if status, ok := rangeStatus(code); ok {
+			return status, responses[code], true
+		}
+	}
+	return 0, nil, false
+}
+
+// rangeStatus returns the status code a response key stands for: the code
+// itself, or the first code of a range, 400 for "4XX".
+func rangeStatus(key string) (int, bool) {
+	if schema.IsStatusRange(key) {
+		return int(key[0]-'0') * 100, true
+	}
+	status, err := strconv.Atoi(key)
+	return status, err == nil
+}
+
+// parsePrefer parses the key=value preferences of a Prefer header.
+func parsePrefer(header string) map[string]string {
+	preferences := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			preferences[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return preferences
+}
+
+func toStringMap(value interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	switch m := value.(type) {
+	case map[interface{}]interface{}:
+		for key, item := range m {
+			result[fmt.Sprintf("%v", key)] = item
+		}
+	case map[string]interface{}:
+		for key, item := range m {
+			result[key] = item
+		}
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,279 @@
+package verifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// waiverDateLayout is the format of a waiver's expiry date.
+const waiverDateLayout = "2006-01-02"
+
+// Sources of a waived issue.
+const (
+	WaiveSourceBaseline = "baseline"
+	WaiveSourceWaiver   = "waiver"
+)
+
+// IssueKey identifies an issue across runs by rule code, provider, consumer,
+// mock and path.
+type IssueKey struct {
+	Code     string `json:"code"`
+	Provider string `json:"provider,omitempty"`
+	Consumer string `json:"consumer"`
+	Mock     string `json:"mock"`
+	Path     string `json:"path"`
+}
+
+// String formats the key for reports, leaving out empty fields.
+func (k IssueKey) String() string {
+	parts := []string{k.Code}
+	for _, part := range []string{k.Provider, k.Consumer, k.Mock, k.Path} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// Waiver explicitly acknowledges a known incompatibility until it expires.
+// Empty provider, consumer, mock or path fields match any value.
+type Waiver struct {
+	IssueKey
+	Owner   string `json:"owner"`
+	Reason  string `json:"reason"`
+	Expires string `json:"expires"` // YYYY-MM-DD, inclusive
+}
+
+// WaivedIssue is an issue that was suppressed by a baseline entry or a waiver.
+type WaivedIssue struct {
+	Issue
+	Source  string `json:"source"` // "baseline", "waiver"
+	Owner   string `json:"owner,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Expires string `json:"expires,omitempty"`
+}
+
+// Note describes why the issue was waived.
+func (w WaivedIssue) Note() string {
+	if w.Source == WaiveSourceWaiver {
+		return fmt.Sprintf("waived by %s until %s: %s", w.Owner, w.Expires, w.Reason)
+	}
+	return "known issue in baseline"
+}
+
+// Baseline is a snapshot of known issues plus explicit waivers. Runs that apply
+// a baseline only fail on issues that are neither baselined nor waived.
+type Baseline struct {
+	GeneratedAt time.Time  `json:"generatedAt"`
+	Issues      []IssueKey `json:"issues"`
+	Waivers     []Waiver   `json:"waivers,omitempty"`
+}
+
+// NewBaseline snapshots every outstanding issue in the results of one or more
+// providers.
+func NewBaseline(results ...*ValidationResult) *Baseline {
+	baseline := &Baseline{
+		Issues: []IssueKey{},
+	}
+
+	for _, result := range results {
+		if result.Timestamp.After(baseline.GeneratedAt) {
+			baseline.GeneratedAt = result.Timestamp
+		}
+
+		for consumer, consumerResult := range result.ConsumerResults {
+			for _, matchResult := range consumerResult.MatchResults {
+				for _, issue := range matchResult.Issues {
+					baseline.Issues = append(baseline.Issues, issueKey(result.ProviderName, consumer, matchResult, issue))
+				}
+			}
+		}
+	}
+
+	sort.Slice(baseline.Issues, func(i, j int) bool {
+		a, b := baseline.Issues[i], baseline.Issues[j]
+		return compareStrings(
+			a.Provider, b.Provider,
+			a.Consumer, b.Consumer,
+			a.Mock, b.Mock,
+			a.Path, b.Path,
+			a.Code, b.Code,
+		) < 0
+	})
+
+	return baseline
+}
+
+// LoadBaseline reads a baseline file and checks that its waivers are complete.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+
+	for i, waiver := range baseline.Waivers {
+		if err := waiver.validate(); err != nil {
+			return nil, fmt.Errorf("invalid waiver %d: %w", i+1, err)
+		}
+	}
+
+	return &baseline, nil
+}
+
+// Save writes the baseline to path. Waivers already present in an existing
+// file at path are kept so that regenerating a baseline doesn't drop them.
+func (b *Baseline) Save(path string) error {
+	if len(b.Waivers) == 0 {
+		if existing, err := LoadBaseline(path); err == nil {
+			b.Waivers = existing.Waivers
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return nil
+}
+
+// Apply moves baselined and waived issues out of the results and recomputes
+// compatibility. Expired waivers no longer suppress anything and are recorded
+// on the results instead.
+func (b *Baseline) Apply(results *ValidationResult, now time.Time) {
+	known := make(map[IssueKey]bool, len(b.Issues))
+	for _, key := range b.Issues {
+		known[key] = true
+	}
+
+	var active []Waiver
+	for _, waiver := range b.Waivers {
+		if waiver.Provider != "" && waiver.Provider != results.ProviderName {
+			continue
+		}
+		if waiver.expired(now) {
+			results.ExpiredWaivers = append(results.ExpiredWaivers, waiver)
+			continue
+		}
+		active = append(active, waiver)
+	}
+
+	results.OverallSuccess = true
+	for consumer, consumerResult := range results.ConsumerResults {
+		consumerResult.Success = true
+
+		for i := range consumerResult.MatchResults {
+			matchResult := &consumerResult.MatchResults[i]
+
+			remaining := []Issue{}
+			for _, issue := range matchResult.Issues {
+				key := issueKey(results.ProviderName, consumer, *matchResult, issue)
+
+				if waiver, ok := findWaiver(active, key); ok {
+					matchResult.Waived = append(matchResult.Waived, WaivedIssue{
+						Issue:   issue,
+						Source:  WaiveSourceWaiver,
+						Owner:   waiver.Owner,
+						Reason:  waiver.Reason,
+						Expires: waiver.Expires,
+					})
+					continue
+				}
+
+				if known[key] || known[key.withoutProvider()] {
+					matchResult.Waived = append(matchResult.Waived, WaivedIssue{
+						Issue:  issue,
+						Source: WaiveSourceBaseline,
+					})
+					continue
+				}
+
+				remaining = append(remaining, issue)
+			}
+
+			matchResult.Issues = remaining
+			matchResult.IsCompatible = errorCount(remaining) == 0
+			if !matchResult.IsCompatible {
+				consumerResult.Success = false
+				results.OverallSuccess = false
+			}
+		}
+
+		results.ConsumerResults[consumer] = consumerResult
+	}
+}
+
+func (w Waiver) validate() error {
+	if w.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if w.Owner == "" {
+		return fmt.Errorf("owner is required for %s", w.Code)
+	}
+	if w.Reason == "" {
+		return fmt.Errorf("reason is required for %s", w.Code)
+	}
+	if _, err := time.Parse(waiverDateLayout, w.Expires); err != nil {
+		return fmt.Errorf("expires must be a YYYY-MM-DD date for %s: %w", w.Code, err)
+	}
+	return nil
+}
+
+func (w Waiver) expired(now time.Time) bool {
+	expires, err := time.ParseInLocation(waiverDateLayout, w.Expires, now.Location())
+	if err != nil {
+		return true
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+func (w Waiver) matches(key IssueKey) bool {
+	return w.Code == key.Code &&
+		(w.Provider == "" || w.Provider == key.Provider) &&
+		(w.Consumer == "" || w.Consumer == key.Consumer) &&
+		(w.Mock == "" || w.Mock == key.Mock) &&
+		(w.Path == "" || w.Path == key.Path)
+}
+
+func findWaiver(waivers []Waiver, key IssueKey) (Waiver, bool) {
+	for _, waiver := range waivers {
+		if waiver.matches(key) {
+			return waiver, true
+		}
+	}
+	return Waiver{}, false
+}
+
+func issueKey(provider, consumer string, matchResult MatchResult, issue Issue) IssueKey {
+	return IssueKey{
+		Code:     issue.Code,
+		Provider: provider,
+		Consumer: consumer,
+		Mock:     matchResult.Mock.Description,
+		Path:     issue.Path,
+	}
+}
+
+// withoutProvider matches baseline entries recorded without a provider.
+func (k IssueKey) withoutProvider() IssueKey {
+	k.Provider = ""
+	return k
+}
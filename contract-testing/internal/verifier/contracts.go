@@ -0,0 +1,157 @@
+package verifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of contract files, as told by contractKind.
+const (
+	kindHTTP    = "http"
+	kindMessage = "message"
+	kindGRPC    = "grpc"
+	kindGraphQL = "graphql"
+	kindWebhook = "webhook"
+	kindStream  = "stream"
+)
+
+// contractKind tells what a contract file holds from its top-level keys:
+// messages have a channel and no request, gRPC, GraphQL and stream contracts
+// have a section of that name, and webhook contracts name the webhook they
+// receive. Anything else is an HTTP mock. It returns "" for files that
+// aren't JSON objects.
+func contractKind(data []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return ""
+	}
+	has := func(key string) bool {
+		raw, ok := fields[key]
+		return ok && string(raw) != "null"
+	}
+
+	switch {
+	case has("channel") && !has("request"):
+		return kindMessage
+	case has("grpc"):
+		return kindGRPC
+	case has("graphql"):
+		return kindGraphQL
+	case has("webhook"):
+		return kindWebhook
+	case has("stream"):
+		return kindStream
+	default:
+		return kindHTTP
+	}
+}
+
+// Contracts holds the contract files under a directory by kind. Each list
+// is in lexical order of the files, and its paths run parallel to it.
+type Contracts struct {
+	MockPaths    []string
+	Mocks        []Mock
+	MessagePaths []string
+	Messages     []Message
+	GRPCPaths    []string
+	GRPC         []GRPCMock
+	GraphQLPaths []string
+	GraphQL      []GraphQLMock
+	WebhookPaths []string
+	Webhooks     []WebhookContract
+	StreamPaths  []string
+	Streams      []StreamMock
+}
+
+// LoadContracts reads every contract file under dir and sorts them by kind.
+// Contracts for other providers are skipped unless provider is empty. Files
+// that aren't JSON objects, or don't parse as a mock, are no contracts and
+// are skipped too.
+func LoadContracts(dir, provider string) (*Contracts, error) {
+	contracts := &Contracts{}
+	wanted := func(name string) bool {
+		return provider == "" || name == provider
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read contract file: %w", err)
+		}
+
+		switch contractKind(data) {
+		case "":
+			return nil // Skip this file
+		case kindMessage:
+			var message Message
+			if err := json.Unmarshal(data, &message); err != nil {
+				return fmt.Errorf("failed to parse message %s: %w", path, err)
+			}
+			if wanted(message.Provider) {
+				contracts.MessagePaths = append(contracts.MessagePaths, path)
+				contracts.Messages = append(contracts.Messages, message)
+			}
+		case kindGRPC:
+			var mock GRPCMock
+			if err := json.Unmarshal(data, &mock); err != nil {
+				return fmt.Errorf("failed to parse gRPC contract %s: %w", path, err)
+			}
+			if wanted(mock.Provider) {
+				contracts.GRPCPaths = append(contracts.GRPCPaths, path)
+				contracts.GRPC = append(contracts.GRPC, mock)
+			}
+		case kindGraphQL:
+			var mock GraphQLMock
+			if err := json.Unmarshal(data, &mock); err != nil {
+				return fmt.Errorf("failed to parse GraphQL contract %s: %w", path, err)
+			}
+			if wanted(mock.Provider) {
+				contracts.GraphQLPaths = append(contracts.GraphQLPaths, path)
+				contracts.GraphQL = append(contracts.GraphQL, mock)
+			}
+		case kindWebhook:
+			var contract WebhookContract
+			if err := json.Unmarshal(data, &contract); err != nil {
+				return fmt.Errorf("failed to parse webhook contract %s: %w", path, err)
+			}
+			if wanted(contract.Provider) {
+				contracts.WebhookPaths = append(contracts.WebhookPaths, path)
+				contracts.Webhooks = append(contracts.Webhooks, contract)
+			}
+		case kindStream:
+			var mock StreamMock
+			if err := json.Unmarshal(data, &mock); err != nil {
+				return fmt.Errorf("failed to parse stream contract %s: %w", path, err)
+			}
+			if wanted(mock.Provider) {
+				contracts.StreamPaths = append(contracts.StreamPaths, path)
+				contracts.Streams = append(contracts.Streams, mock)
+			}
+		default:
+			var mock Mock
+			if err := json.Unmarshal(data, &mock); err != nil {
+				return nil // Skip this file
+			}
+			if wanted(mock.Provider) {
+				contracts.MockPaths = append(contracts.MockPaths, path)
+				contracts.Mocks = append(contracts.Mocks, mock)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contracts: %w", err)
+	}
+
+	return contracts, nil
+}
@@ -0,0 +1,305 @@
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Issue codes reported for GraphQL contracts.
+const (
+	CodeGraphQLSchemaMissing    = "graphql-schema-missing"
+	CodeGraphQLQueryInvalid     = "graphql-query-invalid"
+	CodeGraphQLVariablesInvalid = "graphql-variables-invalid"
+	CodeGraphQLDeprecated       = "graphql-deprecated"
+	CodeGraphQLResponseInvalid  = "graphql-response-invalid"
+	CodeGraphQLRequestFailed    = "graphql-request-failed"
+	CodeGraphQLErrorsMismatch   = "graphql-errors-mismatch"
+	CodeGraphQLDataMismatch     = "graphql-data-mismatch"
+)
+
+// GraphQLMock is a consumer's expectation of one GraphQL operation: the
+// query document, the variables sent with it and the response expected.
+// Matching rules apply to response paths such as "data.order.id".
+type GraphQLMock struct {
+	Provider      string          `json:"provider"`
+	Consumer      string          `json:"consumer"`
+	Description   string          `json:"description"`
+	ProviderState string          `json:"providerState,omitempty"`
+	GraphQL       GraphQLRequest  `json:"graphql"`
+	Response      GraphQLResponse `json:"response"`
+}
+
+// GraphQLRequest is the request a GraphQLMock sends. The document is given
+// inline as Query or in QueryFile, relative to the contract.
+type GraphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	QueryFile     string                 `json:"queryFile,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Headers       map[string]string      `json:"headers,omitempty"`
+}
+
+// GraphQLResponse is the response a consumer expects. Errors lists the
+// errors the consumer expects by message; when it is empty the provider
+// must answer without errors.
+type GraphQLResponse struct {
+	Data          map[string]interface{} `json:"data,omitempty"`
+	Errors        []GraphQLError         `json:"errors,omitempty"`
+	MatchingRules MatchingRules          `json:"matchingRules,omitempty"`
+}
+
+// GraphQLError is one entry of a response's errors.
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// document returns the mock's query document, reading QueryFile if the
+// query isn't inline.
+func (m GraphQLMock) document(mockPath string) (string, error) {
+	if m.GraphQL.Query != "" || m.GraphQL.QueryFile == "" {
+		return m.GraphQL.Query, nil
+	}
+	path := m.GraphQL.QueryFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(mockPath), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read query file: %w", err)
+	}
+	return string(data), nil
+}
+
+// operationPath names the mock's operation in issue paths.
+func (m GraphQLMock) operationPath() string {
+	if m.GraphQL.OperationName != "" {
+		return "graphql " + m.GraphQL.OperationName
+	}
+	return "graphql"
+}
+
+// MatchGraphQL checks a consumer's GraphQL expectation against the
+// provider's schema: the query must be valid for the schema and the
+// variables, and the expected data must have the shape the query selects.
+// Deprecated fields the query uses are reported as warnings. sdl may be nil
+// if the provider has no GraphQL schema.
+func MatchGraphQL(sdl *schema.GraphQLSchema, mock GraphQLMock, mockPath string) MatchResult {
+	result := MatchResult{
+		Mock: Mock{
+			Provider:      mock.Provider,
+			Consumer:      mock.Consumer,
+			Description:   mock.Description,
+			ProviderState: mock.ProviderState,
+		},
+		GraphQL:      &mock,
+		MockPath:     mockPath,
+		IsCompatible: true,
+		Issues:       []Issue{},
+	}
+	path := mock.operationPath()
+	add := func(code, issuePath, severity, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, Issue{
+			Code:        code,
+			Path:        issuePath,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	if sdl == nil {
+		add(CodeGraphQLSchemaMissing, path, "error", "Provider %s has no GraphQL schema", mock.Provider)
+		result.IsCompatible = false
+		return result
+	}
+
+	document, err := mock.document(mockPath)
+	if err == nil && strings.TrimSpace(document) == "" {
+		err = fmt.Errorf("contract has no query")
+	}
+	var query *schema.GraphQLQuery
+	if err == nil {
+		query, err = sdl.ParseQuery(document, mock.GraphQL.OperationName)
+	}
+	if err != nil {
+		add(CodeGraphQLQueryInvalid, path, "error", "Query could not be parsed: %v", err)
+		result.IsCompatible = false
+		return result
+	}
+
+	for _, violation := range query.Validate(mock.GraphQL.Variables) {
+		issuePath := strings.TrimSpace(path + " " + violation.Path)
+		switch {
+		case violation.Rule == "deprecated":
+			add(CodeGraphQLDeprecated, issuePath, "warning", "Query uses a deprecated element: %s", violation.Message)
+		case strings.HasPrefix(violation.Path, "variables."):
+			add(CodeGraphQLVariablesInvalid, issuePath, "error", "Variables do not match the query (%s): %s", violation.Rule, violation.Message)
+		default:
+			add(CodeGraphQLQueryInvalid, issuePath, "error", "Query is not valid for the provider schema (%s): %s", violation.Rule, violation.Message)
+		}
+	}
+
+	if mock.Response.Data != nil {
+		for _, violation := range query.ValidateResponse(mock.Response.Data, "response.data") {
+			add(CodeGraphQLResponseInvalid, path+" "+violation.Path, "error",
+				"Expected response does not match the selection set (%s): %s", violation.Rule, violation.Message)
+		}
+	}
+	for i, expected := range mock.Response.Errors {
+		if expected.Message == "" {
+			add(CodeGraphQLResponseInvalid, fmt.Sprintf("%s response.errors[%d]", path, i), "error", "Expected error has no message")
+		}
+	}
+
+	result.Issues = append(result.Issues, checkMatchingRules(schema.NewSpec(nil), path+" response.", "data",
+		mock.Response.MatchingRules, mock.Response.Data, query.ResponseSchema())...)
+
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	return result
+}
+
+// GraphQLVerifier posts consumers' operations to a provider's GraphQL
+// endpoint and checks its responses against their expectations.
+type GraphQLVerifier struct {
+	endpoint string
+	client   *http.Client
+	limiter  *rateLimiter
+}
+
+// NewGraphQLVerifier creates a verifier for the GraphQL endpoint at the
+// given URL. Requests are limited to requestsPerSecond; zero or less means
+// unlimited.
+func NewGraphQLVerifier(endpoint string, requestsPerSecond float64) *GraphQLVerifier {
+	return &GraphQLVerifier{
+		endpoint: endpoint,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		limiter: newRateLimiter(requestsPerSecond),
+	}
+}
+
+// Verify posts the mock's operation and reports every way the response
+// differs from the mock's: errors the consumer did not expect or that are
+// missing, and data that does not match.
+func (g *GraphQLVerifier) Verify(mock GraphQLMock, mockPath string) []Issue {
+	path := mock.operationPath()
+	failed := func(err error) []Issue {
+		return []Issue{{
+			Code:        CodeGraphQLRequestFailed,
+			Path:        path,
+			Description: fmt.Sprintf("Request to provider failed: %v", err),
+			Severity:    "error",
+		}}
+	}
+
+	document, err := mock.document(mockPath)
+	if err != nil {
+		return failed(err)
+	}
+	payload := map[string]interface{}{"query": document}
+	if mock.GraphQL.OperationName != "" {
+		payload["operationName"] = mock.GraphQL.OperationName
+	}
+	if len(mock.GraphQL.Variables) > 0 {
+		payload["variables"] = mock.GraphQL.Variables
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return failed(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return failed(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	for name, value := range mock.GraphQL.Headers {
+		req.Header.Set(name, value)
+	}
+
+	g.limiter.Wait()
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return failed(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return failed(fmt.Errorf("failed to read response: %w", err))
+	}
+
+	// Servers answer errors in validation or execution with a JSON body
+	// whatever the HTTP status, so the body decides the outcome.
+	var actual struct {
+		Data   interface{}    `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		return failed(fmt.Errorf("provider answered HTTP %d without a GraphQL response", resp.StatusCode))
+	}
+
+	var issues []Issue
+	if len(mock.Response.Errors) == 0 && len(actual.Errors) > 0 {
+		messages := make([]string, len(actual.Errors))
+		for i, e := range actual.Errors {
+			messages[i] = e.Message
+		}
+		issues = append(issues, Issue{
+			Code:        CodeGraphQLErrorsMismatch,
+			Path:        path + " response.errors",
+			Description: fmt.Sprintf("Provider returned errors the mock does not expect: %s", strings.Join(messages, "; ")),
+			Severity:    "error",
+		})
+	}
+	for i, expected := range mock.Response.Errors {
+		if !containsGraphQLError(actual.Errors, expected) {
+			issues = append(issues, Issue{
+				Code:        CodeGraphQLErrorsMismatch,
+				Path:        fmt.Sprintf("%s response.errors[%d]", path, i),
+				Description: fmt.Sprintf("Provider did not return the expected error %q", expected.Message),
+				Severity:    "error",
+			})
+		}
+	}
+
+	if mock.Response.Data != nil {
+		var expected interface{} = mock.Response.Data
+		for _, mismatch := range CompareBody("data", expected, actual.Data, mock.Response.MatchingRules) {
+			issues = append(issues, Issue{
+				Code:        CodeGraphQLDataMismatch,
+				Path:        fmt.Sprintf("%s response.%s", path, mismatch.Path),
+				Description: mismatch.Description,
+				Severity:    "error",
+			})
+		}
+	}
+	return issues
+}
+
+// containsGraphQLError reports whether errors include one with the expected
+// message and, if the expectation has one, the same path.
+func containsGraphQLError(errors []GraphQLError, expected GraphQLError) bool {
+	for _, e := range errors {
+		if e.Message != expected.Message {
+			continue
+		}
+		if len(expected.Path) == 0 || fmt.Sprint(e.Path) == fmt.Sprint(expected.Path) {
+			return true
+		}
+	}
+	return false
+}
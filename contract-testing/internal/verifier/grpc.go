@@ -0,0 +1,392 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"golang.org/x/net/http2"
+)
+
+// Issue codes reported for gRPC contracts.
+const (
+	CodeProtoMissing         = "proto-missing"
+	CodeProtoInvalid         = "proto-invalid"
+	CodeGRPCMethodNotFound   = "grpc-method-not-found"
+	CodeGRPCStreaming        = "grpc-streaming"
+	CodeGRPCMessageInvalid   = "grpc-message-invalid"
+	CodeGRPCStatusInvalid    = "grpc-status-invalid"
+	CodeProtoFieldMismatch   = "proto-field-mismatch"
+	CodeProtoFieldRenamed    = "proto-field-renamed"
+	CodeGRPCCallFailed       = "grpc-call-failed"
+	CodeGRPCStatusMismatch   = "grpc-status-mismatch"
+	CodeGRPCResponseMismatch = "grpc-response-mismatch"
+)
+
+// GRPCMock is a consumer's expectation of one call to a provider's gRPC
+// method, with the request and response messages written as proto3 JSON.
+// Matching rules apply to response paths such as "message.orderId".
+type GRPCMock struct {
+	Provider      string                 `json:"provider"`
+	Consumer      string                 `json:"consumer"`
+	Description   string                 `json:"description"`
+	ProviderState string                 `json:"providerState,omitempty"`
+	GRPC          GRPCCall               `json:"grpc"`
+	Request       map[string]interface{} `json:"request"`
+	Response      GRPCResponse           `json:"response"`
+}
+
+// GRPCCall names the method a GRPCMock calls. Proto optionally points to the
+// consumer's copy of the .proto file, relative to the contract, so that its
+// field numbers and types can be checked against the provider's.
+type GRPCCall struct {
+	Method   string            `json:"method"` // e.g. "orders.v1.OrderService/GetOrder"
+	Proto    string            `json:"proto,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// GRPCResponse is the reply a consumer expects: a status code name such as
+// "OK" or "NOT_FOUND", and for OK the response message.
+type GRPCResponse struct {
+	Status        string                 `json:"status,omitempty"`
+	Message       map[string]interface{} `json:"message,omitempty"`
+	MatchingRules MatchingRules          `json:"matchingRules,omitempty"`
+}
+
+// grpcCodes are the gRPC status codes by name.
+var grpcCodes = map[string]int{
+	"OK": 0, "CANCELLED": 1, "UNKNOWN": 2, "INVALID_ARGUMENT": 3,
+	"DEADLINE_EXCEEDED": 4, "NOT_FOUND": 5, "ALREADY_EXISTS": 6,
+	"PERMISSION_DENIED": 7, "RESOURCE_EXHAUSTED": 8, "FAILED_PRECONDITION": 9,
+	"ABORTED": 10, "OUT_OF_RANGE": 11, "UNIMPLEMENTED": 12, "INTERNAL": 13,
+	"UNAVAILABLE": 14, "DATA_LOSS": 15, "UNAUTHENTICATED": 16,
+}
+
+// status returns the expected status code name, "OK" by default.
+func (r GRPCResponse) status() string {
+	if r.Status == "" {
+		return "OK"
+	}
+	return strings.ToUpper(r.Status)
+}
+
+// MatchGRPC checks a consumer's gRPC expectation against the provider's
+// .proto files: the method must exist, the request and response messages
+// must fit its message types and, if the consumer names its copy of the
+// .proto file, the fields it declares must agree with the provider's in
+// number, type and cardinality. spec may be nil if the provider has no
+// .proto files.
+func MatchGRPC(spec *schema.ProtoSpec, mock GRPCMock, mockPath string) MatchResult {
+	result := MatchResult{
+		Mock: Mock{
+			Provider:      mock.Provider,
+			Consumer:      mock.Consumer,
+			Description:   mock.Description,
+			ProviderState: mock.ProviderState,
+		},
+		GRPC:         &mock,
+		MockPath:     mockPath,
+		IsCompatible: true,
+		Issues:       []Issue{},
+	}
+	path := "rpc " + mock.GRPC.Method
+	add := func(code, issuePath, severity, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, Issue{
+			Code:        code,
+			Path:        issuePath,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	if spec == nil {
+		add(CodeProtoMissing, path, "error", "Provider %s has no .proto files describing its gRPC services", mock.Provider)
+		result.IsCompatible = false
+		return result
+	}
+
+	method, ok := spec.Method(mock.GRPC.Method)
+	if !ok {
+		add(CodeGRPCMethodNotFound, path, "error", "Method not found in provider .proto files")
+		result.IsCompatible = false
+		return result
+	}
+	if method.ClientStreaming || method.ServerStreaming {
+		add(CodeGRPCStreaming, path, "warning", "Method streams messages; only the first request and response are checked and live verification is skipped")
+	}
+
+	for _, violation := range spec.ValidateJSON(method.Input, mock.Request, "request") {
+		add(CodeGRPCMessageInvalid, fmt.Sprintf("%s %s", path, violation.Path), "error",
+			"Request does not match %s (%s): %s", method.Input, violation.Rule, violation.Message)
+	}
+
+	var messageSchema interface{}
+	status := mock.Response.status()
+	if _, known := grpcCodes[status]; !known {
+		add(CodeGRPCStatusInvalid, path+" response.status", "error", "%q is not a gRPC status code", mock.Response.Status)
+	} else if status == "OK" {
+		for _, violation := range spec.ValidateJSON(method.Output, mock.Response.Message, "response.message") {
+			add(CodeGRPCMessageInvalid, fmt.Sprintf("%s %s", path, violation.Path), "error",
+				"Response does not match %s (%s): %s", method.Output, violation.Rule, violation.Message)
+		}
+		messageSchema = spec.JSONSchema(method.Output)
+	}
+
+	result.Issues = append(result.Issues, checkMatchingRules(schema.NewSpec(nil), path+" response.", "message",
+		mock.Response.MatchingRules, mock.Response.Message, messageSchema)...)
+
+	if mock.GRPC.Proto != "" {
+		result.Issues = append(result.Issues, compareConsumerProto(spec, method, mock, mockPath)...)
+	}
+
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	return result
+}
+
+// compareConsumerProto checks the consumer's copy of the method's message
+// types against the provider's.
+func compareConsumerProto(spec *schema.ProtoSpec, method schema.ProtoMethod, mock GRPCMock, mockPath string) []Issue {
+	path := "rpc " + mock.GRPC.Method
+	protoPath := mock.GRPC.Proto
+	if !filepath.IsAbs(protoPath) {
+		protoPath = filepath.Join(filepath.Dir(mockPath), protoPath)
+	}
+
+	consumer, err := schema.ParseProtoFiles(protoPath)
+	if err != nil {
+		return []Issue{{
+			Code:        CodeProtoInvalid,
+			Path:        path,
+			Description: fmt.Sprintf("Consumer .proto file could not be read: %v", err),
+			Severity:    "error",
+		}}
+	}
+	ours, ok := consumer.Method(mock.GRPC.Method)
+	if !ok {
+		return []Issue{{
+			Code:        CodeGRPCMethodNotFound,
+			Path:        path,
+			Description: fmt.Sprintf("Method not found in consumer .proto file %s", mock.GRPC.Proto),
+			Severity:    "error",
+		}}
+	}
+
+	var issues []Issue
+	for _, side := range []struct {
+		name           string
+		ours, provided string
+	}{
+		{"request", ours.Input, method.Input},
+		{"response.message", ours.Output, method.Output},
+	} {
+		for _, violation := range spec.CompareMessage(consumer, side.ours, side.provided, side.name) {
+			issue := Issue{
+				Code:        CodeProtoFieldMismatch,
+				Path:        fmt.Sprintf("%s %s", path, violation.Path),
+				Description: fmt.Sprintf("Consumer .proto disagrees with the provider (%s): %s", violation.Rule, violation.Message),
+				Severity:    "error",
+			}
+			switch violation.Rule {
+			case "field-name":
+				issue.Code = CodeProtoFieldRenamed
+				issue.Severity = "warning"
+			case "type-compatible":
+				issue.Severity = "warning"
+			}
+			issues = append(issues, issue)
+		}
+	}
+	if ours.ClientStreaming != method.ClientStreaming || ours.ServerStreaming != method.ServerStreaming {
+		issues = append(issues, Issue{
+			Code:        CodeProtoFieldMismatch,
+			Path:        path,
+			Description: "Consumer .proto disagrees with the provider on whether the method streams",
+			Severity:    "error",
+		})
+	}
+	return issues
+}
+
+// GRPCVerifier calls a provider's unary gRPC methods and checks its replies
+// against the consumers' expectations. Messages are encoded and decoded
+// with the provider's parsed .proto files, so no generated code is needed.
+type GRPCVerifier struct {
+	baseURL string
+	client  *http.Client
+	limiter *rateLimiter
+}
+
+// NewGRPCVerifier creates a verifier for the gRPC server at address, given
+// as host:port or as an http:// or https:// URL. Plain addresses and http://
+// URLs are called over HTTP/2 without TLS. Calls are limited to
+// requestsPerSecond; zero or less means unlimited.
+func NewGRPCVerifier(address string, requestsPerSecond float64) *GRPCVerifier {
+	baseURL := strings.TrimSuffix(address, "/")
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	transport := &http2.Transport{}
+	if strings.HasPrefix(baseURL, "http://") {
+		transport.AllowHTTP = true
+		transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	return &GRPCVerifier{
+		baseURL: baseURL,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+		limiter: newRateLimiter(requestsPerSecond),
+	}
+}
+
+// Verify calls the mock's method with its request and reports every way the
+// reply differs from the mock's response.
+func (g *GRPCVerifier) Verify(spec *schema.ProtoSpec, mock GRPCMock) []Issue {
+	path := "rpc " + mock.GRPC.Method
+	method, ok := spec.Method(mock.GRPC.Method)
+	if !ok || method.ClientStreaming || method.ServerStreaming {
+		return nil // Reported by MatchGRPC
+	}
+	failed := func(err error) []Issue {
+		return []Issue{{
+			Code:        CodeGRPCCallFailed,
+			Path:        path,
+			Description: fmt.Sprintf("Call to provider failed: %v", err),
+			Severity:    "error",
+		}}
+	}
+
+	request, err := spec.EncodeJSON(method.Input, mock.Request)
+	if err != nil {
+		return failed(fmt.Errorf("failed to encode request: %w", err))
+	}
+
+	status, statusMessage, reply, err := g.invoke(method.Path(), mock.GRPC.Metadata, request)
+	if err != nil {
+		return failed(err)
+	}
+
+	var issues []Issue
+	expected := mock.Response.status()
+	if status != expected {
+		description := fmt.Sprintf("Provider returned %s, mock expects %s", status, expected)
+		if statusMessage != "" {
+			description += fmt.Sprintf(" (%s)", statusMessage)
+		}
+		issues = append(issues, Issue{
+			Code:        CodeGRPCStatusMismatch,
+			Path:        path,
+			Description: description,
+			Severity:    "error",
+		})
+	}
+	if status != "OK" || expected != "OK" {
+		return issues
+	}
+
+	actual, err := spec.DecodeJSON(method.Output, reply)
+	if err != nil {
+		return append(issues, failed(fmt.Errorf("failed to decode response: %w", err))...)
+	}
+	want := spec.NormalizeJSON(method.Output, mock.Response.Message)
+	for _, mismatch := range CompareBody("message", want, actual, mock.Response.MatchingRules) {
+		issues = append(issues, Issue{
+			Code:        CodeGRPCResponseMismatch,
+			Path:        fmt.Sprintf("%s response.%s", path, mismatch.Path),
+			Description: mismatch.Description,
+			Severity:    "error",
+		})
+	}
+	return issues
+}
+
+// invoke makes a unary call and returns the status code name and message
+// and the response message bytes.
+func (g *GRPCVerifier) invoke(methodPath string, metadata map[string]string, message []byte) (string, string, []byte, error) {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+
+	req, err := http.NewRequest(http.MethodPost, g.baseURL+methodPath, bytes.NewReader(frame))
+	if err != nil {
+		return "", "", nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	for name, value := range metadata {
+		req.Header.Set(name, value)
+	}
+
+	g.limiter.Wait()
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", nil, fmt.Errorf("provider answered HTTP %d", resp.StatusCode)
+	}
+
+	// Trailers-only replies carry the status in the headers.
+	rawStatus := resp.Trailer.Get("Grpc-Status")
+	statusMessage := resp.Trailer.Get("Grpc-Message")
+	if rawStatus == "" {
+		rawStatus = resp.Header.Get("Grpc-Status")
+		statusMessage = resp.Header.Get("Grpc-Message")
+	}
+	if rawStatus == "" {
+		return "", "", nil, fmt.Errorf("reply has no grpc-status")
+	}
+	code, err := strconv.Atoi(rawStatus)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid grpc-status %q", rawStatus)
+	}
+	status := fmt.Sprintf("CODE_%d", code)
+	for name, value := range grpcCodes {
+		if value == code {
+			status = name
+		}
+	}
+	if decoded, err := url.PathUnescape(statusMessage); err == nil {
+		statusMessage = decoded
+	}
+
+	if len(body) == 0 {
+		return status, statusMessage, nil, nil
+	}
+	if len(body) < 5 {
+		return "", "", nil, fmt.Errorf("truncated response message")
+	}
+	if body[0] != 0 {
+		return "", "", nil, fmt.Errorf("compressed responses are not supported")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < length {
+		return "", "", nil, fmt.Errorf("truncated response message")
+	}
+	return status, statusMessage, body[5 : 5+length], nil
+}
@@ -0,0 +1,316 @@
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Issue codes reported by live verification.
+const (
+	CodeLiveRequestFailed  = "live-request-failed"
+	CodeLiveStatusMismatch = "live-status-mismatch"
+	CodeLiveHeaderMismatch = "live-header-mismatch"
+	CodeLiveHeaderInvalid  = "live-header-invalid"
+	CodeLiveBodyMismatch   = "live-body-mismatch"
+)
+
+// LiveVerifier replays mock requests against a running provider and checks
+// its responses against the mocks' expectations.
+type LiveVerifier struct {
+	baseURL string
+	client  *http.Client
+	limiter *rateLimiter
+	spec    *schema.Spec
+}
+
+// NewLiveVerifier creates a live verifier for one provider. Requests are
+// limited to requestsPerSecond; zero or less means unlimited.
+func NewLiveVerifier(baseURL string, requestsPerSecond float64) *LiveVerifier {
+	return &LiveVerifier{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		limiter: newRateLimiter(requestsPerSecond),
+		spec:    schema.NewSpec(nil),
+	}
+}
+
+// WithSpec sets the provider's schema, whose media types decide how
+// non-JSON request bodies are encoded and response bodies decoded: XML
+// element names and the types of form, multipart and XML values.
+func (l *LiveVerifier) WithSpec(spec *schema.Spec) *LiveVerifier {
+	l.spec = spec
+	return l
+}
+
+// Verify sends the mock's request to the provider and reports every way the
+// actual response differs from the mock's response.
+func (l *LiveVerifier) Verify(mock Mock) []Issue {
+	issues, _ := l.verify(mock)
+	return issues
+}
+
+// verify is Verify that also returns the decoded response body, or nil if
+// the request failed or the body couldn't be decoded.
+func (l *LiveVerifier) verify(mock Mock) ([]Issue, interface{}) {
+	method := strings.ToLower(mock.Request.Method)
+	endpoint := mock.Request.Endpoint
+
+	op, _, _ := l.spec.FindOperation(method, endpoint)
+	var requestContent map[string]interface{}
+	if op != nil {
+		requestContent, _, _ = op.RequestBody()
+	}
+	req, err := buildLiveRequest(l.baseURL, mock.Request, l.spec, requestContent)
+	if err != nil {
+		return []Issue{liveRequestFailed(method, endpoint, err)}, nil
+	}
+
+	l.limiter.Wait()
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return []Issue{liveRequestFailed(method, endpoint, err)}, nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []Issue{liveRequestFailed(method, endpoint, err)}, nil
+	}
+
+	var issues []Issue
+	actual, bodyErr := l.decodeBody(op, resp, data)
+
+	if !expectsStatus(mock.Response, resp.StatusCode) {
+		issues = append(issues, Issue{
+			Code:        CodeLiveStatusMismatch,
+			Path:        fmt.Sprintf("%s %s response.statusCode", method, endpoint),
+			Description: fmt.Sprintf("Provider returned status %d, mock expects %s", resp.StatusCode, describeStatus(mock.Response)),
+			Severity:    "error",
+		})
+	}
+
+	for _, name := range sortedKeys(mock.Response.Headers) {
+		expected := mock.Response.Headers[name]
+		actual := resp.Header.Get(name)
+		if !HeaderMatches(name, expected, actual) {
+			issues = append(issues, Issue{
+				Code:        CodeLiveHeaderMismatch,
+				Path:        fmt.Sprintf("%s %s response.headers.%s", method, endpoint, name),
+				Description: fmt.Sprintf("Provider returned %q, mock expects %q", actual, expected),
+				Severity:    "error",
+			})
+		}
+	}
+
+	if op != nil {
+		for _, violation := range op.ValidateResponseHeaders(schema.ResponseData{Status: resp.StatusCode, Header: resp.Header, Body: data}) {
+			issues = append(issues, Issue{
+				Code:        CodeLiveHeaderInvalid,
+				Path:        fmt.Sprintf("%s %s response.%s", method, endpoint, violation.Path),
+				Description: fmt.Sprintf("Provider response breaks its own schema (%s): %s", violation.Rule, violation.Message),
+				Severity:    "error",
+			})
+		}
+	}
+
+	if mock.Response.Body != nil {
+		if bodyErr != nil {
+			issues = append(issues, Issue{
+				Code:        CodeLiveBodyMismatch,
+				Path:        fmt.Sprintf("%s %s response.body", method, endpoint),
+				Description: fmt.Sprintf("Provider response %v", bodyErr),
+				Severity:    "error",
+			})
+		} else {
+			var expected interface{} = mock.Response.Body
+			for _, mismatch := range CompareBody("body", expected, actual, mock.Response.MatchingRules) {
+				issues = append(issues, Issue{
+					Code:        CodeLiveBodyMismatch,
+					Path:        fmt.Sprintf("%s %s response.%s", method, endpoint, mismatch.Path),
+					Description: mismatch.Description,
+					Severity:    "error",
+				})
+			}
+		}
+	}
+
+	return issues, actual
+}
+
+// decodeBody decodes a response body as its Content-Type, using the
+// schema of the media type the operation declares for it, if any. Bodies
+// without a Content-Type are decoded as JSON.
+func (l *LiveVerifier) decodeBody(op *schema.Operation, resp *http.Response, data []byte) (interface{}, error) {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	var media interface{}
+	if op != nil {
+		if response, ok := op.Response(resp.StatusCode); ok {
+			_, media, _ = schema.SelectMediaType(contentOf(response["content"]), contentType)
+		}
+	}
+
+	value, violations, ok := l.spec.DecodeBody(contentType, media, data)
+	if !ok {
+		return nil, fmt.Errorf("has media type %s, which cannot be compared", mediaType(contentType))
+	}
+	for _, violation := range violations {
+		if violation.Rule == "syntax" {
+			return nil, fmt.Errorf("%s", violation.Message)
+		}
+	}
+	return value, nil
+}
+
+// buildLiveRequest turns a mock request into an HTTP request against baseURL.
+// Parameters that appear as {name} in the endpoint fill the path and the rest
+// become query parameters. The body is encoded as the media type the mock's
+// Content-Type names, using the declared request content for XML names and
+// multipart part types.
+func buildLiveRequest(baseURL string, mockReq MockRequest, spec *schema.Spec, content map[string]interface{}) (*http.Request, error) {
+	path := mockReq.Endpoint
+	query := url.Values{}
+
+	for _, name := range sortedKeys(mockReq.Parameters) {
+		placeholder := "{" + name + "}"
+		if strings.Contains(path, placeholder) {
+			path = strings.ReplaceAll(path, placeholder, url.PathEscape(mockReq.Parameters[name]))
+		} else {
+			query.Set(name, mockReq.Parameters[name])
+		}
+	}
+
+	if strings.Contains(path, "{") {
+		return nil, fmt.Errorf("no parameter value for path %s", path)
+	}
+
+	target := baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	if mockReq.Body != nil {
+		data, sentAs, err := encodeMockBody(spec, content, mockReq.Headers, mockReq.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = sentAs
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(mockReq.Method), target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, value := range mockReq.Headers {
+		req.Header.Set(name, value)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// HeaderMatches compares header values. Content-Type only has to match up to
+// its parameters, so "application/json" matches "application/json; charset=utf-8".
+func HeaderMatches(name, expected, actual string) bool {
+	if strings.EqualFold(name, "Content-Type") {
+		return strings.EqualFold(mediaType(expected), mediaType(actual))
+	}
+	return expected == actual
+}
+
+func mediaType(contentType string) string {
+	return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func liveRequestFailed(method, endpoint string, err error) Issue {
+	return Issue{
+		Code:        CodeLiveRequestFailed,
+		Path:        fmt.Sprintf("%s %s", method, endpoint),
+		Description: fmt.Sprintf("Request to provider failed: %v", err),
+		Severity:    "error",
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rateLimiter spaces calls to Wait evenly so that at most a fixed number of
+// requests per second reach a provider, however many workers share it.
+type rateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+// Wait blocks until the caller may send its next request.
+func (l *rateLimiter) Wait() {
+	if l.interval == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
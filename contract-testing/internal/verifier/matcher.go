@@ -3,51 +3,91 @@ package verifier
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
 )
 
 type MockRequest struct {
-	Method   string                 `json:"method"`
-	Endpoint string                 `json:"endpoint"`
-	Headers  map[string]string      `json:"headers"`
-	Body     map[string]interface{} `json:"body"`
+	Method        string            `json:"method"`
+	Endpoint      string            `json:"endpoint"`
+	Headers       map[string]string `json:"headers"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	Body          interface{}       `json:"body"`
+	MatchingRules MatchingRules     `json:"matchingRules,omitempty"`
 }
 
 type MockResponse struct {
-	StatusCode int                    `json:"statusCode"`
-	Headers    map[string]string      `json:"headers"`
-	Body       map[string]interface{} `json:"body"`
+	StatusCode int `json:"statusCode"`
+	// StatusRange, such as "4XX", accepts any status of its class. The
+	// statusCode, if set, must fall in it and is the one stubs answer with.
+	StatusRange   string            `json:"statusRange,omitempty"`
+	Headers       map[string]string `json:"headers"`
+	Body          interface{}       `json:"body"`
+	MatchingRules MatchingRules     `json:"matchingRules,omitempty"`
 }
 
 type Mock struct {
-	Provider     string       `json:"provider"`
-	Consumer     string       `json:"consumer"`
-	Description  string       `json:"description"`
-	Request      MockRequest  `json:"request"`
-	Response     MockResponse `json:"response"`
-	Dependencies []string     `json:"dependencies"`
+	Provider      string       `json:"provider"`
+	Consumer      string       `json:"consumer"`
+	Description   string       `json:"description"`
+	ProviderState string       `json:"providerState,omitempty"`
+	Request       MockRequest  `json:"request"`
+	Response      MockResponse `json:"response"`
+	Dependencies  []string     `json:"dependencies"`
+	// Captures name values of the live response, as JSONPath expressions
+	// such as $.orderId, that dependent mocks use as {{name}}.
+	Captures map[string]string `json:"captures,omitempty"`
 }
 
 type MatchResult struct {
-	Mock         Mock    `json:"mock"`
-	IsCompatible bool    `json:"isCompatible"`
-	Issues       []Issue `json:"issues"`
+	Mock         Mock             `json:"mock"`
+	Message      *Message         `json:"message,omitempty"` // set for message contracts
+	GRPC         *GRPCMock        `json:"grpc,omitempty"`    // set for gRPC contracts
+	GraphQL      *GraphQLMock     `json:"graphql,omitempty"` // set for GraphQL contracts
+	Webhook      *WebhookContract `json:"webhook,omitempty"` // set for webhook contracts
+	Stream       *StreamMock      `json:"stream,omitempty"`  // set for stream contracts
+	MockPath     string           `json:"mockPath"`
+	IsCompatible bool             `json:"isCompatible"`
+	Issues       []Issue          `json:"issues"`
+	Waived       []WaivedIssue    `json:"waived,omitempty"`
 }
 
 type Issue struct {
+	Code        string `json:"code"`
 	Path        string `json:"path"`
 	Description string `json:"description"`
 	Severity    string `json:"severity"` // "error", "warning"
 }
 
+// Issue codes identify the contract rule an issue violates. They are stable
+// across runs so that baselines and waivers can refer to them.
+const (
+	CodeSchemaNoPaths             = "schema-no-paths"
+	CodeEndpointNotFound          = "endpoint-not-found"
+	CodeMethodNotSupported        = "method-not-supported"
+	CodeResponseSchemaMissing     = "response-schema-missing"
+	CodeStatusNotDefined          = "status-not-defined"
+	CodeRequestBodyInvalid        = "request-body-invalid"
+	CodeResponseBodyInvalid       = "response-body-invalid"
+	CodeMatchingRuleInvalid       = "matching-rule-invalid"
+	CodeMatchingRuleUnsatisfiable = "matching-rule-unsatisfiable"
+	CodeRequestHeaderInvalid      = "request-header-invalid"
+	CodeResponseHeaderInvalid     = "response-header-invalid"
+	CodeResponseHeaderUndeclared  = "response-header-undeclared"
+)
+
 type Matcher struct {
 	schema map[string]interface{}
+	spec   *schema.Spec
 }
 
-func NewMatcher(schema map[string]interface{}) *Matcher {
+func NewMatcher(document map[string]interface{}) *Matcher {
 	return &Matcher{
-		schema: schema,
+		schema: document,
+		spec:   schema.NewSpec(document),
 	}
 }
 
@@ -62,8 +102,14 @@ func (m *Matcher) MatchMock(mockPath string) (MatchResult, error) {
 		return MatchResult{}, fmt.Errorf("failed to parse mock: %w", err)
 	}
 	
+	return m.Match(mock, mockPath), nil
+}
+
+// Match checks an already parsed mock, read from mockPath, against the schema.
+func (m *Matcher) Match(mock Mock, mockPath string) MatchResult {
 	result := MatchResult{
 		Mock:         mock,
+		MockPath:     mockPath,
 		IsCompatible: true,
 		Issues:       []Issue{},
 	}
@@ -73,11 +119,12 @@ func (m *Matcher) MatchMock(mockPath string) (MatchResult, error) {
 	if paths == nil {
 		result.IsCompatible = false
 		result.Issues = append(result.Issues, Issue{
+			Code:        CodeSchemaNoPaths,
 			Path:        "",
 			Description: "Schema does not contain paths",
 			Severity:    "error",
 		})
-		return result, nil
+		return result
 	}
 	
 	// Find the matching endpoint in the schema
@@ -98,66 +145,191 @@ func (m *Matcher) MatchMock(mockPath string) (MatchResult, error) {
 	if !found {
 		result.IsCompatible = false
 		result.Issues = append(result.Issues, Issue{
+			Code:        CodeEndpointNotFound,
 			Path:        endpoint,
 			Description: "Endpoint not found in provider schema",
 			Severity:    "error",
 		})
-		return result, nil
+		return result
 	}
 	
 	// Check if the method is supported
-	methodItem, found := pathItem[method]
+	_, found = pathItem[method]
 	if !found {
 		result.IsCompatible = false
 		result.Issues = append(result.Issues, Issue{
+			Code:        CodeMethodNotSupported,
 			Path:        fmt.Sprintf("%s %s", method, endpoint),
 			Description: "Method not supported for this endpoint",
 			Severity:    "error",
 		})
-		return result, nil
+		return result
 	}
 	
 	// Validate request body against schema
-	methodMap := methodItem.(map[interface{}]interface{})
-	if requestBody, ok := methodMap["requestBody"].(map[interface{}]interface{}); ok {
-		if content, ok := requestBody["content"].(map[interface{}]interface{}); ok {
-			if jsonContent, ok := content["application/json"].(map[interface{}]interface{}); ok {
-				if _, ok := jsonContent["schema"].(map[interface{}]interface{}); ok {
-					// Here we would validate the mock request body against the schema
-					// For simplicity, we'll just assume it's valid
-				}
-			}
+	op, _ := m.spec.Operation(method, endpoint)
+	if op != nil && mock.Request.Body != nil {
+		content, _, _ := op.RequestBody()
+		body, contentType, err := encodeMockBody(m.spec, content, mock.Request.Headers, mock.Request.Body)
+		if err != nil {
+			result.Issues = append(result.Issues, Issue{
+				Code:        CodeRequestBodyInvalid,
+				Path:        fmt.Sprintf("%s %s request.body", method, endpoint),
+				Description: fmt.Sprintf("Mock request body cannot be sent: %v", err),
+				Severity:    "error",
+			})
+		} else {
+			header := mockHeader(mock.Request.Headers)
+			header.Set("Content-Type", contentType)
+			violations := op.ValidateRequestBody(schema.RequestData{
+				Header: header,
+				Body:   body,
+			})
+			result.Issues = append(result.Issues, violationIssues(CodeRequestBodyInvalid, method, endpoint, "request", violations)...)
 		}
 	}
 	
+	// Validate request headers against the header parameters
+	if op != nil {
+		violations := op.ValidateRequestHeaders(mockHeader(mock.Request.Headers))
+		result.Issues = append(result.Issues, violationIssues(CodeRequestHeaderInvalid, method, endpoint, "request", violations)...)
+	}
+	
+	// Resolve the expected status against the declared responses
+	status := mock.Response.StatusCode
+	if op != nil && len(op.Responses()) > 0 {
+		var issues []Issue
+		status, issues = matchStatus(op, method, endpoint, mock.Response)
+		result.Issues = append(result.Issues, issues...)
+	}
+	
+	// Validate the headers the mock expects against the declared ones
+	if op != nil && status != 0 {
+		result.Issues = append(result.Issues, matchResponseHeaders(op, method, endpoint, status, mock.Response)...)
+	}
+	
+	// Check matching rules against the mock's values and the schema
+	if len(mock.Request.MatchingRules) > 0 {
+		var bodySchema interface{}
+		if op != nil {
+			bodySchema, _ = op.RequestBodySchema(mockHeader(mock.Request.Headers).Get("Content-Type"))
+		}
+		result.Issues = append(result.Issues, checkMatchingRules(m.spec, fmt.Sprintf("%s %s request.", method, endpoint), "body", mock.Request.MatchingRules, mock.Request.Body, bodySchema)...)
+	}
+	if len(mock.Response.MatchingRules) > 0 {
+		var bodySchema interface{}
+		if op != nil {
+			bodySchema, _ = op.ResponseBodySchema(status, mockHeader(mock.Response.Headers).Get("Content-Type"))
+		}
+		result.Issues = append(result.Issues, checkMatchingRules(m.spec, fmt.Sprintf("%s %s response.", method, endpoint), "body", mock.Response.MatchingRules, mock.Response.Body, bodySchema)...)
+	}
+	
 	// Validate response schema
-	if responses, ok := methodMap["responses"].(map[interface{}]interface{}); ok {
-		statusCode := fmt.Sprintf("%d", mock.Response.StatusCode)
-		if response, ok := responses[statusCode].(map[interface{}]interface{}); ok {
-			if content, ok := response["content"].(map[interface{}]interface{}); ok {
-				if jsonContent, ok := content["application/json"].(map[interface{}]interface{}); ok {
-					if _, ok := jsonContent["schema"].(map[interface{}]interface{}); ok {
-						// Validate mock response against schema
-						// Again, for simplicity we'll just assume it's valid
-					} else {
-						result.IsCompatible = false
-						result.Issues = append(result.Issues, Issue{
-							Path:        fmt.Sprintf("%s %s response.body", method, endpoint),
-							Description: "Response schema not defined in provider contract",
-							Severity:    "error",
-						})
-					}
-				}
-			}
-		} else {
+	var response map[interface{}]interface{}
+	if op != nil && status != 0 {
+		response, _ = op.Response(status)
+	}
+	if content := contentOf(response["content"]); len(content) > 0 {
+		mediaType, media, selected := schema.SelectMediaType(content, mockContentType(mock.Response.Headers))
+		mediaNode, _ := media.(map[interface{}]interface{})
+		if _, ok := mediaNode["schema"].(map[interface{}]interface{}); selected && !ok && schema.IsJSONMediaType(mediaType) {
 			result.IsCompatible = false
 			result.Issues = append(result.Issues, Issue{
-				Path:        fmt.Sprintf("%s %s response.statusCode", method, endpoint),
-				Description: fmt.Sprintf("Status code %s not defined in provider contract", statusCode),
+				Code:        CodeResponseSchemaMissing,
+				Path:        fmt.Sprintf("%s %s response.body", method, endpoint),
+				Description: "Response schema not defined in provider contract",
 				Severity:    "error",
 			})
+		} else if mock.Response.Body != nil {
+			body, contentType, err := encodeMockBody(m.spec, content, mock.Response.Headers, mock.Response.Body)
+			if err != nil {
+				result.Issues = append(result.Issues, Issue{
+					Code:        CodeResponseBodyInvalid,
+					Path:        fmt.Sprintf("%s %s response.body", method, endpoint),
+					Description: fmt.Sprintf("Mock response body cannot be sent: %v", err),
+					Severity:    "error",
+				})
+			} else {
+				header := mockHeader(mock.Response.Headers)
+				header.Set("Content-Type", contentType)
+				violations := op.ValidateResponseBody(schema.ResponseData{
+					Status: status,
+					Header: header,
+					Body:   body,
+				})
+				result.Issues = append(result.Issues, violationIssues(CodeResponseBodyInvalid, method, endpoint, "response", violations)...)
+			}
 		}
 	}
 	
-	return result, nil
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	
+	return result
+}
+
+// violationIssues reports schema violations of a mock's request or response
+// body as issues. side is "request" or "response".
+func violationIssues(code, method, endpoint, side string, violations []schema.Violation) []Issue {
+	issues := make([]Issue, 0, len(violations))
+	for _, violation := range violations {
+		issues = append(issues, Issue{
+			Code:        code,
+			Path:        fmt.Sprintf("%s %s %s.%s", method, endpoint, side, violation.Path),
+			Description: fmt.Sprintf("Mock %s does not match the provider schema (%s): %s", side, violation.Rule, violation.Message),
+			Severity:    "error",
+		})
+	}
+	return issues
+}
+
+// matchResponseHeaders checks the headers a mock's response expects against
+// the headers declared on the provider's response for status. Values must
+// match their schemas and Content-Type must be a declared media type.
+// Headers the provider does not declare are reported as warnings, as the
+// provider may still send them; required headers the mock leaves out are not
+// reported, as the consumer does not rely on them.
+func matchResponseHeaders(op *schema.Operation, method, endpoint string, status int, response MockResponse) []Issue {
+	header := mockHeader(response.Headers)
+	var body []byte
+	if response.Body != nil {
+		body, _ = json.Marshal(response.Body)
+	}
+
+	var violations []schema.Violation
+	for _, violation := range op.ValidateResponseHeaders(schema.ResponseData{Status: status, Header: header, Body: body}) {
+		if violation.Rule != "required" {
+			violations = append(violations, violation)
+		}
+	}
+	issues := violationIssues(CodeResponseHeaderInvalid, method, endpoint, "response", violations)
+
+	declared := op.ResponseHeaders(status)
+	for _, name := range sortedKeys(response.Headers) {
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		found := false
+		for _, param := range declared {
+			found = found || strings.EqualFold(param.Name, name)
+		}
+		if !found {
+			issues = append(issues, Issue{
+				Code:        CodeResponseHeaderUndeclared,
+				Path:        fmt.Sprintf("%s %s response.header.%s", method, endpoint, name),
+				Description: "Mock expects a header the provider does not declare",
+				Severity:    "warning",
+			})
+		}
+	}
+	return issues
+}
+
+func mockHeader(headers map[string]string) http.Header {
+	header := make(http.Header, len(headers))
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	return header
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package verifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// mockContentType returns the Content-Type header of a mock's headers,
+// whatever the case of its name.
+func mockContentType(headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Type") {
+			return value
+		}
+	}
+	return ""
+}
+
+// encodeMockBody encodes a mock's body as the media type its Content-Type
+// header names, or else as the media type content prefers, application/json
+// when there is none. The declared media type serving it supplies XML
+// element names and multipart part types. It returns the body and the
+// Content-Type to send it with.
+func encodeMockBody(spec *schema.Spec, content map[string]interface{}, headers map[string]string, body interface{}) ([]byte, string, error) {
+	contentType := mockContentType(headers)
+	mediaType, media, ok := schema.SelectMediaType(content, contentType)
+
+	encodeAs := mediaType
+	if contentType != "" && !strings.Contains(contentType, "*") {
+		encodeAs = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+	if !ok && contentType == "" {
+		encodeAs = "application/json"
+	}
+
+	data, sentAs, err := spec.EncodeBody(encodeAs, media, body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode body as %s: %w", encodeAs, err)
+	}
+	if sentAs == encodeAs && contentType != "" && !strings.Contains(contentType, "*") {
+		sentAs = contentType // Keep parameters such as charset
+	}
+	return data, sentAs, nil
+}
+
+// contentOf returns a request body's or response's content by media type.
+func contentOf(node interface{}) map[string]interface{} {
+	raw, _ := node.(map[interface{}]interface{})
+	content := make(map[string]interface{}, len(raw))
+	for mediaType, media := range raw {
+		content[fmt.Sprint(mediaType)] = media
+	}
+	return content
+}
@@ -0,0 +1,158 @@
+package verifier
+
+import (
+	"fmt"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Issue codes reported for message contracts.
+const (
+	CodeAsyncAPIMissing        = "asyncapi-missing"
+	CodeChannelNotFound        = "channel-not-found"
+	CodeMessageInvalid         = "message-invalid"
+	CodeMessageChannelMismatch = "message-channel-mismatch"
+	CodeMessageHeaderMismatch  = "message-header-mismatch"
+	CodeMessagePayloadMismatch = "message-payload-mismatch"
+	CodeProducerFailed         = "producer-failed"
+)
+
+// Message is a consumer's expectation of an event a provider emits, the
+// asynchronous counterpart of a Mock. Matching rules apply to payload paths
+// such as "payload.orderId".
+type Message struct {
+	Provider      string                 `json:"provider"`
+	Consumer      string                 `json:"consumer"`
+	Description   string                 `json:"description"`
+	ProviderState string                 `json:"providerState,omitempty"`
+	Channel       string                 `json:"channel"`
+	Headers       map[string]string      `json:"headers,omitempty"`
+	Payload       map[string]interface{} `json:"payload"`
+	MatchingRules MatchingRules          `json:"matchingRules,omitempty"`
+}
+
+// ProducedMessage is a message actually emitted by a provider's code, with
+// its payload decoded from JSON.
+type ProducedMessage struct {
+	Channel string
+	Headers map[string]string
+	Payload interface{}
+}
+
+// MatchMessage checks a consumer's message expectation against the
+// provider's AsyncAPI document: the channel must be declared, the expected
+// headers and payload must match one of its message schemas, and the
+// payload's matching rules must be satisfiable by that schema. spec may be
+// nil if the provider has no AsyncAPI document.
+func MatchMessage(spec *schema.AsyncSpec, message Message, messagePath string) MatchResult {
+	result := MatchResult{
+		Mock: Mock{
+			Provider:      message.Provider,
+			Consumer:      message.Consumer,
+			Description:   message.Description,
+			ProviderState: message.ProviderState,
+		},
+		Message:      &message,
+		MockPath:     messagePath,
+		IsCompatible: true,
+		Issues:       []Issue{},
+	}
+
+	if spec == nil {
+		result.Issues = append(result.Issues, Issue{
+			Code:        CodeAsyncAPIMissing,
+			Path:        "channel " + message.Channel,
+			Description: fmt.Sprintf("Provider %s has no AsyncAPI document describing its messages", message.Provider),
+			Severity:    "error",
+		})
+	} else {
+		result.Issues = append(result.Issues, validateMessage(spec, message.Channel, message.Headers, message.Payload, "Expected")...)
+	}
+
+	rulesSpec := schema.NewSpec(nil)
+	var payloadSchema interface{}
+	if spec != nil {
+		declared, _, _ := spec.ClosestMessage(message.Channel, message.Headers, message.Payload)
+		rulesSpec, payloadSchema = spec.Spec(), declared.Payload
+	}
+	result.Issues = append(result.Issues, checkMatchingRules(rulesSpec, "channel "+message.Channel+" ", "payload",
+		message.MatchingRules, message.Payload, payloadSchema)...)
+
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	return result
+}
+
+// VerifyProducedMessage checks a message emitted by the provider against the
+// consumer's expectation and the provider's AsyncAPI document. Payload
+// values are compared literally unless a matching rule applies.
+func VerifyProducedMessage(spec *schema.AsyncSpec, expected Message, actual ProducedMessage) []Issue {
+	var issues []Issue
+	path := "channel " + expected.Channel
+
+	if actual.Channel != expected.Channel {
+		issues = append(issues, Issue{
+			Code:        CodeMessageChannelMismatch,
+			Path:        path,
+			Description: fmt.Sprintf("Producer emitted on %q, consumer expects %q", actual.Channel, expected.Channel),
+			Severity:    "error",
+		})
+	}
+
+	for _, name := range sortedKeys(expected.Headers) {
+		want := expected.Headers[name]
+		got := actual.Headers[name]
+		if !HeaderMatches(name, want, got) {
+			issues = append(issues, Issue{
+				Code:        CodeMessageHeaderMismatch,
+				Path:        fmt.Sprintf("%s headers.%s", path, name),
+				Description: fmt.Sprintf("Producer emitted %q, consumer expects %q", got, want),
+				Severity:    "error",
+			})
+		}
+	}
+
+	if spec != nil {
+		issues = append(issues, validateMessage(spec, actual.Channel, actual.Headers, actual.Payload, "Emitted")...)
+	}
+
+	var want interface{} = expected.Payload
+	for _, mismatch := range CompareBody("payload", want, actual.Payload, expected.MatchingRules) {
+		issues = append(issues, Issue{
+			Code:        CodeMessagePayloadMismatch,
+			Path:        fmt.Sprintf("%s %s", path, mismatch.Path),
+			Description: mismatch.Description,
+			Severity:    "error",
+		})
+	}
+
+	return issues
+}
+
+// validateMessage reports how a message breaks the AsyncAPI document; which
+// names the message in descriptions, e.g. "Expected" or "Emitted".
+func validateMessage(spec *schema.AsyncSpec, channel string, headers map[string]string, payload interface{}, which string) []Issue {
+	path := "channel " + channel
+
+	violations, err := spec.ValidateMessage(channel, headers, payload)
+	if err != nil {
+		return []Issue{{
+			Code:        CodeChannelNotFound,
+			Path:        path,
+			Description: fmt.Sprintf("Channel not found in provider AsyncAPI document: %v", err),
+			Severity:    "error",
+		}}
+	}
+
+	issues := make([]Issue, 0, len(violations))
+	for _, violation := range violations {
+		issues = append(issues, Issue{
+			Code:        CodeMessageInvalid,
+			Path:        fmt.Sprintf("%s %s", path, violation.Path),
+			Description: fmt.Sprintf("%s message does not match the provider AsyncAPI document (%s): %s", which, violation.Rule, violation.Message),
+			Severity:    "error",
+		})
+	}
+	return issues
+}
@@ -6,30 +6,138 @@ import (
 	"os"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
 )
 
+// timestampLayout is how run timestamps are shown in summaries and reports.
+const timestampLayout = "2006-01-02 15:04:05"
+
 type Reporter struct {
-	results *ValidationResult
+	results   *ValidationResult
+	suite     *SuiteResult
+	sortKeys  []string
+	timestamp time.Time
 }
 
 func NewReporter(results *ValidationResult) *Reporter {
 	return &Reporter{
-		results: results,
+		results:  results,
+		sortKeys: DefaultSortKeys,
+	}
+}
+
+// NewSuiteReporter creates a reporter for the combined results of several providers.
+func NewSuiteReporter(suite *SuiteResult) *Reporter {
+	return &Reporter{
+		suite:    suite,
+		sortKeys: DefaultSortKeys,
 	}
 }
 
+// WithSort orders consumers, mocks and issues by the given keys before the
+// defaults, which make the order fully deterministic.
+func (r *Reporter) WithSort(keys []string) *Reporter {
+	r.sortKeys = keys
+	return r
+}
+
+// WithTimestamp replaces the run timestamps shown in the output, so that
+// reproducible builds can produce byte-identical reports.
+func (r *Reporter) WithTimestamp(timestamp time.Time) *Reporter {
+	r.timestamp = timestamp
+	return r
+}
+
 func (r *Reporter) GenerateSummary() string {
-	if r.results == nil {
+	if r.results == nil && r.suite == nil {
 		return "No validation results available"
 	}
 	
+	r.prepare()
+	
+	if r.suite != nil {
+		return r.generateSuiteSummary()
+	}
+	
+	return r.summarizeResult(r.results)
+}
+
+// prepare applies the timestamp override and sorts the results in place.
+func (r *Reporter) prepare() {
+	results := []*ValidationResult{r.results}
+	if r.suite != nil {
+		results = r.suite.Results
+		if !r.timestamp.IsZero() {
+			r.suite.Timestamp = r.timestamp
+		}
+	}
+	
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if !r.timestamp.IsZero() {
+			result.Timestamp = r.timestamp
+		}
+		sortResult(result, r.sortKeys)
+	}
+}
+
+// GenerateMatrix renders the consumer × provider matrix of a suite as a table.
+func (r *Reporter) GenerateMatrix() string {
+	if r.suite == nil {
+		return ""
+	}
+	
+	matrix := r.suite.Matrix()
+	
+	var sb strings.Builder
+	table := tablewriter.NewWriter(&sb)
+	table.SetHeader(append([]string{"Consumer"}, matrix.Providers...))
+	table.SetAutoFormatHeaders(false)
+	
+	for _, consumer := range matrix.Consumers {
+		row := []string{consumer}
+		for _, provider := range matrix.Providers {
+			row = append(row, matrix.Status(consumer, provider))
+		}
+		table.Append(row)
+	}
+	
+	table.Render()
+	return sb.String()
+}
+
+func (r *Reporter) generateSuiteSummary() string {
+	var sb strings.Builder
+	
+	for _, result := range r.suite.Results {
+		sb.WriteString(r.summarizeResult(result))
+		sb.WriteString("\n")
+	}
+	
+	sb.WriteString(fmt.Sprintf("Compatibility Matrix (%d providers):\n", len(r.suite.Results)))
+	sb.WriteString(r.GenerateMatrix())
+	
+	if r.suite.OverallSuccess {
+		sb.WriteString("\n✅ Overall: All providers are compatible with their consumers\n")
+	} else {
+		sb.WriteString("\n❌ Overall: Some providers are incompatible with their consumers\n")
+	}
+	
+	return sb.String()
+}
+
+func (r *Reporter) summarizeResult(result *ValidationResult) string {
 	var sb strings.Builder
 	
-	sb.WriteString(fmt.Sprintf("Validation Results for Provider: %s\n", r.results.ProviderName))
-	sb.WriteString(fmt.Sprintf("Schema: %s\n", r.results.SchemaPath))
-	sb.WriteString(fmt.Sprintf("Timestamp: %s\n\n", r.results.Timestamp.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("Validation Results for Provider: %s\n", result.ProviderName))
+	sb.WriteString(fmt.Sprintf("Schema: %s\n", result.SchemaPath))
+	sb.WriteString(fmt.Sprintf("Timestamp: %s\n\n", result.Timestamp.Format(timestampLayout)))
 	
-	if r.results.OverallSuccess {
+	if result.OverallSuccess {
 		sb.WriteString("✅ Overall: All consumer contracts are compatible\n\n")
 	} else {
 		sb.WriteString("❌ Overall: Some consumer contracts are incompatible\n\n")
@@ -38,16 +146,29 @@ func (r *Reporter) GenerateSummary() string {
 	// Display results for each consumer
 	sb.WriteString("Consumer Results:\n")
 	
-	for consumer, result := range r.results.ConsumerResults {
-		if result.Success {
+	for _, consumerResult := range orderedConsumers(result, r.sortKeys) {
+		consumer := consumerResult.ConsumerName
+		if consumerResult.Success {
 			sb.WriteString(fmt.Sprintf("  ✅ %s: All expectations met\n", consumer))
 		} else {
 			sb.WriteString(fmt.Sprintf("  ❌ %s: Incompatibilities found\n", consumer))
 			
 			// List issues for each mock
-			for _, matchResult := range result.MatchResults {
+			for _, matchResult := range consumerResult.MatchResults {
 				if !matchResult.IsCompatible {
-					sb.WriteString(fmt.Sprintf("    - Mock: %s\n", matchResult.Mock.Description))
+					kind := "Mock"
+					if matchResult.Message != nil {
+						kind = "Message"
+					} else if matchResult.GRPC != nil {
+						kind = "gRPC"
+					} else if matchResult.GraphQL != nil {
+						kind = "GraphQL"
+					} else if matchResult.Webhook != nil {
+						kind = "Webhook"
+					} else if matchResult.Stream != nil {
+						kind = "Stream"
+					}
+					sb.WriteString(fmt.Sprintf("    - %s: %s\n", kind, matchResult.Mock.Description))
 					
 					for _, issue := range matchResult.Issues {
 						sb.WriteString(fmt.Sprintf("      • %s: %s\n", issue.Path, issue.Description))
@@ -55,6 +176,38 @@ func (r *Reporter) GenerateSummary() string {
 				}
 			}
 		}
+		
+		if waived := countWaived(consumerResult); waived > 0 {
+			sb.WriteString(fmt.Sprintf("    (%d known issue(s) waived)\n", waived))
+		}
+	}
+	
+	if len(result.Workflows) > 0 {
+		sb.WriteString("\nWorkflows:\n")
+		for _, workflow := range result.Workflows {
+			if workflow.Success {
+				sb.WriteString(fmt.Sprintf("  ✅ %s: %s\n", workflow.Consumer, workflow.Name))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("  ❌ %s: %s\n", workflow.Consumer, workflow.Name))
+			for _, step := range workflow.Steps {
+				sb.WriteString(fmt.Sprintf("    - %s: %s\n", step.Description, step.Status))
+			}
+		}
+	}
+	
+	if len(result.Warnings) > 0 {
+		sb.WriteString("\n⚠️  Provider warnings:\n")
+		for _, warning := range result.Warnings {
+			sb.WriteString(fmt.Sprintf("  - %s: %s\n", warning.Path, warning.Description))
+		}
+	}
+	
+	if len(result.ExpiredWaivers) > 0 {
+		sb.WriteString("\n⚠️  Expired waivers (no longer applied):\n")
+		for _, waiver := range result.ExpiredWaivers {
+			sb.WriteString(fmt.Sprintf("  - %s (owner: %s, expired: %s): %s\n", waiver.IssueKey, waiver.Owner, waiver.Expires, waiver.Reason))
+		}
 	}
 	
 	return sb.String()
@@ -62,24 +215,18 @@ func (r *Reporter) GenerateSummary() string {
 
 func (r *Reporter) GenerateReport(resultsPath, format, outputPath string) error {
 	// Load results if not provided
-	if r.results == nil && resultsPath != "" {
-		data, err := os.ReadFile(resultsPath)
-		if err != nil {
-			return fmt.Errorf("failed to read results file: %w", err)
-		}
-		
-		var results ValidationResult
-		if err := json.Unmarshal(data, &results); err != nil {
-			return fmt.Errorf("failed to parse results: %w", err)
+	if r.results == nil && r.suite == nil && resultsPath != "" {
+		if err := r.loadResults(resultsPath); err != nil {
+			return err
 		}
-		
-		r.results = &results
 	}
 	
-	if r.results == nil {
+	if r.results == nil && r.suite == nil {
 		return fmt.Errorf("no validation results available")
 	}
 	
+	r.prepare()
+	
 	switch format {
 	case "json":
 		return r.generateJSONReport(outputPath)
@@ -92,8 +239,44 @@ func (r *Reporter) GenerateReport(resultsPath, format, outputPath string) error
 	}
 }
 
+// loadResults reads either a single provider's results or a suite.
+func (r *Reporter) loadResults(resultsPath string) error {
+	data, err := os.ReadFile(resultsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read results file: %w", err)
+	}
+	
+	var probe struct {
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return fmt.Errorf("failed to parse results: %w", err)
+	}
+	
+	if probe.Results != nil {
+		var suite SuiteResult
+		if err := json.Unmarshal(data, &suite); err != nil {
+			return fmt.Errorf("failed to parse results: %w", err)
+		}
+		r.suite = &suite
+		return nil
+	}
+	
+	var results ValidationResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("failed to parse results: %w", err)
+	}
+	r.results = &results
+	return nil
+}
+
 func (r *Reporter) generateJSONReport(outputPath string) error {
-	data, err := json.MarshalIndent(r.results, "", "  ")
+	var report interface{} = r.results
+	if r.suite != nil {
+		report = r.suite
+	}
+	
+	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal results: %w", err)
 	}
@@ -105,29 +288,14 @@ func (r *Reporter) generateJSONReport(outputPath string) error {
 	return nil
 }
 
-func (r *Reporter) generateHTMLReport(outputPath string) error {
-	// This is simplified - in a real implementation, you'd have a proper HTML template
-	htmlTemplate := `<!DOCTYPE html>
-<html>
-<head>
-    <title>Contract Testing Report - {{.ProviderName}}</title>
-    <style>
-        body { font-family: Arial, sans-serif; margin: 20px; }
-        h1, h2, h3 { color: #333; }
-        .success { color: green; }
-        .failure { color: red; }
-        .issue { margin-left: 20px; }
-        .summary { margin: 20px 0; padding: 10px; background-color: #f8f8f8; }
-    </style>
-</head>
-<body>
-    <h1>Contract Testing Report</h1>
-    
+// htmlResultTemplate renders one provider's results. It is shared by the
+// single-provider and suite reports.
+const htmlResultTemplate = `{{define "result"}}
     <div class="summary">
         <h2>Summary</h2>
         <p><strong>Provider:</strong> {{.ProviderName}}</p>
         <p><strong>Schema:</strong> {{.SchemaPath}}</p>
-        <p><strong>Timestamp:</strong> {{.Timestamp}}</p>
+        <p><strong>Timestamp:</strong> {{.Timestamp.Format "2006-01-02 15:04:05"}}</p>
         {{if .OverallSuccess}}
             <p class="success"><strong>Overall Status:</strong> All consumer contracts are compatible</p>
         {{else}}
@@ -136,8 +304,8 @@ func (r *Reporter) generateHTMLReport(outputPath string) error {
     </div>
     
     <h2>Consumer Results</h2>
-    {{range $consumer, $result := .ConsumerResults}}
-        <h3>{{$consumer}}</h3>
+    {{range $result := consumers .}}
+        <h3>{{$result.ConsumerName}}</h3>
         {{if $result.Success}}
             <p class="success">✅ All expectations met</p>
         {{else}}
@@ -156,14 +324,138 @@ func (r *Reporter) generateHTMLReport(outputPath string) error {
                         </ul>
                     </div>
                 {{end}}
-            {{en{{end}}
             {{end}}
         {{end}}
+        {{range $mock := $result.MatchResults}}
+            {{if $mock.Waived}}
+                <div class="issue waived">
+                    <h4>Waived: {{$mock.Mock.Description}}</h4>
+                    <ul>
+                        {{range $waived := $mock.Waived}}
+                            <li>
+                                <strong>{{$waived.Path}}:</strong> {{$waived.Description}}
+                                ({{$waived.Code}}, {{$waived.Note}})
+                            </li>
+                        {{end}}
+                    </ul>
+                </div>
+            {{end}}
+        {{end}}
+    {{end}}
+    
+    {{if .Workflows}}
+        <h2>Workflows</h2>
+        {{range $workflow := .Workflows}}
+            <h3 class="{{if $workflow.Success}}success{{else}}failure{{end}}">{{$workflow.Consumer}}: {{$workflow.Name}}</h3>
+            <ul>
+                {{range $step := $workflow.Steps}}
+                    <li>{{$step.Description}} ({{$step.Status}})</li>
+                {{end}}
+            </ul>
+        {{end}}
+    {{end}}
+    
+    {{if .Warnings}}
+        <h2>Provider Warnings</h2>
+        <ul>
+            {{range $warning := .Warnings}}
+                <li class="warning"><strong>{{$warning.Path}}</strong>: {{$warning.Description}}</li>
+            {{end}}
+        </ul>
+    {{end}}
+    
+    {{if .ExpiredWaivers}}
+        <h2>Expired Waivers</h2>
+        <ul>
+            {{range $waiver := .ExpiredWaivers}}
+                <li class="warning">
+                    <strong>{{$waiver.IssueKey}}</strong>
+                    (owner: {{$waiver.Owner}}, expired: {{$waiver.Expires}}): {{$waiver.Reason}}
+                </li>
+            {{end}}
+        </ul>
+    {{end}}
+{{end}}`
+
+const htmlStyle = `    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; }
+        h1, h2, h3 { color: #333; }
+        .success { color: green; }
+        .failure { color: red; }
+        .warning { color: #b58900; }
+        .waived { color: #777; }
+        .issue { margin-left: 20px; }
+        .summary { margin: 20px 0; padding: 10px; background-color: #f8f8f8; }
+        table.matrix { border-collapse: collapse; }
+        table.matrix th, table.matrix td { border: 1px solid #ccc; padding: 4px 10px; text-align: center; }
+    </style>`
+
+func (r *Reporter) generateHTMLReport(outputPath string) error {
+	// This is simplified - in a real implementation, you'd have a proper HTML template
+	htmlTemplate := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Contract Testing Report - {{.ProviderName}}</title>
+` + htmlStyle + `
+</head>
+<body>
+    <h1>Contract Testing Report</h1>
+    {{template "result" .}}
+</body>
+</html>`
+
+	var data interface{} = r.results
+	if r.suite != nil {
+		htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <title>Contract Testing Report - {{len .Suite.Results}} providers</title>
+` + htmlStyle + `
+</head>
+<body>
+    <h1>Contract Testing Report</h1>
+    
+    <div class="summary">
+        <h2>Compatibility Matrix</h2>
+        <p><strong>Timestamp:</strong> {{.Suite.Timestamp.Format "2006-01-02 15:04:05"}}</p>
+        {{if .Suite.OverallSuccess}}
+            <p class="success"><strong>Overall Status:</strong> All providers are compatible with their consumers</p>
+        {{else}}
+            <p class="failure"><strong>Overall Status:</strong> Some providers are incompatible with their consumers</p>
+        {{end}}
+        <table class="matrix">
+            <tr>
+                <th>Consumer</th>
+                {{range $provider := .Matrix.Providers}}<th>{{$provider}}</th>{{end}}
+            </tr>
+            {{range $consumer := .Matrix.Consumers}}
+                <tr>
+                    <th>{{$consumer}}</th>
+                    {{range $provider := $.Matrix.Providers}}<td>{{$.Matrix.Status $consumer $provider}}</td>{{end}}
+                </tr>
+            {{end}}
+        </table>
+    </div>
+    
+    {{range $result := .Suite.Results}}
+        <h1>{{$result.ProviderName}}</h1>
+        {{template "result" $result}}
     {{end}}
 </body>
 </html>`
+		data = struct {
+			Suite  *SuiteResult
+			Matrix Matrix
+		}{r.suite, r.suite.Matrix()}
+	}
 
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	funcs := template.FuncMap{
+		"consumers": func(result *ValidationResult) []ConsumerResult {
+			return orderedConsumers(result, r.sortKeys)
+		},
+	}
+	
+	tmpl, err := template.New("report").Funcs(funcs).Parse(htmlResultTemplate + htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -174,7 +466,7 @@ func (r *Reporter) generateHTMLReport(outputPath string) error {
 	}
 	defer file.Close()
 	
-	if err := tmpl.Execute(file, r.results); err != nil {
+	if err := tmpl.Execute(file, data); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 	
@@ -184,32 +476,77 @@ func (r *Reporter) generateHTMLReport(outputPath string) error {
 func (r *Reporter) generateMarkdownReport(outputPath string) error {
 	var sb strings.Builder
 	
-	sb.WriteString(fmt.Sprintf("# Contract Testing Report - %s\n\n", r.results.ProviderName))
+	if r.suite != nil {
+		matrix := r.suite.Matrix()
+		
+		sb.WriteString(fmt.Sprintf("# Contract Testing Report - %d providers\n\n", len(r.suite.Results)))
+		
+		sb.WriteString("## Compatibility Matrix\n\n")
+		sb.WriteString(fmt.Sprintf("- **Timestamp:** %s\n", r.suite.Timestamp.Format(timestampLayout)))
+		if r.suite.OverallSuccess {
+			sb.WriteString("\n**Overall Status:** ✅ All providers are compatible with their consumers\n\n")
+		} else {
+			sb.WriteString("\n**Overall Status:** ❌ Some providers are incompatible with their consumers\n\n")
+		}
+		
+		sb.WriteString("| Consumer | " + strings.Join(matrix.Providers, " | ") + " |\n")
+		sb.WriteString("|---" + strings.Repeat("|---", len(matrix.Providers)) + "|\n")
+		for _, consumer := range matrix.Consumers {
+			sb.WriteString("| " + consumer)
+			for _, provider := range matrix.Providers {
+				sb.WriteString(" | " + matrix.Status(consumer, provider))
+			}
+			sb.WriteString(" |\n")
+		}
+		sb.WriteString("\n")
+		
+		for _, result := range r.suite.Results {
+			r.writeMarkdownResult(&sb, result, 1)
+		}
+	} else {
+		r.writeMarkdownResult(&sb, r.results, 0)
+	}
+	
+	if err := os.WriteFile(outputPath, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	
+	return nil
+}
+
+// writeMarkdownResult renders one provider's results, with headings nested
+// depth levels below the top of the document.
+func (r *Reporter) writeMarkdownResult(sb *strings.Builder, result *ValidationResult, depth int) {
+	heading := func(level int) string {
+		return strings.Repeat("#", level+depth)
+	}
+	
+	sb.WriteString(fmt.Sprintf("%s Contract Testing Report - %s\n\n", heading(1), result.ProviderName))
 	
-	sb.WriteString("## Summary\n\n")
-	sb.WriteString(fmt.Sprintf("- **Provider:** %s\n", r.results.ProviderName))
-	sb.WriteString(fmt.Sprintf("- **Schema:** %s\n", r.results.SchemaPath))
-	sb.WriteString(fmt.Sprintf("- **Timestamp:** %s\n", r.results.Timestamp.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("%s Summary\n\n", heading(2)))
+	sb.WriteString(fmt.Sprintf("- **Provider:** %s\n", result.ProviderName))
+	sb.WriteString(fmt.Sprintf("- **Schema:** %s\n", result.SchemaPath))
+	sb.WriteString(fmt.Sprintf("- **Timestamp:** %s\n", result.Timestamp.Format(timestampLayout)))
 	
-	if r.results.OverallSuccess {
+	if result.OverallSuccess {
 		sb.WriteString("\n**Overall Status:** ✅ All consumer contracts are compatible\n\n")
 	} else {
 		sb.WriteString("\n**Overall Status:** ❌ Some consumer contracts are incompatible\n\n")
 	}
 	
-	sb.WriteString("## Consumer Results\n\n")
+	sb.WriteString(fmt.Sprintf("%s Consumer Results\n\n", heading(2)))
 	
-	for consumer, result := range r.results.ConsumerResults {
-		sb.WriteString(fmt.Sprintf("### %s\n\n", consumer))
+	for _, consumerResult := range orderedConsumers(result, r.sortKeys) {
+		sb.WriteString(fmt.Sprintf("%s %s\n\n", heading(3), consumerResult.ConsumerName))
 		
-		if result.Success {
+		if consumerResult.Success {
 			sb.WriteString("✅ All expectations met\n\n")
 		} else {
 			sb.WriteString("❌ Incompatibilities found\n\n")
 			
-			for _, matchResult := range result.MatchResults {
+			for _, matchResult := range consumerResult.MatchResults {
 				if !matchResult.IsCompatible {
-					sb.WriteString(fmt.Sprintf("#### %s\n\n", matchResult.Mock.Description))
+					sb.WriteString(fmt.Sprintf("%s %s\n\n", heading(4), matchResult.Mock.Description))
 					
 					for _, issue := range matchResult.Issues {
 						sb.WriteString(fmt.Sprintf("- **%s:** %s (%s)\n", issue.Path, issue.Description, issue.Severity))
@@ -219,11 +556,62 @@ func (r *Reporter) generateMarkdownReport(outputPath string) error {
 				}
 			}
 		}
+		
+		if countWaived(consumerResult) > 0 {
+			sb.WriteString(fmt.Sprintf("%s Waived issues\n\n", heading(4)))
+			
+			for _, matchResult := range consumerResult.MatchResults {
+				for _, waived := range matchResult.Waived {
+					sb.WriteString(fmt.Sprintf("- %s — **%s:** %s (%s, %s)\n", matchResult.Mock.Description, waived.Path, waived.Description, waived.Code, waived.Note()))
+				}
+			}
+			
+			sb.WriteString("\n")
+		}
 	}
 	
-	if err := os.WriteFile(outputPath, []byte(sb.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write report: %w", err)
+	if len(result.Workflows) > 0 {
+		sb.WriteString(fmt.Sprintf("%s Workflows\n\n", heading(2)))
+		
+		for _, workflow := range result.Workflows {
+			status := "✅"
+			if !workflow.Success {
+				status = "❌"
+			}
+			sb.WriteString(fmt.Sprintf("- %s **%s:** %s\n", status, workflow.Consumer, workflow.Name))
+			for _, step := range workflow.Steps {
+				sb.WriteString(fmt.Sprintf("  - %s (%s)\n", step.Description, step.Status))
+			}
+		}
+		
+		sb.WriteString("\n")
 	}
 	
-	return nil
-}
\ No newline at end of file
+	if len(result.Warnings) > 0 {
+		sb.WriteString(fmt.Sprintf("%s Provider Warnings\n\n", heading(2)))
+		
+		for _, warning := range result.Warnings {
+			sb.WriteString(fmt.Sprintf("- ⚠️ **%s:** %s\n", warning.Path, warning.Description))
+		}
+		
+		sb.WriteString("\n")
+	}
+	
+	if len(result.ExpiredWaivers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s Expired Waivers\n\n", heading(2)))
+		
+		for _, waiver := range result.ExpiredWaivers {
+			sb.WriteString(fmt.Sprintf("- ⚠️ **%s** (owner: %s, expired: %s): %s\n", waiver.IssueKey, waiver.Owner, waiver.Expires, waiver.Reason))
+		}
+		
+		sb.WriteString("\n")
+	}
+}
+
+func countWaived(result ConsumerResult) int {
+	count := 0
+	for _, matchResult := range result.MatchResults {
+		count += len(matchResult.Waived)
+	}
+	return count
+}
@@ -0,0 +1,402 @@
+package verifier
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Matching rule kinds. A rule replaces literal comparison of the value at its
+// path: "type" compares JSON types only, recursively; "regex", "datetime",
+// "uuid" and "includes" check strings; "integer" and "decimal" check numbers;
+// "eachLike" checks an array's length and compares each item by type with
+// the first item of the mock's array.
+const (
+	MatchType     = "type"
+	MatchRegex    = "regex"
+	MatchInteger  = "integer"
+	MatchDecimal  = "decimal"
+	MatchDateTime = "datetime"
+	MatchUUID     = "uuid"
+	MatchEachLike = "eachLike"
+	MatchIncludes = "includes"
+)
+
+// MatchingRule relaxes how one value of a mock body is compared.
+type MatchingRule struct {
+	Match string `json:"match"`
+	Regex string `json:"regex,omitempty"` // regex
+	Value string `json:"value,omitempty"` // includes; defaults to the mock's value
+	Min   *int   `json:"min,omitempty"`   // eachLike; defaults to 1
+	Max   *int   `json:"max,omitempty"`   // eachLike
+}
+
+// MatchingRules maps body paths to rules. Paths start at the root of the
+// value they apply to ("body" for HTTP bodies, "message" for gRPC messages,
+// "data" for GraphQL data and "payload" for event payloads) and use dots for
+// fields and [*] for every item of an array, e.g. "body.orderId" or
+// "body.items[*].productId".
+type MatchingRules map[string]MatchingRule
+
+var (
+	indexPattern    = regexp.MustCompile(`\[\d+\]`)
+	ruleUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// lookup finds the rule for a concrete path such as body.items[2].name,
+// trying the path as is and with every index replaced by [*].
+func (r MatchingRules) lookup(path string) (MatchingRule, bool) {
+	if len(r) == 0 {
+		return MatchingRule{}, false
+	}
+	if rule, ok := r[path]; ok {
+		return rule, true
+	}
+	rule, ok := r[indexPattern.ReplaceAllString(path, "[*]")]
+	return rule, ok
+}
+
+// Validate checks that a rule is well-formed.
+func (rule MatchingRule) Validate() error {
+	switch rule.Match {
+	case MatchType, MatchInteger, MatchDecimal, MatchDateTime, MatchUUID, MatchIncludes:
+	case MatchRegex:
+		if rule.Regex == "" {
+			return fmt.Errorf("regex rule needs a regex")
+		}
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return fmt.Errorf("invalid regex %q: %v", rule.Regex, err)
+		}
+	case MatchEachLike:
+		if rule.Min != nil && *rule.Min < 0 {
+			return fmt.Errorf("eachLike min must not be negative")
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Max < *rule.Min {
+			return fmt.Errorf("eachLike max %d is below min %d", *rule.Max, *rule.Min)
+		}
+	case "":
+		return fmt.Errorf("rule has no match kind")
+	default:
+		return fmt.Errorf("unknown match kind %q", rule.Match)
+	}
+	return nil
+}
+
+// BodyMismatch is one difference between an expected and an actual body.
+type BodyMismatch struct {
+	Path        string
+	Description string
+}
+
+// CompareBody checks that every value the mock expects is present in the
+// actual body. Values are compared literally unless a matching rule applies
+// to their path. Extra fields in the actual body are allowed.
+func CompareBody(path string, expected, actual interface{}, rules MatchingRules) []BodyMismatch {
+	return compareValue(path, expected, actual, rules, false)
+}
+
+// compareValue compares expected with actual; byType compares JSON types
+// instead of values, as inherited from a type or eachLike rule.
+func compareValue(path string, expected, actual interface{}, rules MatchingRules, byType bool) []BodyMismatch {
+	if rule, ok := rules.lookup(path); ok {
+		return applyRule(path, rule, expected, actual, rules)
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			return []BodyMismatch{{path, fmt.Sprintf("Expected an object, got %s", jsonType(actual))}}
+		}
+
+		var mismatches []BodyMismatch
+		for _, key := range sortedKeys(expectedValue) {
+			fieldPath := path + "." + key
+			actualField, ok := actualMap[key]
+			if !ok {
+				mismatches = append(mismatches, BodyMismatch{fieldPath, "Field missing"})
+				continue
+			}
+			mismatches = append(mismatches, compareValue(fieldPath, expectedValue[key], actualField, rules, byType)...)
+		}
+		return mismatches
+
+	case []interface{}:
+		actualSlice, ok := actual.([]interface{})
+		if !ok {
+			return []BodyMismatch{{path, fmt.Sprintf("Expected an array, got %s", jsonType(actual))}}
+		}
+		if byType {
+			return compareItems(path, expectedValue, actualSlice, rules)
+		}
+		if len(actualSlice) != len(expectedValue) {
+			return []BodyMismatch{{path, fmt.Sprintf("Expected %d items, got %d", len(expectedValue), len(actualSlice))}}
+		}
+
+		var mismatches []BodyMismatch
+		for i := range expectedValue {
+			mismatches = append(mismatches, compareValue(fmt.Sprintf("%s[%d]", path, i), expectedValue[i], actualSlice[i], rules, false)...)
+		}
+		return mismatches
+
+	default:
+		if byType {
+			if jsonType(expected) != jsonType(actual) {
+				return []BodyMismatch{{path, fmt.Sprintf("Expected %s, got %s", jsonType(expected), jsonType(actual))}}
+			}
+			return nil
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return []BodyMismatch{{path, fmt.Sprintf("Expected %v, got %v", expected, actual)}}
+		}
+		return nil
+	}
+}
+
+// compareItems compares every actual item by type with the first expected item.
+func compareItems(path string, expected, actual []interface{}, rules MatchingRules) []BodyMismatch {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	var mismatches []BodyMismatch
+	for i := range actual {
+		mismatches = append(mismatches, compareValue(fmt.Sprintf("%s[%d]", path, i), expected[0], actual[i], rules, true)...)
+	}
+	return mismatches
+}
+
+func applyRule(path string, rule MatchingRule, expected, actual interface{}, rules MatchingRules) []BodyMismatch {
+	mismatch := func(format string, args ...interface{}) []BodyMismatch {
+		return []BodyMismatch{{path, fmt.Sprintf(format, args...)}}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return mismatch("Invalid matching rule: %v", err)
+	}
+
+	switch rule.Match {
+	case MatchType:
+		withoutRule := make(MatchingRules, len(rules))
+		for key, value := range rules {
+			if key != path && key != indexPattern.ReplaceAllString(path, "[*]") {
+				withoutRule[key] = value
+			}
+		}
+		return compareValue(path, expected, actual, withoutRule, true)
+
+	case MatchEachLike:
+		items, ok := actual.([]interface{})
+		if !ok {
+			return mismatch("Expected an array, got %s", jsonType(actual))
+		}
+		min := 1
+		if rule.Min != nil {
+			min = *rule.Min
+		}
+		if len(items) < min {
+			return mismatch("Expected at least %d items, got %d", min, len(items))
+		}
+		if rule.Max != nil && len(items) > *rule.Max {
+			return mismatch("Expected at most %d items, got %d", *rule.Max, len(items))
+		}
+		expectedItems, _ := expected.([]interface{})
+		return compareItems(path, expectedItems, items, rules)
+
+	case MatchInteger, MatchDecimal:
+		n, ok := actual.(float64)
+		if !ok {
+			return mismatch("Expected a number, got %s", jsonType(actual))
+		}
+		if rule.Match == MatchInteger && n != math.Trunc(n) {
+			return mismatch("Expected an integer, got %v", n)
+		}
+		return nil
+	}
+
+	value, ok := actual.(string)
+	if !ok {
+		return mismatch("Expected a string, got %s", jsonType(actual))
+	}
+
+	switch rule.Match {
+	case MatchRegex:
+		if !regexp.MustCompile(rule.Regex).MatchString(value) {
+			return mismatch("Expected a value matching %s, got %q", rule.Regex, value)
+		}
+	case MatchDateTime:
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return mismatch("Expected an ISO 8601 date-time, got %q", value)
+		}
+	case MatchUUID:
+		if !ruleUUIDPattern.MatchString(value) {
+			return mismatch("Expected a UUID, got %q", value)
+		}
+	case MatchIncludes:
+		needle := rule.Value
+		if needle == "" {
+			needle, _ = expected.(string)
+		}
+		if !strings.Contains(value, needle) {
+			return mismatch("Expected a value including %q, got %q", needle, value)
+		}
+	}
+	return nil
+}
+
+// checkMatchingRules reports rules that are malformed, that the mock's own
+// example value breaks, or that no value allowed by the schema could meet.
+// Rule paths start at root, e.g. "body", and issues are reported at prefix
+// followed by the rule path. bodySchema describes the value at root, read
+// with spec; it may be nil if the provider declares none.
+func checkMatchingRules(spec *schema.Spec, prefix, root string, rules MatchingRules, body interface{}, bodySchema interface{}) []Issue {
+	var issues []Issue
+	add := func(code, path, severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{
+			Code:        code,
+			Path:        prefix + path,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	paths := make([]string, 0, len(rules))
+	for path := range rules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		rule := rules[path]
+		if err := rule.Validate(); err != nil {
+			add(CodeMatchingRuleInvalid, path, "error", "Invalid matching rule: %v", err)
+			continue
+		}
+		if path != root && !strings.HasPrefix(path, root+".") && !strings.HasPrefix(path, root+"[") {
+			add(CodeMatchingRuleInvalid, path, "error", "Matching rule paths must start with %s", root)
+			continue
+		}
+
+		// The mock's own value has to satisfy its rule.
+		if example, ok := valueAt(body, root, path); ok {
+			for _, mismatch := range applyRule(path, rule, example, example, rules) {
+				add(CodeMatchingRuleInvalid, mismatch.Path, "error", "Mock value breaks its own matching rule: %s", mismatch.Description)
+			}
+		} else {
+			add(CodeMatchingRuleInvalid, path, "error", "Matching rule refers to a value the mock doesn't have")
+			continue
+		}
+
+		if bodySchema == nil {
+			continue
+		}
+		node, ok := spec.SchemaAt(bodySchema, strings.TrimPrefix(strings.TrimPrefix(path, root), "."))
+		if !ok {
+			add(CodeMatchingRuleUnsatisfiable, path, "warning", "Provider schema doesn't declare this field, so the provider never promises it")
+			continue
+		}
+		if reason := unsatisfiable(spec, rule, node); reason != "" {
+			add(CodeMatchingRuleUnsatisfiable, path, "error", "Matching rule %s can't be met by the provider schema: %s", rule.Match, reason)
+		}
+	}
+	return issues
+}
+
+// unsatisfiable explains why no value allowed by node can meet rule, or
+// returns "" if some can.
+func unsatisfiable(spec *schema.Spec, rule MatchingRule, node interface{}) string {
+	switch rule.Match {
+	case MatchRegex, MatchIncludes, MatchDateTime, MatchUUID:
+		if !spec.AllowsType(node, "string") {
+			return "schema values are not strings"
+		}
+		format := spec.Format(node)
+		if rule.Match == MatchDateTime && format != "" && format != "date-time" {
+			return fmt.Sprintf("schema format is %s", format)
+		}
+		if rule.Match == MatchUUID && format != "" && format != "uuid" {
+			return fmt.Sprintf("schema format is %s", format)
+		}
+	case MatchInteger:
+		if !spec.AllowsType(node, "integer") {
+			return "schema values are not numbers"
+		}
+	case MatchDecimal:
+		if !spec.AllowsType(node, "number") {
+			return "schema values are not decimal numbers"
+		}
+	case MatchEachLike:
+		if !spec.AllowsType(node, "array") {
+			return "schema values are not arrays"
+		}
+		min, max, hasMax := spec.ItemBounds(node)
+		if rule.Max != nil && *rule.Max < min {
+			return fmt.Sprintf("schema requires at least %d items", min)
+		}
+		ruleMin := 1
+		if rule.Min != nil {
+			ruleMin = *rule.Min
+		}
+		if hasMax && ruleMin > max {
+			return fmt.Sprintf("schema allows at most %d items", max)
+		}
+	}
+	return ""
+}
+
+// valueAt returns the value at a rule path inside the value at root; [*]
+// selects the first item.
+func valueAt(body interface{}, root, path string) (interface{}, bool) {
+	current := body
+	rest := strings.TrimPrefix(path, root)
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[rest[:end]]; !ok {
+				return nil, false
+			}
+			rest = rest[end:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, false
+			}
+			items, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			index := 0
+			if token := rest[1:end]; token != "*" {
+				if _, err := fmt.Sscanf(token, "%d", &index); err != nil {
+					return nil, false
+				}
+			}
+			if index >= len(items) {
+				return nil, false
+			}
+			current = items[index]
+			rest = rest[end+1:]
+
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,185 @@
+package verifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sort keys accepted by reporters. Keys are applied in order at every level
+// they make sense for: consumers, the mocks of a consumer and a mock's issues.
+const (
+	SortSeverity = "severity"
+	SortConsumer = "consumer"
+	SortEndpoint = "endpoint"
+)
+
+// DefaultSortKeys orders consumers by name and mocks and issues by endpoint.
+var DefaultSortKeys = []string{SortConsumer, SortEndpoint}
+
+// ParseSortKeys parses a comma-separated list of sort keys.
+func ParseSortKeys(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultSortKeys, nil
+	}
+
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch key {
+		case SortSeverity, SortConsumer, SortEndpoint:
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unknown sort key %q (use severity, consumer or endpoint)", key)
+		}
+	}
+	return keys, nil
+}
+
+// sortResult orders every consumer's mocks, issues and waived issues in place.
+func sortResult(result *ValidationResult, keys []string) {
+	for consumer, consumerResult := range result.ConsumerResults {
+		for i := range consumerResult.MatchResults {
+			matchResult := &consumerResult.MatchResults[i]
+			sort.SliceStable(matchResult.Issues, func(a, b int) bool {
+				return compareIssues(matchResult.Issues[a], matchResult.Issues[b], keys) < 0
+			})
+			sort.SliceStable(matchResult.Waived, func(a, b int) bool {
+				return compareIssues(matchResult.Waived[a].Issue, matchResult.Waived[b].Issue, keys) < 0
+			})
+		}
+
+		sort.SliceStable(consumerResult.MatchResults, func(a, b int) bool {
+			return compareMatchResults(consumerResult.MatchResults[a], consumerResult.MatchResults[b], keys) < 0
+		})
+
+		result.ConsumerResults[consumer] = consumerResult
+	}
+}
+
+// orderedConsumers returns the consumer results in report order.
+func orderedConsumers(result *ValidationResult, keys []string) []ConsumerResult {
+	consumers := make([]ConsumerResult, 0, len(result.ConsumerResults))
+	for name, consumerResult := range result.ConsumerResults {
+		if consumerResult.ConsumerName == "" {
+			consumerResult.ConsumerName = name
+		}
+		consumers = append(consumers, consumerResult)
+	}
+
+	sort.SliceStable(consumers, func(a, b int) bool {
+		return compareConsumers(consumers[a], consumers[b], keys) < 0
+	})
+	return consumers
+}
+
+func compareConsumers(a, b ConsumerResult, keys []string) int {
+	for _, key := range keys {
+		var c int
+		switch key {
+		case SortSeverity:
+			c = compareInts(countErrors(b), countErrors(a)) // most errors first
+		case SortConsumer:
+			c = strings.Compare(a.ConsumerName, b.ConsumerName)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.ConsumerName, b.ConsumerName)
+}
+
+func compareMatchResults(a, b MatchResult, keys []string) int {
+	for _, key := range keys {
+		var c int
+		switch key {
+		case SortSeverity:
+			c = compareInts(errorCount(b.Issues), errorCount(a.Issues))
+		case SortEndpoint:
+			c = compareStrings(
+				a.Mock.Request.Endpoint, b.Mock.Request.Endpoint,
+				strings.ToUpper(a.Mock.Request.Method), strings.ToUpper(b.Mock.Request.Method),
+			)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareStrings(
+		a.Mock.Request.Endpoint, b.Mock.Request.Endpoint,
+		strings.ToUpper(a.Mock.Request.Method), strings.ToUpper(b.Mock.Request.Method),
+		a.Mock.Description, b.Mock.Description,
+		a.MockPath, b.MockPath,
+	)
+}
+
+func compareIssues(a, b Issue, keys []string) int {
+	for _, key := range keys {
+		var c int
+		switch key {
+		case SortSeverity:
+			c = compareInts(severityRank(a.Severity), severityRank(b.Severity))
+		case SortEndpoint:
+			c = strings.Compare(a.Path, b.Path)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareStrings(
+		a.Path, b.Path,
+		a.Code, b.Code,
+		a.Description, b.Description,
+	)
+}
+
+// severityRank orders errors before warnings before anything else.
+func severityRank(severity string) int {
+	switch severity {
+	case "error":
+		return 0
+	case "warning":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func countErrors(result ConsumerResult) int {
+	count := 0
+	for _, matchResult := range result.MatchResults {
+		count += errorCount(matchResult.Issues)
+	}
+	return count
+}
+
+func errorCount(issues []Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			count++
+		}
+	}
+	return count
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareStrings compares pairs of strings in turn: a1 with b1, a2 with b2, ...
+func compareStrings(pairs ...string) int {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if c := strings.Compare(pairs[i], pairs[i+1]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package verifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Issue codes of status code checks.
+const (
+	CodeStatusRangeInvalid = "status-range-invalid"
+	CodeStatusUndocumented = "status-undocumented"
+	CodeStatusUnhandled    = "status-unhandled"
+)
+
+// matchStatus checks the status a mock expects against the responses the
+// operation declares, resolved as OpenAPI does: the exact code, else its
+// range such as "4XX", else the default response. A status only the default
+// response covers is undocumented and reported as a warning. It returns the
+// status whose declared response the mock's body is checked against: the
+// mock's statusCode, or for a statusRange alone the lowest code the provider
+// declares in the range. It is 0 when no response applies.
+func matchStatus(op *schema.Operation, method, endpoint string, response MockResponse) (int, []Issue) {
+	path := fmt.Sprintf("%s %s response.statusCode", method, endpoint)
+	var issues []Issue
+	add := func(code, severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{
+			Code:        code,
+			Path:        path,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	statusRange := strings.ToUpper(response.StatusRange)
+	if statusRange != "" {
+		if !schema.IsStatusRange(statusRange) {
+			add(CodeStatusRangeInvalid, "error", "Status range %q is not a range such as 2XX", response.StatusRange)
+			return 0, issues
+		}
+		if response.StatusCode != 0 && !schema.StatusInRange(response.StatusCode, statusRange) {
+			add(CodeStatusRangeInvalid, "error", "Status code %d is outside the mock's status range %s", response.StatusCode, statusRange)
+			return 0, issues
+		}
+	}
+
+	if response.StatusCode != 0 || statusRange == "" {
+		key, ok := op.ResponseKey(response.StatusCode)
+		switch {
+		case !ok:
+			add(CodeStatusNotDefined, "error", "Status code %d not defined in provider contract", response.StatusCode)
+			return 0, issues
+		case key == "default":
+			add(CodeStatusUndocumented, "warning", "Status code %d is only covered by the provider's default response", response.StatusCode)
+		}
+		return response.StatusCode, issues
+	}
+
+	status := 0
+	for key := range op.Responses() {
+		code, err := strconv.Atoi(key)
+		if err == nil && schema.StatusInRange(code, statusRange) && (status == 0 || code < status) {
+			status = code
+		}
+		if err != nil && strings.EqualFold(key, statusRange) && status == 0 {
+			status = int(statusRange[0]-'0') * 100
+		}
+	}
+	if status != 0 {
+		return status, issues
+	}
+	if _, ok := op.Responses()["default"]; ok {
+		add(CodeStatusUndocumented, "warning", "No %s status is documented, only the provider's default response covers it", statusRange)
+		return int(statusRange[0]-'0') * 100, issues
+	}
+	add(CodeStatusNotDefined, "error", "No %s status code is defined in provider contract", statusRange)
+	return 0, issues
+}
+
+// expectsStatus reports whether a mock accepts a status: its statusRange if
+// it has one, or else its statusCode.
+func expectsStatus(response MockResponse, status int) bool {
+	if response.StatusRange != "" {
+		return schema.StatusInRange(status, strings.ToUpper(response.StatusRange))
+	}
+	return status == response.StatusCode
+}
+
+// describeStatus returns the status a mock expects, e.g. "201" or "4XX".
+func describeStatus(response MockResponse) string {
+	if response.StatusRange != "" {
+		return strings.ToUpper(response.StatusRange)
+	}
+	return strconv.Itoa(response.StatusCode)
+}
+
+// unhandledStatuses warns about the success responses (2xx codes and the
+// 2XX range) of each operation the mocks call that no mock expects, as
+// consumers would not know what to do with them. Operations no mock calls
+// are left out.
+func unhandledStatuses(spec *schema.Spec, mocks []Mock) []Issue {
+	type operation struct {
+		op     *schema.Operation
+		method string
+		path   string
+		mocks  []MockResponse
+	}
+	operations := map[string]*operation{}
+	for _, mock := range mocks {
+		method := strings.ToLower(mock.Request.Method)
+		op, _ := spec.Operation(method, mock.Request.Endpoint)
+		if op == nil {
+			continue
+		}
+		key := method + " " + mock.Request.Endpoint
+		if operations[key] == nil {
+			operations[key] = &operation{op: op, method: method, path: mock.Request.Endpoint}
+		}
+		operations[key].mocks = append(operations[key].mocks, mock.Response)
+	}
+
+	var issues []Issue
+	for _, key := range sortedKeys(operations) {
+		operation := operations[key]
+		for _, code := range sortedKeys(operation.op.Responses()) {
+			if !strings.HasPrefix(code, "2") || operationHandles(operation.op, operation.mocks, code) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Code:        CodeStatusUnhandled,
+				Path:        fmt.Sprintf("%s %s responses.%s", operation.method, operation.path, code),
+				Description: fmt.Sprintf("Provider declares %s but no consumer handles it", code),
+				Severity:    "warning",
+			})
+		}
+	}
+	return issues
+}
+
+// operationHandles reports whether some mock expects a status the declared
+// response key serves.
+func operationHandles(op *schema.Operation, responses []MockResponse, key string) bool {
+	for _, response := range responses {
+		if response.StatusRange != "" && key[0] == response.StatusRange[0] {
+			return true
+		}
+		if served, ok := op.ResponseKey(response.StatusCode); ok && served == key {
+			return true
+		}
+	}
+	return false
+}
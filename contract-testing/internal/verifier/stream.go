@@ -0,0 +1,483 @@
+package verifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+	"golang.org/x/net/websocket"
+)
+
+// Issue codes reported for stream contracts.
+const (
+	CodeStreamInvalid          = "stream-invalid"
+	CodeStreamEndpointNotFound = "stream-endpoint-not-found"
+	CodeStreamNotStreaming     = "stream-not-streaming"
+	CodeStreamMessageInvalid   = "stream-message-invalid"
+	CodeStreamConnectFailed    = "stream-connect-failed"
+	CodeStreamMessageMissing   = "stream-message-missing"
+	CodeStreamOrderMismatch    = "stream-order-mismatch"
+)
+
+// Stream protocols.
+const (
+	StreamSSE       = "sse"
+	StreamWebSocket = "websocket"
+)
+
+// defaultStreamTimeout is how long live verification collects messages when
+// a contract sets no timeout.
+const defaultStreamTimeout = 5 * time.Second
+
+// StreamMock is a consumer's expectation of a streaming endpoint: the
+// connection it opens, over Server-Sent Events or WebSocket, and the
+// messages it expects to receive on it.
+type StreamMock struct {
+	Provider      string           `json:"provider"`
+	Consumer      string           `json:"consumer"`
+	Description   string           `json:"description"`
+	ProviderState string           `json:"providerState,omitempty"`
+	Stream        StreamConnection `json:"stream"`
+	Messages      []StreamMessage  `json:"messages"`
+}
+
+// StreamConnection is the request that opens a stream. Send lists the
+// messages a WebSocket client sends once connected, such as subscriptions.
+// With Ordered, the expected messages must arrive in the order listed, with
+// any other messages in between. Timeout is a duration such as "5s".
+type StreamConnection struct {
+	Protocol string            `json:"protocol"`
+	Endpoint string            `json:"endpoint"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Send     []interface{}     `json:"send,omitempty"`
+	Ordered  bool              `json:"ordered,omitempty"`
+	Timeout  string            `json:"timeout,omitempty"`
+}
+
+// StreamMessage is one message a consumer expects. A received message
+// matches when it has the same SSE event type ("message" by default), its
+// payload equals Payload, subject to the matching rules on paths such as
+// "payload.status", and it satisfies Schema. Payload or Schema may be left
+// out to check only the other.
+type StreamMessage struct {
+	Event         string                 `json:"event,omitempty"`
+	Payload       interface{}            `json:"payload,omitempty"`
+	Schema        map[string]interface{} `json:"schema,omitempty"`
+	MatchingRules MatchingRules          `json:"matchingRules,omitempty"`
+}
+
+// event returns the SSE event type the message is expected with.
+func (m StreamMessage) event() string {
+	if m.Event == "" {
+		return "message"
+	}
+	return m.Event
+}
+
+// timeout returns how long to collect messages for.
+func (c StreamConnection) timeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return defaultStreamTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", c.Timeout)
+	}
+	return timeout, nil
+}
+
+// path names the stream in issue paths, e.g. "sse /orders/ord_123/events".
+func (c StreamConnection) path() string {
+	return strings.ToLower(c.Protocol) + " " + c.Endpoint
+}
+
+// MatchStream checks a consumer's stream expectation against the provider's
+// OpenAPI document: the endpoint must be a GET operation that streams, an
+// SSE endpoint by declaring text/event-stream, and every expected payload
+// must satisfy its message schema and the schema the provider declares for
+// its events.
+func MatchStream(spec *schema.Spec, mock StreamMock, mockPath string) MatchResult {
+	result := MatchResult{
+		Mock: Mock{
+			Provider:      mock.Provider,
+			Consumer:      mock.Consumer,
+			Description:   mock.Description,
+			ProviderState: mock.ProviderState,
+		},
+		Stream:       &mock,
+		MockPath:     mockPath,
+		IsCompatible: true,
+		Issues:       []Issue{},
+	}
+	path := mock.Stream.path()
+	add := func(code, issuePath, severity, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, Issue{
+			Code:        code,
+			Path:        issuePath,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	protocol := strings.ToLower(mock.Stream.Protocol)
+	if protocol != StreamSSE && protocol != StreamWebSocket {
+		add(CodeStreamInvalid, path, "error", "Unknown protocol %q (use sse or websocket)", mock.Stream.Protocol)
+	}
+	if _, err := mock.Stream.timeout(); err != nil {
+		add(CodeStreamInvalid, path+" stream.timeout", "error", "%v", err)
+	}
+	if len(mock.Messages) == 0 {
+		add(CodeStreamInvalid, path, "error", "Contract expects no messages")
+	}
+	if len(mock.Stream.Send) > 0 && protocol == StreamSSE {
+		add(CodeStreamInvalid, path+" stream.send", "error", "Server-Sent Events streams cannot send messages")
+	}
+
+	var eventSchema interface{}
+	op, _, pathFound := spec.FindOperation("get", strings.SplitN(mock.Stream.Endpoint, "?", 2)[0])
+	switch {
+	case op == nil && pathFound:
+		add(CodeStreamEndpointNotFound, path, "error", "Endpoint has no GET operation to open the stream")
+	case op == nil:
+		add(CodeStreamEndpointNotFound, path, "error", "Endpoint not found in provider schema")
+	case protocol == StreamSSE:
+		var ok bool
+		if eventSchema, ok = streamEventSchema(op); !ok {
+			add(CodeStreamNotStreaming, path, "error", "Provider does not declare a text/event-stream response for %s", op)
+		}
+	case protocol == StreamWebSocket:
+		if _, ok := op.Responses()["101"]; !ok {
+			add(CodeStreamNotStreaming, path, "warning", "Provider does not declare a 101 Switching Protocols response for %s", op)
+		}
+	}
+
+	for i, message := range mock.Messages {
+		messagePath := fmt.Sprintf("%s messages[%d]", path, i)
+		if message.Payload == nil && message.Schema == nil {
+			add(CodeStreamInvalid, messagePath, "error", "Message has neither a payload nor a schema")
+			continue
+		}
+		if message.Payload == nil {
+			continue
+		}
+		if message.Schema != nil {
+			for _, violation := range spec.Validate(message.Schema, message.Payload, "payload") {
+				add(CodeStreamMessageInvalid, fmt.Sprintf("%s.%s", messagePath, violation.Path), "error",
+					"Expected payload does not match the message schema (%s): %s", violation.Rule, violation.Message)
+			}
+		}
+		if eventSchema != nil {
+			for _, violation := range spec.Validate(eventSchema, message.Payload, "payload") {
+				add(CodeStreamMessageInvalid, fmt.Sprintf("%s.%s", messagePath, violation.Path), "error",
+					"Expected payload does not match the provider's event schema (%s): %s", violation.Rule, violation.Message)
+			}
+		}
+		result.Issues = append(result.Issues, checkMatchingRules(spec, messagePath+".", "payload",
+			message.MatchingRules, message.Payload, eventSchema)...)
+	}
+
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	return result
+}
+
+// streamEventSchema returns the schema an SSE operation declares for its
+// events, reporting false if the operation doesn't stream text/event-stream.
+func streamEventSchema(op *schema.Operation) (interface{}, bool) {
+	response, _ := op.Response(http.StatusOK)
+	content, _ := response["content"].(map[interface{}]interface{})
+	media, ok := content["text/event-stream"]
+	if !ok {
+		return nil, false
+	}
+	node, _ := media.(map[interface{}]interface{})
+	return node["schema"], true
+}
+
+// receivedMessage is a message read from a live stream. Payloads that are
+// not JSON are kept as strings.
+type receivedMessage struct {
+	event   string
+	payload interface{}
+}
+
+// StreamVerifier opens consumers' streams on a running provider, collects
+// the messages it sends and matches them against the expected ones.
+type StreamVerifier struct {
+	baseURL string
+	limiter *rateLimiter
+}
+
+// NewStreamVerifier creates a verifier for the provider at baseURL.
+// Connections are limited to requestsPerSecond; zero or less means
+// unlimited.
+func NewStreamVerifier(baseURL string, requestsPerSecond float64) *StreamVerifier {
+	return &StreamVerifier{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		limiter: newRateLimiter(requestsPerSecond),
+	}
+}
+
+// Verify opens the mock's stream and collects messages until every expected
+// message has arrived, the provider closes the stream or the timeout
+// passes, then reports the expected messages that did not arrive and the
+// received events that break the provider's declared event schema.
+func (s *StreamVerifier) Verify(spec *schema.Spec, mock StreamMock) []Issue {
+	path := mock.Stream.path()
+	timeout, err := mock.Stream.timeout()
+	if err != nil {
+		return nil // Reported by MatchStream
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	received := make(chan receivedMessage)
+	errs := make(chan error, 1)
+	s.limiter.Wait()
+	go func() {
+		defer close(received)
+		var err error
+		if strings.ToLower(mock.Stream.Protocol) == StreamWebSocket {
+			err = s.readWebSocket(ctx, mock.Stream, received)
+		} else {
+			err = s.readEvents(ctx, mock.Stream, received)
+		}
+		errs <- err
+	}()
+
+	var messages []receivedMessage
+	for message := range received {
+		messages = append(messages, message)
+		if len(matchStreamMessages(spec, mock.Messages, messages, mock.Stream.Ordered)) == 0 {
+			cancel()
+		}
+	}
+	if err := <-errs; err != nil && len(messages) == 0 {
+		return []Issue{{
+			Code:        CodeStreamConnectFailed,
+			Path:        path,
+			Description: fmt.Sprintf("Could not open stream: %v", err),
+			Severity:    "error",
+		}}
+	}
+
+	var issues []Issue
+	if op, _, _ := spec.FindOperation("get", strings.SplitN(mock.Stream.Endpoint, "?", 2)[0]); op != nil && strings.ToLower(mock.Stream.Protocol) == StreamSSE {
+		if eventSchema, _ := streamEventSchema(op); eventSchema != nil {
+			for k, message := range messages {
+				for _, violation := range spec.Validate(eventSchema, message.payload, "payload") {
+					issues = append(issues, Issue{
+						Code:        CodeStreamMessageInvalid,
+						Path:        fmt.Sprintf("%s received[%d].%s", path, k, violation.Path),
+						Description: fmt.Sprintf("Provider sent an event that breaks its event schema (%s): %s", violation.Rule, violation.Message),
+						Severity:    "error",
+					})
+				}
+			}
+		}
+	}
+	for _, i := range matchStreamMessages(spec, mock.Messages, messages, mock.Stream.Ordered) {
+		issue := Issue{
+			Code:        CodeStreamMessageMissing,
+			Path:        fmt.Sprintf("%s messages[%d]", path, i),
+			Description: fmt.Sprintf("Expected %q event not received within %s (%d messages received)", mock.Messages[i].event(), timeout, len(messages)),
+			Severity:    "error",
+		}
+		if mock.Stream.Ordered && len(matchStreamMessages(spec, mock.Messages[i:i+1], messages, false)) == 0 {
+			issue.Code = CodeStreamOrderMismatch
+			issue.Description = fmt.Sprintf("Expected %q event arrived out of order", mock.Messages[i].event())
+		} else if closest := closestMismatch(spec, mock.Messages[i], messages); closest != "" {
+			issue.Description += "; closest: " + closest
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// matchStreamMessages returns the indexes of the expected messages that the
+// received ones do not satisfy. Ordered expectations must be matched in
+// order; each received message satisfies at most one expectation.
+func matchStreamMessages(spec *schema.Spec, expected []StreamMessage, received []receivedMessage, ordered bool) []int {
+	used := make([]bool, len(received))
+	next := 0
+	var missing []int
+
+	for i, message := range expected {
+		start := 0
+		if ordered {
+			start = next
+		}
+		found := false
+		for k := start; k < len(received); k++ {
+			if !used[k] && len(streamMismatches(spec, message, received[k])) == 0 {
+				used[k] = true
+				next = k + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// streamMismatches describes how a received message differs from an
+// expected one.
+func streamMismatches(spec *schema.Spec, expected StreamMessage, actual receivedMessage) []string {
+	if actual.event != expected.event() {
+		return []string{fmt.Sprintf("event is %q, not %q", actual.event, expected.event())}
+	}
+	var mismatches []string
+	if expected.Payload != nil {
+		for _, mismatch := range CompareBody("payload", expected.Payload, actual.payload, expected.MatchingRules) {
+			mismatches = append(mismatches, mismatch.Path+": "+mismatch.Description)
+		}
+	}
+	if expected.Schema != nil {
+		for _, violation := range spec.Validate(expected.Schema, actual.payload, "payload") {
+			mismatches = append(mismatches, violation.String())
+		}
+	}
+	return mismatches
+}
+
+// closestMismatch describes the received message of the expected event type
+// that comes closest to matching, or returns "" if there is none.
+func closestMismatch(spec *schema.Spec, expected StreamMessage, received []receivedMessage) string {
+	var best []string
+	for _, message := range received {
+		if message.event != expected.event() {
+			continue
+		}
+		if mismatches := streamMismatches(spec, expected, message); best == nil || len(mismatches) < len(best) {
+			best = mismatches
+		}
+	}
+	return strings.Join(best, "; ")
+}
+
+// readEvents reads a Server-Sent Events stream, sending each dispatched
+// event until the stream ends or ctx is done.
+func (s *StreamVerifier) readEvents(ctx context.Context, conn StreamConnection, received chan<- receivedMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+conn.Endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for name, value := range conn.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("provider answered HTTP %d", resp.StatusCode)
+	}
+	if contentType := mediaType(resp.Header.Get("Content-Type")); contentType != "text/event-stream" {
+		return fmt.Errorf("provider answered with Content-Type %q, not text/event-stream", contentType)
+	}
+
+	event := ""
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				received <- receivedMessage{event: event, payload: decodePayload(strings.Join(data, "\n"))}
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Comment, often a keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// readWebSocket opens a WebSocket, sends the connection's messages and then
+// sends each message received until the socket closes or ctx is done.
+// WebSocket messages have the event type "message".
+func (s *StreamVerifier) readWebSocket(ctx context.Context, conn StreamConnection, received chan<- receivedMessage) error {
+	location := "ws" + strings.TrimPrefix(s.baseURL, "http") + conn.Endpoint
+	config, err := websocket.NewConfig(location, s.baseURL)
+	if err != nil {
+		return err
+	}
+	for name, value := range conn.Headers {
+		config.Header.Set(name, value)
+	}
+
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	go func() {
+		<-ctx.Done()
+		ws.Close()
+	}()
+
+	for _, message := range conn.Send {
+		text, ok := message.(string)
+		if !ok {
+			data, err := json.Marshal(message)
+			if err != nil {
+				return err
+			}
+			text = string(data)
+		}
+		if err := websocket.Message.Send(ws, text); err != nil {
+			return err
+		}
+	}
+
+	for {
+		var text string
+		if err := websocket.Message.Receive(ws, &text); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		received <- receivedMessage{event: "message", payload: decodePayload(text)}
+	}
+}
+
+// decodePayload decodes a message as JSON, or keeps it as a string if it
+// isn't JSON.
+func decodePayload(data string) interface{} {
+	var payload interface{}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return data
+	}
+	return payload
+}
@@ -0,0 +1,96 @@
+package verifier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SuiteResult combines the validation results of several providers that were
+// verified in one run.
+type SuiteResult struct {
+	Timestamp      time.Time           `json:"timestamp"`
+	Results        []*ValidationResult `json:"results"`
+	OverallSuccess bool                `json:"overallSuccess"`
+}
+
+// MatrixCell summarises one consumer's contracts with one provider.
+type MatrixCell struct {
+	Mocks        int `json:"mocks"`
+	Incompatible int `json:"incompatible"`
+	Waived       int `json:"waived"`
+}
+
+// Matrix is the consumer × provider compatibility overview of a suite.
+type Matrix struct {
+	Consumers []string                         `json:"consumers"`
+	Providers []string                         `json:"providers"`
+	Cells     map[string]map[string]MatrixCell `json:"cells"` // consumer -> provider -> cell
+}
+
+// NewSuiteResult combines per-provider results, ordered by provider name.
+func NewSuiteResult(results []*ValidationResult) *SuiteResult {
+	suite := &SuiteResult{
+		Timestamp:      time.Now(),
+		Results:        append([]*ValidationResult(nil), results...),
+		OverallSuccess: true,
+	}
+
+	sort.SliceStable(suite.Results, func(i, j int) bool {
+		return suite.Results[i].ProviderName < suite.Results[j].ProviderName
+	})
+
+	for _, result := range suite.Results {
+		if !result.OverallSuccess {
+			suite.OverallSuccess = false
+		}
+	}
+
+	return suite
+}
+
+// Matrix builds the consumer × provider overview.
+func (s *SuiteResult) Matrix() Matrix {
+	matrix := Matrix{
+		Cells: make(map[string]map[string]MatrixCell),
+	}
+
+	for _, result := range s.Results {
+		matrix.Providers = append(matrix.Providers, result.ProviderName)
+
+		for consumer, consumerResult := range result.ConsumerResults {
+			if _, ok := matrix.Cells[consumer]; !ok {
+				matrix.Cells[consumer] = make(map[string]MatrixCell)
+				matrix.Consumers = append(matrix.Consumers, consumer)
+			}
+
+			cell := matrix.Cells[consumer][result.ProviderName]
+			for _, matchResult := range consumerResult.MatchResults {
+				cell.Mocks++
+				cell.Waived += len(matchResult.Waived)
+				if !matchResult.IsCompatible {
+					cell.Incompatible++
+				}
+			}
+			matrix.Cells[consumer][result.ProviderName] = cell
+		}
+	}
+
+	sort.Strings(matrix.Consumers)
+	sort.Strings(matrix.Providers)
+
+	return matrix
+}
+
+// Status renders the cell for a consumer and provider as a short symbol with
+// mock counts, or a dash when the consumer has no contracts with the provider.
+func (m Matrix) Status(consumer, provider string) string {
+	cell, ok := m.Cells[consumer][provider]
+	if !ok {
+		return "—"
+	}
+	if cell.Incompatible > 0 {
+		return fmt.Sprintf("❌ %d/%d", cell.Incompatible, cell.Mocks)
+	}
+	return fmt.Sprintf("✅ %d", cell.Mocks)
+}
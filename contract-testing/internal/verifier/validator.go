@@ -1,11 +1,11 @@
 package verifier
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Arpit529srivastava/internal/schema"
@@ -17,6 +17,9 @@ type ValidationResult struct {
 	Timestamp       time.Time               `json:"timestamp"`
 	ConsumerResults map[string]ConsumerResult `json:"consumerResults"`
 	OverallSuccess  bool                    `json:"overallSuccess"`
+	ExpiredWaivers  []Waiver                `json:"expiredWaivers,omitempty"`
+	Workflows       []WorkflowResult        `json:"workflows,omitempty"`
+	Warnings        []Issue                 `json:"warnings,omitempty"` // provider-wide, e.g. unhandled success codes
 }
 
 type ConsumerResult struct {
@@ -26,9 +29,18 @@ type ConsumerResult struct {
 }
 
 type Validator struct {
-	schemaPath  string
-	mocksDir    string
-	providerURL string
+	schemaPath   string
+	mocksDir     string
+	providerURL  string
+	providerName string
+	concurrency  int
+	live         bool
+	rateLimit    float64
+	asyncAPIPath string
+	protoDir     string
+	grpcAddress  string
+	graphQLPath  string
+	graphQLURL   string
 }
 
 func NewValidator(schemaPath, mocksDir, providerURL string) *Validator {
@@ -36,9 +48,73 @@ func NewValidator(schemaPath, mocksDir, providerURL string) *Validator {
 		schemaPath:  schemaPath,
 		mocksDir:    mocksDir,
 		providerURL: providerURL,
+		concurrency: 1,
 	}
 }
 
+// ForProvider sets the provider name used to select mocks. By default it is
+// the name of the directory that contains the schema.
+func (v *Validator) ForProvider(providerName string) *Validator {
+	v.providerName = providerName
+	return v
+}
+
+// WithConcurrency sets how many mocks are verified in parallel.
+func (v *Validator) WithConcurrency(workers int) *Validator {
+	if workers < 1 {
+		workers = 1
+	}
+	v.concurrency = workers
+	return v
+}
+
+// WithLive also replays every mock against the provider URL, sending at most
+// requestsPerSecond requests to this provider (zero means unlimited).
+func (v *Validator) WithLive(requestsPerSecond float64) *Validator {
+	v.live = true
+	v.rateLimit = requestsPerSecond
+	return v
+}
+
+// WithAsyncAPI sets the AsyncAPI document that consumers' message contracts
+// are checked against. By default it is asyncapi.yaml next to the schema,
+// if there is one.
+func (v *Validator) WithAsyncAPI(path string) *Validator {
+	v.asyncAPIPath = path
+	return v
+}
+
+// WithProtos sets the directory of .proto files that consumers' gRPC
+// contracts are checked against. By default it is the directory that
+// contains the schema, if it holds any .proto files.
+func (v *Validator) WithProtos(dir string) *Validator {
+	v.protoDir = dir
+	return v
+}
+
+// WithGRPC sets the address of the provider's gRPC server, which live
+// verification calls gRPC contracts' methods on.
+func (v *Validator) WithGRPC(address string) *Validator {
+	v.grpcAddress = address
+	return v
+}
+
+// WithGraphQL sets the SDL file that consumers' GraphQL contracts are
+// checked against. By default it is schema.graphql next to the schema, if
+// there is one.
+func (v *Validator) WithGraphQL(path string) *Validator {
+	v.graphQLPath = path
+	return v
+}
+
+// WithGraphQLURL sets the provider's GraphQL endpoint, which live
+// verification posts GraphQL contracts' operations to. By default it is
+// /graphql on the provider URL.
+func (v *Validator) WithGraphQLURL(endpoint string) *Validator {
+	v.graphQLURL = endpoint
+	return v
+}
+
 func (v *Validator) Validate() (*ValidationResult, error) {
 	// Parse the schema
 	parser := schema.NewParser(v.schemaPath)
@@ -48,11 +124,19 @@ func (v *Validator) Validate() (*ValidationResult, error) {
 	}
 	
 	// Get provider name from schema path
-	providerName := filepath.Base(filepath.Dir(v.schemaPath))
+	providerName := v.providerName
+	if providerName == "" {
+		providerName = filepath.Base(filepath.Dir(v.schemaPath))
+	}
 	
 	// Initialize the matcher
 	matcher := NewMatcher(schemaData)
 	
+	var live *LiveVerifier
+	if v.live {
+		live = NewLiveVerifier(v.providerURL, v.rateLimit).WithSpec(schema.NewSpec(schemaData))
+	}
+	
 	// Initialize the result
 	result := &ValidationResult{
 		ProviderName:    providerName,
@@ -62,54 +146,102 @@ func (v *Validator) Validate() (*ValidationResult, error) {
 		OverallSuccess:  true,
 	}
 	
-	// Walk through mocks directory
-	err = filepath.Walk(v.mocksDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
-		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
-			return nil
-		}
-		
-		// Read the mock to check if it's for this provider
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil // Skip this file
-		}
-		
-		var mock struct {
-			Provider string `json:"provider"`
-			Consumer string `json:"consumer"`
-		}
-		
-		if err := json.Unmarshal(data, &mock); err != nil {
-			return nil // Skip this file
-		}
-		
-		if mock.Provider != providerName {
-			return nil // Skip mocks for other providers
-		}
-		
-		// Match the mock against the schema
-		matchResult, err := matcher.MatchMock(path)
-		if err != nil {
-			// Log the error but continue with other mocks
-			fmt.Printf("Error matching mock %s: %v\n", path, err)
-			return nil
-		}
+	// Load this provider's contracts and link its mocks by their dependencies
+	contracts, err := LoadContracts(v.mocksDir, providerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process mocks: %w", err)
+	}
+	paths, mocks := contracts.MockPaths, contracts.Mocks
+	plan := planWorkflows(paths, mocks)
+	
+	// Verify mocks on a bounded pool of workers. Each worker writes only its
+	// own slot, so results keep the order of the mocks. Mocks that are part
+	// of a workflow are verified live afterwards, in dependency order.
+	matches := make([]*MatchResult, len(mocks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	
+	for w := 0; w < v.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mockLive := live
+				if plan.inWorkflow(i) {
+					mockLive = nil
+				}
+				matches[i] = v.verifyMock(paths[i], mocks[i], matcher, mockLive)
+				matches[i].Issues = append(matches[i].Issues, plan.issues[i]...)
+				if errorCount(plan.issues[i]) > 0 {
+					matches[i].IsCompatible = false
+				}
+			}
+		}()
+	}
+	
+	for i := range mocks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	
+	if live != nil {
+		result.Workflows = v.runWorkflows(plan, live, matches)
+	}
+	
+	// Warn about success codes the provider declares but no consumer handles
+	result.Warnings = unhandledStatuses(matcher.spec, mocks)
+	
+	// Check message contracts against the provider's AsyncAPI document
+	messageMatches, err := v.verifyMessages(contracts.MessagePaths, contracts.Messages)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, messageMatches...)
+	
+	// Check gRPC contracts against the provider's .proto files
+	grpcMatches, err := v.verifyGRPC(contracts.GRPCPaths, contracts.GRPC)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, grpcMatches...)
+	
+	// Check GraphQL contracts against the provider's SDL
+	graphQLMatches, err := v.verifyGraphQL(contracts.GraphQLPaths, contracts.GraphQL)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, graphQLMatches...)
+	
+	// Check webhook contracts against the webhooks and callbacks the
+	// provider declares
+	spec := schema.NewSpec(schemaData)
+	for i, contract := range contracts.Webhooks {
+		matchResult := MatchWebhook(spec, contract, contracts.WebhookPaths[i])
+		matches = append(matches, &matchResult)
+	}
+	
+	// Check stream contracts against the provider's streaming endpoints
+	streamMatches, err := v.verifyStreams(spec, contracts.StreamPaths, contracts.Streams)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, streamMatches...)
+	
+	for _, matchResult := range matches {
+		consumer := matchResult.Mock.Consumer
 		
 		// Update consumer results
-		consumerResult, exists := result.ConsumerResults[mock.Consumer]
+		consumerResult, exists := result.ConsumerResults[consumer]
 		if !exists {
 			consumerResult = ConsumerResult{
-				ConsumerName: mock.Consumer,
+				ConsumerName: consumer,
 				MatchResults: []MatchResult{},
 				Success:      true,
 			}
 		}
 		
-		consumerResult.MatchResults = append(consumerResult.MatchResults, matchResult)
+		consumerResult.MatchResults = append(consumerResult.MatchResults, *matchResult)
 		
 		// Update success flag
 		if !matchResult.IsCompatible {
@@ -117,14 +249,204 @@ func (v *Validator) Validate() (*ValidationResult, error) {
 			result.OverallSuccess = false
 		}
 		
-		result.ConsumerResults[mock.Consumer] = consumerResult
-		
-		return nil
-	})
-	
-	if err != nil {
-		return nil, fmt.Errorf("failed to process mocks: %w", err)
+		result.ConsumerResults[consumer] = consumerResult
 	}
 	
 	return result, nil
-}
\ No newline at end of file
+}
+
+// runWorkflows verifies the plan's workflows live, running up to the
+// validator's concurrency of them in parallel. Each workflow's steps run one
+// after another.
+func (v *Validator) runWorkflows(plan *workflowPlan, live *LiveVerifier, matches []*MatchResult) []WorkflowResult {
+	results := make([]WorkflowResult, len(plan.workflows))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	
+	for w := 0; w < v.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = plan.run(plan.workflows[i], live, matches)
+			}
+		}()
+	}
+	
+	for i := range plan.workflows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	
+	return results
+}
+
+// verifyMessages checks the provider's message contracts.
+func (v *Validator) verifyMessages(paths []string, messages []Message) ([]*MatchResult, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	
+	asyncAPIPath := v.asyncAPIPath
+	if asyncAPIPath == "" {
+		candidate := filepath.Join(filepath.Dir(v.schemaPath), "asyncapi.yaml")
+		if _, err := os.Stat(candidate); err == nil {
+			asyncAPIPath = candidate
+		}
+	}
+	
+	var spec *schema.AsyncSpec
+	if asyncAPIPath != "" {
+		var err error
+		spec, err = schema.LoadAsyncSpec(asyncAPIPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse AsyncAPI document: %w", err)
+		}
+	}
+	
+	matches := make([]*MatchResult, len(messages))
+	for i, message := range messages {
+		matchResult := MatchMessage(spec, message, paths[i])
+		matches[i] = &matchResult
+	}
+	return matches, nil
+}
+
+// verifyGRPC checks the provider's gRPC contracts against its .proto files
+// and, in live mode with a gRPC address, calls the provider.
+func (v *Validator) verifyGRPC(paths []string, mocks []GRPCMock) ([]*MatchResult, error) {
+	if len(mocks) == 0 {
+		return nil, nil
+	}
+	
+	
+	protoDir := v.protoDir
+	if protoDir == "" {
+		candidate := filepath.Dir(v.schemaPath)
+		if protos, _ := filepath.Glob(filepath.Join(candidate, "*.proto")); len(protos) > 0 {
+			protoDir = candidate
+		}
+	}
+	
+	var spec *schema.ProtoSpec
+	var err error
+	if protoDir != "" {
+		spec, err = schema.LoadProtoSpec(protoDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse .proto files: %w", err)
+		}
+	}
+	
+	var live *GRPCVerifier
+	if v.live && v.grpcAddress != "" && spec != nil {
+		live = NewGRPCVerifier(v.grpcAddress, v.rateLimit)
+	}
+	
+	matches := make([]*MatchResult, len(mocks))
+	for i, mock := range mocks {
+		matchResult := MatchGRPC(spec, mock, paths[i])
+		if live != nil && matchResult.IsCompatible {
+			if issues := live.Verify(spec, mock); len(issues) > 0 {
+				matchResult.Issues = append(matchResult.Issues, issues...)
+				if errorCount(issues) > 0 {
+					matchResult.IsCompatible = false
+				}
+			}
+		}
+		matches[i] = &matchResult
+	}
+	return matches, nil
+}
+
+// verifyGraphQL checks the provider's GraphQL contracts against its SDL and,
+// in live mode, posts each operation to the provider.
+func (v *Validator) verifyGraphQL(paths []string, mocks []GraphQLMock) ([]*MatchResult, error) {
+	if len(mocks) == 0 {
+		return nil, nil
+	}
+	
+	sdlPath := v.graphQLPath
+	if sdlPath == "" {
+		candidate := filepath.Join(filepath.Dir(v.schemaPath), "schema.graphql")
+		if _, err := os.Stat(candidate); err == nil {
+			sdlPath = candidate
+		}
+	}
+	
+	var sdl *schema.GraphQLSchema
+	var err error
+	if sdlPath != "" {
+		sdl, err = schema.LoadGraphQLSchema(sdlPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	var live *GraphQLVerifier
+	if v.live && sdl != nil {
+		endpoint := v.graphQLURL
+		if endpoint == "" {
+			endpoint = strings.TrimSuffix(v.providerURL, "/") + "/graphql"
+		}
+		live = NewGraphQLVerifier(endpoint, v.rateLimit)
+	}
+	
+	matches := make([]*MatchResult, len(mocks))
+	for i, mock := range mocks {
+		matchResult := MatchGraphQL(sdl, mock, paths[i])
+		if live != nil && matchResult.IsCompatible {
+			if issues := live.Verify(mock, paths[i]); len(issues) > 0 {
+				matchResult.Issues = append(matchResult.Issues, issues...)
+				if errorCount(issues) > 0 {
+					matchResult.IsCompatible = false
+				}
+			}
+		}
+		matches[i] = &matchResult
+	}
+	return matches, nil
+}
+
+// verifyStreams checks the provider's stream contracts against its schema
+// and, in live mode, opens each stream on the provider.
+func (v *Validator) verifyStreams(spec *schema.Spec, paths []string, mocks []StreamMock) ([]*MatchResult, error) {
+	if len(mocks) == 0 {
+		return nil, nil
+	}
+	
+	var live *StreamVerifier
+	if v.live {
+		live = NewStreamVerifier(v.providerURL, v.rateLimit)
+	}
+	
+	matches := make([]*MatchResult, len(mocks))
+	for i, mock := range mocks {
+		matchResult := MatchStream(spec, mock, paths[i])
+		if live != nil && matchResult.IsCompatible {
+			if issues := live.Verify(spec, mock); len(issues) > 0 {
+				matchResult.Issues = append(matchResult.Issues, issues...)
+				if errorCount(issues) > 0 {
+					matchResult.IsCompatible = false
+				}
+			}
+		}
+		matches[i] = &matchResult
+	}
+	return matches, nil
+}
+
+// verifyMock checks a parsed mock against the schema and, in live mode,
+// against the running provider.
+func (v *Validator) verifyMock(path string, mock Mock, matcher *Matcher, live *LiveVerifier) *MatchResult {
+	matchResult := matcher.Match(mock, path)
+	
+	if live != nil {
+		if issues := live.Verify(mock); len(issues) > 0 {
+			matchResult.Issues = append(matchResult.Issues, issues...)
+			matchResult.IsCompatible = false
+		}
+	}
+	
+	return &matchResult
+}
@@ -0,0 +1,248 @@
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Arpit529srivastava/internal/schema"
+)
+
+// Issue codes reported for webhook and callback contracts.
+const (
+	CodeWebhookNotFound         = "webhook-not-found"
+	CodeWebhookMethodMismatch   = "webhook-method-mismatch"
+	CodeWebhookPayloadInvalid   = "webhook-payload-invalid"
+	CodeWebhookFieldUndeclared  = "webhook-field-undeclared"
+	CodeWebhookFieldOptional    = "webhook-field-optional"
+	CodeWebhookHeaderUndeclared = "webhook-header-undeclared"
+	CodeWebhookStatusUndeclared = "webhook-status-undeclared"
+	CodeWebhookDeliveryFailed   = "webhook-delivery-failed"
+	CodeWebhookDeliveryRejected = "webhook-delivery-rejected"
+)
+
+// WebhookContract is a consumer's expectation of a request the provider
+// sends it: an OpenAPI webhook or callback, named by Webhook. The request
+// is what the consumer accepts on its own endpoint, and the response is
+// what it answers with.
+type WebhookContract struct {
+	Provider      string       `json:"provider"`
+	Consumer      string       `json:"consumer"`
+	Description   string       `json:"description"`
+	ProviderState string       `json:"providerState,omitempty"`
+	Webhook       string       `json:"webhook"`
+	Request       MockRequest  `json:"request"`
+	Response      MockResponse `json:"response"`
+}
+
+// method returns the method the webhook is delivered with, POST by default.
+func (c WebhookContract) method() string {
+	if c.Request.Method == "" {
+		return "post"
+	}
+	return strings.ToLower(c.Request.Method)
+}
+
+// MatchWebhook checks a consumer's webhook contract against what the
+// provider's OpenAPI document says it sends. The direction is reversed from
+// a Mock: the payload the consumer accepts must be one the provider may
+// send, the fields and headers it relies on must be ones the provider
+// promises, and its answer must be a response the provider declares.
+func MatchWebhook(spec *schema.Spec, contract WebhookContract, contractPath string) MatchResult {
+	result := MatchResult{
+		Mock: Mock{
+			Provider:      contract.Provider,
+			Consumer:      contract.Consumer,
+			Description:   contract.Description,
+			ProviderState: contract.ProviderState,
+		},
+		Webhook:      &contract,
+		MockPath:     contractPath,
+		IsCompatible: true,
+		Issues:       []Issue{},
+	}
+	path := "webhook " + contract.Webhook
+	add := func(code, issuePath, severity, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, Issue{
+			Code:        code,
+			Path:        issuePath,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    severity,
+		})
+	}
+
+	webhook, named := spec.FindWebhook(contract.Webhook, contract.method())
+	if webhook == nil {
+		if named {
+			add(CodeWebhookMethodMismatch, path, "error", "Provider does not send %s with %s", contract.Webhook, strings.ToUpper(contract.method()))
+		} else {
+			add(CodeWebhookNotFound, path, "error", "Webhook or callback not found in provider schema")
+		}
+		result.IsCompatible = false
+		return result
+	}
+	op := &webhook.Operation
+
+	declared := map[string]schema.Parameter{}
+	for _, param := range op.Parameters() {
+		if param.In == "header" {
+			declared[strings.ToLower(param.Name)] = param
+		}
+	}
+	for _, name := range sortedKeys(contract.Request.Headers) {
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		param, ok := declared[strings.ToLower(name)]
+		switch {
+		case !ok:
+			add(CodeWebhookHeaderUndeclared, fmt.Sprintf("%s request.headers.%s", path, name), "error", "Consumer relies on a header the provider does not declare")
+		case !param.Required:
+			add(CodeWebhookFieldOptional, fmt.Sprintf("%s request.headers.%s", path, name), "warning", "Consumer relies on an optional header the provider may leave out")
+		}
+	}
+
+	header := http.Header{}
+	for name, value := range contract.Request.Headers {
+		header.Set(name, value)
+	}
+	var body []byte
+	if contract.Request.Body != nil {
+		content, _, _ := op.RequestBody()
+		data, contentType, err := encodeMockBody(spec, content, contract.Request.Headers, contract.Request.Body)
+		if err != nil {
+			add(CodeWebhookPayloadInvalid, fmt.Sprintf("%s request.body", path), "error", "Consumer's sample payload cannot be encoded: %v", err)
+		}
+		body = data
+		header.Set("Content-Type", contentType)
+	}
+	request := schema.RequestData{Header: header, Body: body}
+	for _, violation := range op.ValidateRequest(request) {
+		if location := strings.SplitN(violation.Path, ".", 2)[0]; location == "path" || location == "query" || location == "cookie" {
+			continue // The URL is the consumer's, not the provider's
+		}
+		if violation.Rule == "required" && strings.HasPrefix(violation.Path, "header.") {
+			continue // Headers the consumer doesn't list are not relied on
+		}
+		add(CodeWebhookPayloadInvalid, fmt.Sprintf("%s request.%s", path, violation.Path), "error",
+			"Provider never sends this (%s): %s", violation.Rule, violation.Message)
+	}
+
+	if content, _, ok := op.RequestBody(); ok && contract.Request.Body != nil {
+		if _, media, ok := schema.SelectMediaType(content, header.Get("Content-Type")); ok {
+			mediaSchema, _ := media.(map[interface{}]interface{})
+			var accepted interface{} = contract.Request.Body
+			for _, violation := range spec.CheckReliance(mediaSchema["schema"], accepted, "body") {
+				if violation.Rule == "optional" {
+					add(CodeWebhookFieldOptional, fmt.Sprintf("%s request.%s", path, violation.Path), "warning",
+						"Consumer relies on a field the provider may leave out")
+					continue
+				}
+				add(CodeWebhookFieldUndeclared, fmt.Sprintf("%s request.%s", path, violation.Path), "error",
+					"Consumer relies on a field the provider does not declare")
+			}
+		}
+	}
+
+	if status := contract.Response.StatusCode; status != 0 {
+		if _, ok := op.Response(status); !ok {
+			add(CodeWebhookStatusUndeclared, fmt.Sprintf("%s response.statusCode", path), "error",
+				"Consumer answers %d, which the provider does not declare for this webhook", status)
+		}
+	}
+
+	if errorCount(result.Issues) > 0 {
+		result.IsCompatible = false
+	}
+	return result
+}
+
+// WebhookSender delivers sample webhook requests, generated from a
+// provider's schema, to a consumer's endpoint.
+type WebhookSender struct {
+	baseURL string
+	client  *http.Client
+	limiter *rateLimiter
+}
+
+// NewWebhookSender creates a sender for the consumer at baseURL. Deliveries
+// are limited to requestsPerSecond; zero or less means unlimited.
+func NewWebhookSender(baseURL string, requestsPerSecond float64) *WebhookSender {
+	return &WebhookSender{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		limiter: newRateLimiter(requestsPerSecond),
+	}
+}
+
+// Deliver sends a sample delivery of the webhook to endpoint and returns the
+// consumer's status code with every way its answer breaks the contract: the
+// status must be one the provider declares for the webhook and, if
+// expectedStatus is set, the one the consumer's contract promises.
+func (w *WebhookSender) Deliver(webhook schema.Webhook, endpoint string, expectedStatus int) (int, []Issue) {
+	path := "webhook " + webhook.Name
+	failed := func(err error) (int, []Issue) {
+		return 0, []Issue{{
+			Code:        CodeWebhookDeliveryFailed,
+			Path:        path,
+			Description: fmt.Sprintf("Delivery to consumer failed: %v", err),
+			Severity:    "error",
+		}}
+	}
+
+	contentType, body, headers := webhook.SampleDelivery()
+	var payload io.Reader
+	if contentType != "" {
+		content, _, _ := webhook.Operation.RequestBody()
+		_, media, _ := schema.SelectMediaType(content, contentType)
+		data, sentAs, err := webhook.Operation.Spec().EncodeBody(contentType, media, body)
+		if err != nil {
+			return failed(err)
+		}
+		payload = bytes.NewReader(data)
+		contentType = sentAs
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(webhook.Operation.Method), w.baseURL+endpoint, payload)
+	if err != nil {
+		return failed(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	w.limiter.Wait()
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return failed(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	var issues []Issue
+	if _, ok := webhook.Operation.Response(resp.StatusCode); !ok {
+		issues = append(issues, Issue{
+			Code:        CodeWebhookDeliveryRejected,
+			Path:        path + " response.statusCode",
+			Description: fmt.Sprintf("Consumer answered %d, which the provider does not declare for this webhook", resp.StatusCode),
+			Severity:    "error",
+		})
+	}
+	if expectedStatus != 0 && resp.StatusCode != expectedStatus {
+		issues = append(issues, Issue{
+			Code:        CodeWebhookDeliveryRejected,
+			Path:        path + " response.statusCode",
+			Description: fmt.Sprintf("Consumer answered %d, its contract promises %d", resp.StatusCode, expectedStatus),
+			Severity:    "error",
+		})
+	}
+	return resp.StatusCode, issues
+}
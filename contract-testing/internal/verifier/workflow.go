@@ -0,0 +1,524 @@
+package verifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Issue codes reported for mock dependencies and workflows.
+const (
+	CodeDependencyNotFound = "dependency-not-found"
+	CodeDependencyCycle    = "dependency-cycle"
+	CodeDependencySkipped  = "dependency-skipped"
+	CodeCaptureUndefined   = "capture-undefined"
+	CodeCaptureFailed      = "capture-failed"
+)
+
+// Workflow step statuses.
+const (
+	StepPassed  = "passed"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// WorkflowResult is the outcome of a chain of mocks linked by their
+// dependencies, verified live in dependency order.
+type WorkflowResult struct {
+	Name     string         `json:"name"`
+	Consumer string         `json:"consumer"`
+	Steps    []WorkflowStep `json:"steps"`
+	Success  bool           `json:"success"`
+}
+
+// WorkflowStep is one mock of a workflow.
+type WorkflowStep struct {
+	Description string `json:"description"`
+	MockPath    string `json:"mockPath"`
+	Status      string `json:"status"` // "passed", "failed", "skipped"
+}
+
+// PlaceholderPattern matches {{name}} references to captured values.
+var PlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// workflowPlan links the mocks of one verification run by their
+// dependencies. A mock's dependencies name other mocks of the same consumer
+// by description or by file name without .json.
+type workflowPlan struct {
+	paths     []string
+	mocks     []Mock
+	deps      [][]int   // resolved dependencies of each mock
+	issues    [][]Issue // dependency problems of each mock
+	broken    []bool    // mocks that can't run: cyclic or with unknown dependencies
+	workflows [][]int   // mocks of each workflow in execution order
+}
+
+// planWorkflows resolves dependencies, detects cycles and groups the mocks
+// that depend on each other into workflows. Mocks with no dependencies and
+// no dependents belong to no workflow.
+func planWorkflows(paths []string, mocks []Mock) *workflowPlan {
+	plan := &workflowPlan{
+		paths:  paths,
+		mocks:  mocks,
+		deps:   make([][]int, len(mocks)),
+		issues: make([][]Issue, len(mocks)),
+		broken: make([]bool, len(mocks)),
+	}
+
+	byName := make(map[string]int)
+	for i, mock := range mocks {
+		for _, name := range []string{mock.Description, strings.TrimSuffix(filepath.Base(paths[i]), ".json")} {
+			key := mock.Consumer + "\x00" + name
+			if _, taken := byName[key]; !taken && name != "" {
+				byName[key] = i
+			}
+		}
+	}
+
+	for i, mock := range mocks {
+		for _, name := range mock.Dependencies {
+			dep, ok := byName[mock.Consumer+"\x00"+name]
+			if !ok {
+				plan.addIssue(i, CodeDependencyNotFound, "dependencies", fmt.Sprintf("No mock of %s is named %q", mock.Consumer, name))
+				plan.broken[i] = true
+				continue
+			}
+			plan.deps[i] = append(plan.deps[i], dep)
+		}
+	}
+
+	plan.findCycles()
+	plan.checkPlaceholders()
+	plan.groupWorkflows()
+	return plan
+}
+
+func (p *workflowPlan) addIssue(i int, code, path, description string) {
+	mock := p.mocks[i]
+	p.issues[i] = append(p.issues[i], Issue{
+		Code:        code,
+		Path:        fmt.Sprintf("%s %s %s", strings.ToLower(mock.Request.Method), mock.Request.Endpoint, path),
+		Description: description,
+		Severity:    "error",
+	})
+}
+
+// findCycles marks every mock on a dependency cycle.
+func (p *workflowPlan) findCycles() {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(p.mocks))
+	var stack []int
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, dep := range p.deps[i] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := stack[start:]
+				names := make([]string, 0, len(cycle)+1)
+				for _, member := range cycle {
+					names = append(names, fmt.Sprintf("%q", p.mocks[member].Description))
+				}
+				names = append(names, names[0])
+				for _, member := range cycle {
+					if !hasIssue(p.issues[member], CodeDependencyCycle) {
+						p.addIssue(member, CodeDependencyCycle, "dependencies", "Dependency cycle: "+strings.Join(names, " → "))
+					}
+					p.broken[member] = true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = done
+	}
+
+	for i := range p.mocks {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+}
+
+// checkPlaceholders reports {{name}} references that none of a mock's
+// direct or indirect dependencies captures.
+func (p *workflowPlan) checkPlaceholders() {
+	for i, mock := range p.mocks {
+		used := placeholders(mock)
+		if len(used) == 0 {
+			continue
+		}
+
+		captured := make(map[string]bool)
+		seen := make(map[int]bool)
+		var collect func(j int)
+		collect = func(j int) {
+			for _, dep := range p.deps[j] {
+				if seen[dep] {
+					continue
+				}
+				seen[dep] = true
+				for name := range p.mocks[dep].Captures {
+					captured[name] = true
+				}
+				collect(dep)
+			}
+		}
+		collect(i)
+
+		for _, name := range used {
+			if !captured[name] {
+				p.addIssue(i, CodeCaptureUndefined, "{{"+name+"}}", fmt.Sprintf("No dependency of this mock captures %q", name))
+				p.broken[i] = true
+			}
+		}
+	}
+}
+
+// groupWorkflows splits the mocks that have dependencies or dependents into
+// connected groups and orders each group so that dependencies come first,
+// keeping file order otherwise.
+func (p *workflowPlan) groupWorkflows() {
+	neighbours := make([][]int, len(p.mocks))
+	for i, deps := range p.deps {
+		for _, dep := range deps {
+			neighbours[i] = append(neighbours[i], dep)
+			neighbours[dep] = append(neighbours[dep], i)
+		}
+	}
+
+	grouped := make([]bool, len(p.mocks))
+	for i := range p.mocks {
+		if grouped[i] || (len(neighbours[i]) == 0 && len(p.mocks[i].Dependencies) == 0) {
+			continue
+		}
+
+		var members []int
+		queue := []int{i}
+		grouped[i] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			members = append(members, current)
+			for _, next := range neighbours[current] {
+				if !grouped[next] {
+					grouped[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		sort.Ints(members)
+		p.workflows = append(p.workflows, p.order(members))
+	}
+}
+
+// order sorts a workflow's members topologically. Members on a cycle are
+// appended in file order; they are never run.
+func (p *workflowPlan) order(members []int) []int {
+	placed := make(map[int]bool, len(members))
+	var ordered []int
+
+	for len(ordered) < len(members) {
+		progress := false
+		for _, i := range members {
+			if placed[i] {
+				continue
+			}
+			ready := true
+			for _, dep := range p.deps[i] {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[i] = true
+				ordered = append(ordered, i)
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			for _, i := range members {
+				if !placed[i] {
+					placed[i] = true
+					ordered = append(ordered, i)
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// inWorkflow reports whether a mock is verified as part of a workflow.
+func (p *workflowPlan) inWorkflow(i int) bool {
+	for _, workflow := range p.workflows {
+		for _, member := range workflow {
+			if member == i {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// run verifies one workflow live, step by step, passing values captured from
+// earlier responses to later requests. Steps whose dependencies didn't pass
+// are skipped. Live issues are appended to matches.
+func (p *workflowPlan) run(steps []int, live *LiveVerifier, matches []*MatchResult) WorkflowResult {
+	first := p.mocks[steps[0]]
+	result := WorkflowResult{Consumer: first.Consumer, Success: true}
+
+	status := make(map[int]string, len(steps))
+	captures := make(map[string]interface{})
+	var names []string
+
+	for _, i := range steps {
+		mock := p.mocks[i]
+		names = append(names, mock.Description)
+		issues := p.runStep(i, status, captures, live)
+		matches[i].Issues = append(matches[i].Issues, issues...)
+
+		switch {
+		case status[i] == StepSkipped:
+		case errorCount(issues) > 0 || p.broken[i]:
+			status[i] = StepFailed
+		default:
+			status[i] = StepPassed
+		}
+		if status[i] != StepPassed {
+			result.Success = false
+		}
+		if errorCount(issues) > 0 {
+			matches[i].IsCompatible = false
+		}
+
+		result.Steps = append(result.Steps, WorkflowStep{
+			Description: mock.Description,
+			MockPath:    p.paths[i],
+			Status:      status[i],
+		})
+	}
+
+	result.Name = strings.Join(names, " → ")
+	return result
+}
+
+func (p *workflowPlan) runStep(i int, status map[int]string, captures map[string]interface{}, live *LiveVerifier) []Issue {
+	mock := p.mocks[i]
+	method := strings.ToLower(mock.Request.Method)
+
+	if p.broken[i] {
+		return nil // already reported while planning
+	}
+	for _, dep := range p.deps[i] {
+		if status[dep] != StepPassed {
+			status[i] = StepSkipped
+			return []Issue{{
+				Code:        CodeDependencySkipped,
+				Path:        fmt.Sprintf("%s %s dependencies", method, mock.Request.Endpoint),
+				Description: fmt.Sprintf("Not verified live because prerequisite %q did not pass", p.mocks[dep].Description),
+				Severity:    "warning",
+			}}
+		}
+	}
+
+	issues, body := live.verify(SubstituteCaptures(mock, captures))
+	if errorCount(issues) > 0 {
+		return issues
+	}
+
+	for _, name := range sortedKeys(mock.Captures) {
+		value, err := EvaluateJSONPath(body, mock.Captures[name])
+		if err != nil {
+			issues = append(issues, Issue{
+				Code:        CodeCaptureFailed,
+				Path:        fmt.Sprintf("%s %s captures.%s", method, mock.Request.Endpoint, name),
+				Description: fmt.Sprintf("Failed to capture %s from the provider response: %v", mock.Captures[name], err),
+				Severity:    "error",
+			})
+			continue
+		}
+		captures[name] = value
+	}
+	return issues
+}
+
+// placeholders lists the capture names a mock refers to, in sorted order.
+func placeholders(mock Mock) []string {
+	data, _ := json.Marshal(struct {
+		Request  MockRequest  `json:"request"`
+		Response MockResponse `json:"response"`
+	}{mock.Request, mock.Response})
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range PlaceholderPattern.FindAllStringSubmatch(string(data), -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SubstituteCaptures returns a copy of a mock with {{name}} placeholders in
+// its request and expected response replaced by captured values. In bodies,
+// a string that is exactly one placeholder takes the captured value with its
+// JSON type.
+func SubstituteCaptures(mock Mock, captures map[string]interface{}) Mock {
+	replace := func(value string) string {
+		return PlaceholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+			if captured, ok := captures[PlaceholderPattern.FindStringSubmatch(placeholder)[1]]; ok {
+				return fmt.Sprint(captured)
+			}
+			return placeholder
+		})
+	}
+	replaceAll := func(values map[string]string) map[string]string {
+		if values == nil {
+			return nil
+		}
+		replaced := make(map[string]string, len(values))
+		for name, value := range values {
+			replaced[name] = replace(value)
+		}
+		return replaced
+	}
+	replaceBody := func(body interface{}) interface{} {
+		if body == nil {
+			return nil
+		}
+		return substituteValue(body, captures, replace)
+	}
+
+	mock.Request.Endpoint = replace(mock.Request.Endpoint)
+	mock.Request.Parameters = replaceAll(mock.Request.Parameters)
+	mock.Request.Headers = replaceAll(mock.Request.Headers)
+	mock.Request.Body = replaceBody(mock.Request.Body)
+	mock.Response.Headers = replaceAll(mock.Response.Headers)
+	mock.Response.Body = replaceBody(mock.Response.Body)
+	return mock
+}
+
+// substituteValue copies a decoded JSON value, replacing placeholders in its
+// strings.
+func substituteValue(value interface{}, captures map[string]interface{}, replace func(string) string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = substituteValue(item, captures, replace)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = substituteValue(item, captures, replace)
+		}
+		return copied
+	case string:
+		if match := PlaceholderPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			if captured, ok := captures[match[1]]; ok {
+				return captured
+			}
+		}
+		return replace(v)
+	default:
+		return v
+	}
+}
+
+// EvaluateJSONPath returns the value at a JSONPath expression in a decoded
+// JSON document. Only the child and index steps are supported: $.order.id,
+// $.items[0].productId and $['order-id'].
+func EvaluateJSONPath(document interface{}, expression string) (interface{}, error) {
+	rest := strings.TrimSpace(expression)
+	if !strings.HasPrefix(rest, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expression)
+	}
+	rest = rest[1:]
+	current := document
+
+	for rest != "" {
+		var key string
+		index := -1
+
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty step", expression)
+			}
+
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end == -1 {
+				return nil, fmt.Errorf("JSONPath %q has an unterminated ['", expression)
+			}
+			key, rest = rest[2:end], rest[end+2:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("JSONPath %q has an unterminated [", expression)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("JSONPath %q: %q is not an array index", expression, rest[1:end])
+			}
+			index, rest = n, rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected %q", expression, rest)
+		}
+
+		if index >= 0 {
+			items, ok := current.([]interface{})
+			if !ok || index >= len(items) {
+				return nil, fmt.Errorf("no item %d", index)
+			}
+			current = items[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no field %q", key)
+		}
+		if current, ok = object[key]; !ok {
+			return nil, fmt.Errorf("no field %q", key)
+		}
+	}
+	return current, nil
+}
+
+func hasIssue(issues []Issue, code string) bool {
+	for _, issue := range issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,14 @@ Copyright © 2025 Arpit Srivastava Arpitsrivasatava@gmail.com
 */
 package main
 
-import "github.com/Arpit529srivastava/cmd"
+import (
+	"os"
+
+	"github.com/Arpit529srivastava/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }